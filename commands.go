@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"library/config"
+	"library/database"
+	"library/models"
+	"library/repositories"
+	"library/services"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runCommand menjalankan subcommand CLI dan mengembalikan exit code prosesnya
+func runCommand(cfg *config.Config, args []string) int {
+	switch args[0] {
+	case "import-books":
+		return importBooksCommand(cfg, args[1:])
+	case "migrate":
+		return migrateCommand(cfg, args[1:])
+	case "expire-holds":
+		return expireHoldsCommand(cfg)
+	case "config":
+		return configCommand(cfg, args[1:])
+	case "create-admin":
+		return createAdminCommand(cfg, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\nAvailable commands:\n"+
+			"  import-books  import the book catalogue from a CSV, XLSX, MARC21 or MARCXML file\n"+
+			"  migrate       apply, roll back or list database schema migrations (up, down, status)\n"+
+			"  expire-holds  expire ready holds past their pickup date and pass the copies on\n"+
+			"  config        print the effective configuration with secrets redacted, or check it (print, check)\n"+
+			"  create-admin  create an admin account, reading its password from ADMIN_PASSWORD or stdin\n", args[0])
+		return 2
+	}
+}
+
+// migrateCommand menjalankan migration skema database: up, down atau status
+func migrateCommand(cfg *config.Config, args []string) int {
+	usage := "usage: migrate up [-steps N] | migrate down [-steps N] | migrate status"
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	defaultSteps := 0
+	stepsHelp := "number of pending migrations to apply, 0 applies all"
+	if args[0] == "down" {
+		defaultSteps = 1
+		stepsHelp = "number of applied migrations to roll back, newest first"
+	}
+	steps := flags.Int("steps", defaultSteps, stepsHelp)
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	switch args[0] {
+	case "up":
+		database.Connect(cfg)
+		applied, err := database.MigrateUp(database.DBClient, cfg, *steps)
+		for _, migration := range applied {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return 0
+	case "down":
+		database.Connect(cfg)
+		reverted, err := database.MigrateDown(database.DBClient, *steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no migrations to roll back")
+		}
+		return 0
+	case "status":
+		database.Connect(cfg)
+		statuses, err := database.MigrationStatuses(database.DBClient)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Missing {
+				appliedAt += " (file missing from this build)"
+			}
+			fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		writer.Flush()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n%s\n", args[0], usage)
+		return 2
+	}
+}
+
+// configCommand menampilkan konfigurasi efektif sebagai YAML dengan secret disamarkan (print)
+// atau memeriksa konfigurasi tanpa menjalankan server (check)
+func configCommand(cfg *config.Config, args []string) int {
+	usage := "usage: config print | config check"
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "print":
+		if cfg.File != "" {
+			fmt.Printf("# loaded from %s and the environment\n", cfg.File)
+		}
+		output, err := yaml.Marshal(cfg.Redacted())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config print: %v\n", err)
+			return 1
+		}
+		os.Stdout.Write(output)
+		return 0
+	case "check":
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+			return 1
+		}
+		for _, warning := range cfg.Warnings() {
+			fmt.Printf("warning: %s\n", warning)
+		}
+		fmt.Printf("configuration is valid for %s\n", cfg.Env)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config command %q\n%s\n", args[0], usage)
+		return 2
+	}
+}
+
+// createAdminCommand membuat akun admin baru. Password dibaca dari ADMIN_PASSWORD atau stdin
+// supaya tidak terlihat di daftar proses maupun riwayat shell.
+func createAdminCommand(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the new admin account (required)")
+	name := flags.String("name", "Administrator", "name of the new admin account")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *email == "" {
+		fmt.Fprintln(os.Stderr, "create-admin: -email is required")
+		flags.Usage()
+		return 2
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "create-admin: could not read password: %v\n", err)
+			return 1
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	database.InitDatabase(cfg)
+	accounts := services.NewAccountService(repositories.NewGormUserRepository(database.DBClient), cfg)
+	user := &models.User{Name: *name, Email: *email, Password: password}
+	if err := accounts.CreateAdmin(context.Background(), user); err != nil {
+		fmt.Fprintf(os.Stderr, "create-admin: %v\n", err)
+		return 1
+	}
+	fmt.Printf("created admin %s (%s)\n", user.Email, user.ID)
+	return 0
+}
+
+// expireHoldsCommand menjalankan pemeriksaan hold ready yang kedaluwarsa sekali, sama seperti
+// background job expire-holds, misalnya dari cron ketika server dijalankan tanpa job
+func expireHoldsCommand(cfg *config.Config) int {
+	database.InitDatabase(cfg)
+	if err := services.NewCirculationService(database.DBClient, cfg).ExpireReadyHolds(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "expire-holds: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// importBooksCommand mengimpor katalog buku dari file CSV/XLSX dan mencetak laporannya sebagai JSON
+func importBooksCommand(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("import-books", flag.ContinueOnError)
+	path := flags.String("file", "", "path to the CSV, XLSX, MARC21 (.mrc) or MARCXML (.xml) file (required)")
+	dryRun := flags.Bool("dry-run", false, "validate the file without saving anything")
+	chunkSize := flags.Int("chunk-size", services.DefaultImportChunkSize, "number of rows saved per transaction")
+	startRow := flags.Int("start-row", 0, "resume an interrupted import from this row number")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "import-books: -file is required")
+		flags.Usage()
+		return 2
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-books: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	rows, err := services.ParseBookImportFile(file, *path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-books: %v\n", err)
+		return 1
+	}
+
+	database.InitDatabase(cfg)
+	importer := services.NewImportService(database.DBClient, cfg)
+	report := importer.ImportBooks(context.Background(), rows, services.BookImportOptions{
+		DryRun:    *dryRun,
+		ChunkSize: *chunkSize,
+		StartRow:  *startRow,
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if report.Invalid > 0 || report.Error != "" {
+		return 1
+	}
+	return 0
+}
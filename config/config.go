@@ -1,45 +1,308 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-// Config struct untuk menyimpan konfigurasi aplikasi
+// Environment aplikasi yang didukung APP_ENV
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config struct untuk menyimpan konfigurasi aplikasi. Tag yaml adalah nama kunci di file
+// konfigurasi, yaitu nama variabel lingkungannya dalam huruf kecil.
 type Config struct {
-	Port      string
-	DBHost    string
-	DBPort    string
-	DBUser    string
-	DBPass    string
-	DBName    string
-	JWTSecret string
+	// Env adalah environment aplikasi: development, staging atau production.
+	// Di luar development secret default dan kunci yang terlalu pendek ditolak saat startup.
+	Env string `yaml:"app_env"`
+	// File adalah path file konfigurasi yang dimuat (CONFIG_FILE), kosong jika tidak ada
+	File string `yaml:"-"`
+
+	Port      string `yaml:"port"`
+	DBHost    string `yaml:"db_host"`
+	DBPort    string `yaml:"db_port"`
+	DBUser    string `yaml:"db_user"`
+	DBPass    string `yaml:"db_pass"`
+	DBName    string `yaml:"db_name"`
+	JWTSecret string `yaml:"jwt_secret"`
+	// DBAutoMigrate menjalankan migration yang belum diterapkan saat server start
+	DBAutoMigrate bool `yaml:"db_auto_migrate"`
+	// DBMaxOpenConns adalah batas koneksi database yang terbuka, 0 berarti tanpa batas
+	DBMaxOpenConns int `yaml:"db_max_open_conns"`
+	// DBMaxIdleConns adalah jumlah koneksi database menganggur yang disimpan di pool
+	DBMaxIdleConns int `yaml:"db_max_idle_conns"`
+	// DBConnMaxLifetime adalah umur maksimal satu koneksi sebelum diganti, 0 berarti tanpa batas
+	DBConnMaxLifetime time.Duration `yaml:"db_conn_max_lifetime"`
+	// DBConnMaxIdleTime adalah lama koneksi boleh menganggur sebelum ditutup, 0 berarti tanpa batas
+	DBConnMaxIdleTime time.Duration `yaml:"db_conn_max_idle_time"`
+	// ShutdownTimeout adalah batas waktu menyelesaikan request yang sedang berjalan saat server dihentikan
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// AccessTokenTTL adalah masa berlaku Access Token
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl"`
+	// RefreshTokenTTL adalah masa berlaku Refresh Token, harus lebih lama dari Access Token
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+
+	// DefaultTierCode adalah tier keanggotaan untuk anggota baru dan anggota tanpa tier
+	DefaultTierCode string `yaml:"default_membership_tier"`
+	// LoanPeriodDays adalah lama peminjaman default dalam hari, dipakai jika tier default tidak ada
+	LoanPeriodDays int `yaml:"loan_period_days"`
+	// CategoryLoanPeriods berisi lama peminjaman khusus per kategori (kunci huruf kecil)
+	CategoryLoanPeriods map[string]int `yaml:"category_loan_periods"`
+	// MaxRenewals adalah batas berapa kali satu peminjaman boleh diperpanjang
+	MaxRenewals int `yaml:"max_renewals"`
+	// RenewalGraceDays adalah toleransi keterlambatan (hari) yang masih boleh diperpanjang
+	RenewalGraceDays int `yaml:"renewal_grace_days"`
+
+	// FineDailyRate adalah denda keterlambatan per hari (Rupiah)
+	FineDailyRate int64 `yaml:"fine_daily_rate"`
+	// CategoryFineRates berisi denda per hari khusus per kategori (kunci huruf kecil)
+	CategoryFineRates map[string]int `yaml:"category_fine_rates"`
+	// FineGraceDays adalah jumlah hari keterlambatan yang tidak didenda
+	FineGraceDays int `yaml:"fine_grace_days"`
+	// FineMaxAmount adalah batas maksimal denda keterlambatan per peminjaman, 0 berarti tanpa batas
+	FineMaxAmount int64 `yaml:"fine_max_amount"`
+	// MaxOutstandingFine adalah saldo denda maksimal yang masih boleh meminjam buku
+	MaxOutstandingFine int64 `yaml:"max_outstanding_fine"`
+
+	// HoldPickupDays adalah batas waktu (hari) mengambil buku setelah hold berstatus ready
+	HoldPickupDays int `yaml:"hold_pickup_days"`
+	// HoldSweepMinutes adalah interval pengecekan hold yang kedaluwarsa
+	HoldSweepMinutes int `yaml:"hold_sweep_minutes"`
+
+	// StorageDriver memilih penyimpanan file (cover buku): "local" atau "s3"
+	StorageDriver string `yaml:"storage_driver"`
+	// StorageLocalDir adalah direktori penyimpanan untuk driver local
+	StorageLocalDir string `yaml:"storage_local_dir"`
+	// S3Endpoint adalah host S3 atau layanan kompatibel S3 (misalnya MinIO), tanpa skema
+	S3Endpoint  string `yaml:"s3_endpoint"`
+	S3Region    string `yaml:"s3_region"`
+	S3Bucket    string `yaml:"s3_bucket"`
+	S3AccessKey string `yaml:"s3_access_key"`
+	S3SecretKey string `yaml:"s3_secret_key"`
+	// S3UseSSL mengaktifkan HTTPS ke endpoint S3
+	S3UseSSL bool `yaml:"s3_use_ssl"`
 }
 
-// LoadConfig memuat konfigurasi dari variabel lingkungan atau file .env
-func LoadConfig() *Config {
+// Load memuat konfigurasi sekali saat startup. Urutan prioritas dari yang terendah: nilai default,
+// file konfigurasi (CONFIG_FILE, YAML atau TOML), lalu variabel lingkungan atau file .env.
+// Nilai yang tidak bisa dibaca dikembalikan sebagai error; aturan nilai diperiksa oleh Validate.
+func Load() (*Config, error) {
 	err := godotenv.Load() // Memuat variabel dari .env
 	if err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	return &Config{
-		Port:      getEnv("PORT", "3000"),
-		DBHost:    getEnv("DB_HOST", "localhost"),
-		DBPort:    getEnv("DB_PORT", "5432"),
-		DBUser:    getEnv("DB_USER", "postgres"),
-		DBPass:    getEnv("DB_PASS", "password"),
-		DBName:    getEnv("DB_NAME", "mydb"),
-		JWTSecret: getEnv("JWT_SECRET", "supersecretjwtkey"),
+	src := &source{}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if src.file, err = readConfigFile(path); err != nil {
+			return nil, err
+		}
 	}
+
+	cfg := &Config{
+		Env:       strings.ToLower(src.string("APP_ENV", EnvDevelopment)),
+		File:      os.Getenv("CONFIG_FILE"),
+		Port:      src.string("PORT", "3000"),
+		DBHost:    src.string("DB_HOST", "localhost"),
+		DBPort:    src.string("DB_PORT", "5432"),
+		DBUser:    src.string("DB_USER", "postgres"),
+		DBPass:    src.string("DB_PASS", defaultDBPass),
+		DBName:    src.string("DB_NAME", "mydb"),
+		JWTSecret: src.string("JWT_SECRET", defaultJWTSecret),
+
+		DBAutoMigrate:  src.bool("DB_AUTO_MIGRATE", true),
+		DBMaxOpenConns: src.int("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns: src.int("DB_MAX_IDLE_CONNS", 5),
+
+		DBConnMaxLifetime: src.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime: src.duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+
+		ShutdownTimeout: src.duration("SHUTDOWN_TIMEOUT", 15*time.Second),
+
+		AccessTokenTTL:  src.duration("ACCESS_TOKEN_TTL", 30*time.Minute),
+		RefreshTokenTTL: src.duration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+
+		DefaultTierCode:     src.string("DEFAULT_MEMBERSHIP_TIER", "public"),
+		LoanPeriodDays:      src.int("LOAN_PERIOD_DAYS", 14),
+		CategoryLoanPeriods: src.intMap("CATEGORY_LOAN_PERIODS"),
+		MaxRenewals:         src.int("MAX_RENEWALS", 2),
+		RenewalGraceDays:    src.int("RENEWAL_GRACE_DAYS", 3),
+
+		FineDailyRate:      src.int64("FINE_DAILY_RATE", 1000),
+		CategoryFineRates:  src.intMap("CATEGORY_FINE_RATES"),
+		FineGraceDays:      src.int("FINE_GRACE_DAYS", 0),
+		FineMaxAmount:      src.int64("FINE_MAX_AMOUNT", 50000),
+		MaxOutstandingFine: src.int64("MAX_OUTSTANDING_FINE", 20000),
+
+		HoldPickupDays:   src.int("HOLD_PICKUP_DAYS", 3),
+		HoldSweepMinutes: src.int("HOLD_SWEEP_MINUTES", 5),
+
+		StorageDriver:   src.string("STORAGE_DRIVER", "local"),
+		StorageLocalDir: src.string("STORAGE_LOCAL_DIR", "uploads"),
+		S3Endpoint:      src.string("S3_ENDPOINT", "s3.amazonaws.com"),
+		S3Region:        src.string("S3_REGION", "us-east-1"),
+		S3Bucket:        src.string("S3_BUCKET", ""),
+		S3AccessKey:     src.string("S3_ACCESS_KEY", ""),
+		S3SecretKey:     src.string("S3_SECRET_KEY", ""),
+		S3UseSSL:        src.bool("S3_USE_SSL", true),
+	}
+	if err := errors.Join(src.errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// IsDevelopment menandakan aplikasi berjalan di environment development
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// CategoryLoanPeriod mengembalikan lama peminjaman khusus kategori jika dikonfigurasi
+func (c *Config) CategoryLoanPeriod(category string) (int, bool) {
+	days, ok := c.CategoryLoanPeriods[strings.ToLower(strings.TrimSpace(category))]
+	return days, ok
 }
 
-// getEnv membantu mendapatkan nilai variabel lingkungan dengan nilai default
-func getEnv(key, defaultValue string) string {
+// CategoryFineRate mengembalikan denda per hari khusus kategori jika dikonfigurasi
+func (c *Config) CategoryFineRate(category string) (int64, bool) {
+	rate, ok := c.CategoryFineRates[strings.ToLower(strings.TrimSpace(category))]
+	return int64(rate), ok
+}
+
+// source membaca nilai konfigurasi dari variabel lingkungan, lalu dari file konfigurasi.
+// Nilai yang tidak valid dikumpulkan di errs supaya semua kesalahan dilaporkan sekaligus.
+type source struct {
+	file map[string]string
+	errs []error
+}
+
+// lookup mencari nilai mentah sebuah kunci, variabel lingkungan menimpa file konfigurasi
+func (s *source) lookup(key string) (string, bool) {
 	if value, exists := os.LookupEnv(key); exists {
+		return value, true
+	}
+	value, exists := s.file[key]
+	return value, exists
+}
+
+// invalid mencatat nilai yang tidak bisa dibaca
+func (s *source) invalid(key, value, expected string) {
+	s.errs = append(s.errs, fmt.Errorf("%s: %q is not %s", key, value, expected))
+}
+
+// string membaca nilai teks dengan nilai default
+func (s *source) string(key, defaultValue string) string {
+	if value, exists := s.lookup(key); exists {
 		return value
 	}
 	return defaultValue
 }
+
+// int membaca nilai bertipe angka
+func (s *source) int(key string, defaultValue int) int {
+	value, exists := s.lookup(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		s.invalid(key, value, "a whole number")
+		return defaultValue
+	}
+	return parsed
+}
+
+// int64 membaca nilai nominal uang
+func (s *source) int64(key string, defaultValue int64) int64 {
+	value, exists := s.lookup(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		s.invalid(key, value, "a whole number")
+		return defaultValue
+	}
+	return parsed
+}
+
+// bool membaca nilai boolean (true/false, 1/0)
+func (s *source) bool(key string, defaultValue bool) bool {
+	value, exists := s.lookup(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		s.invalid(key, value, "true or false")
+		return defaultValue
+	}
+	return parsed
+}
+
+// duration membaca durasi dengan satuan, contoh: 15m, 12h, 168h
+func (s *source) duration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := s.lookup(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		s.invalid(key, value, "a duration such as 15m or 168h")
+		return defaultValue
+	}
+	return parsed
+}
+
+// intMap membaca daftar pasangan "kunci:angka" yang dipisah koma,
+// contoh: CATEGORY_LOAN_PERIODS=fiksi:14,referensi:3
+func (s *source) intMap(key string) map[string]int {
+	result := map[string]int{}
+	value, exists := s.lookup(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, rawNumber, found := strings.Cut(pair, ":")
+		number, err := strconv.Atoi(strings.TrimSpace(rawNumber))
+		if !found || err != nil {
+			s.invalid(key, pair, "a name:number pair")
+			continue
+		}
+		result[strings.ToLower(strings.TrimSpace(name))] = number
+	}
+	return result
+}
+
+// formatIntMap menulis map kembali ke format "kunci:angka" yang dibaca intMap
+func formatIntMap(values map[string]any) (string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		switch number := values[name].(type) {
+		case int, int64:
+			pairs = append(pairs, fmt.Sprintf("%s:%d", name, number))
+		default:
+			return "", fmt.Errorf("%q must be a whole number", name)
+		}
+	}
+	return strings.Join(pairs, ","), nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFileWithEnvOverrides(t *testing.T) {
+	files := map[string]string{
+		"library.yaml": `
+app_env: staging
+jwt_secret: from-file
+access_token_ttl: 15m
+db_max_open_conns: 10
+category_loan_periods:
+  Referensi: 3
+`,
+		"library.toml": `
+# nilai dari file
+app_env = "staging"
+jwt_secret = "from-file"
+access_token_ttl = "15m"
+db_max_open_conns = 10
+
+[category_loan_periods]
+Referensi = 3 # kunci dibuat huruf kecil
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", writeConfigFile(t, name, content))
+			t.Setenv("JWT_SECRET", "from-env")
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Env != EnvStaging || cfg.JWTSecret != "from-env" || cfg.DBMaxOpenConns != 10 {
+				t.Errorf("got env %q, secret %q, max open conns %d", cfg.Env, cfg.JWTSecret, cfg.DBMaxOpenConns)
+			}
+			if cfg.AccessTokenTTL != 15*time.Minute || cfg.RefreshTokenTTL != 7*24*time.Hour {
+				t.Errorf("got token TTLs %v and %v", cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+			}
+			if !reflect.DeepEqual(cfg.CategoryLoanPeriods, map[string]int{"referensi": 3}) {
+				t.Errorf("CategoryLoanPeriods = %v", cfg.CategoryLoanPeriods)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "library.yaml", "jwt_secret: abc\nunknown_key: 1\n"))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "unknown_key") {
+		t.Errorf("Load() with an unknown key: error = %v", err)
+	}
+
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ACCESS_TOKEN_TTL", "30")
+	t.Setenv("MAX_RENEWALS", "two")
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "ACCESS_TOKEN_TTL") || !strings.Contains(err.Error(), "MAX_RENEWALS") {
+		t.Errorf("Load() error = %v, want both invalid values reported", err)
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	strongSecret := strings.Repeat("k", MinJWTSecretLength)
+
+	tests := []struct {
+		name    string
+		env     string
+		secret  string
+		dbPass  string
+		wantErr string
+	}{
+		{name: "development allows defaults", env: EnvDevelopment, secret: defaultJWTSecret, dbPass: defaultDBPass},
+		{name: "production with real secrets", env: EnvProduction, secret: strongSecret, dbPass: "s3cr3t-db"},
+		{name: "production default jwt secret", env: EnvProduction, secret: defaultJWTSecret, dbPass: "s3cr3t-db", wantErr: "JWT_SECRET is a default"},
+		{name: "production short jwt secret", env: EnvProduction, secret: "0123456789", dbPass: "s3cr3t-db", wantErr: "at least 32 characters"},
+		{name: "staging example db password", env: EnvStaging, secret: strongSecret, dbPass: "your_db_password", wantErr: "DB_PASS is a default"},
+		{name: "unknown environment", env: "prod", secret: strongSecret, dbPass: "s3cr3t-db", wantErr: "APP_ENV"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.Env, cfg.JWTSecret, cfg.DBPass = tc.env, tc.secret, tc.dbPass
+
+			err = cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{JWTSecret: "jwt", DBPass: "db", S3SecretKey: "", S3AccessKey: "access"}
+	printed := cfg.Redacted()
+
+	if printed.JWTSecret != redacted || printed.DBPass != redacted {
+		t.Errorf("secrets not redacted: %q, %q", printed.JWTSecret, printed.DBPass)
+	}
+	if printed.S3SecretKey != "" || printed.S3AccessKey != "access" {
+		t.Errorf("unset secret or non-secret changed: %q, %q", printed.S3SecretKey, printed.S3AccessKey)
+	}
+	if cfg.JWTSecret != "jwt" {
+		t.Errorf("Redacted modified the original config")
+	}
+}
+
+func TestReadConfigFileTOMLErrors(t *testing.T) {
+	for _, input := range []string{
+		`port = 3000 3001`,
+		`jwt_secret = "unterminated`,
+		"port = 1\nport = 2",
+		"[category_loan_periods]\nReferensi = 1.5",
+		`[category_loan_periods.nested]`,
+		`port = [3000, 3001]`,
+	} {
+		if _, err := readConfigFile(writeConfigFile(t, "library.toml", input)); err == nil {
+			t.Errorf("readConfigFile(%q) succeeded, want an error", input)
+		}
+	}
+	if values, err := readConfigFile(writeConfigFile(t, "library.toml", "# only a comment\n")); err != nil || len(values) != 0 {
+		t.Errorf("readConfigFile of a file without settings = %v, %v", values, err)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// readConfigFile membaca file konfigurasi YAML (.yaml/.yml) atau TOML (.toml). Kunci file adalah
+// nama variabel lingkungan dalam huruf kecil, misalnya jwt_secret atau access_token_ttl, dan
+// category_loan_periods/category_fine_rates ditulis sebagai mapping (tabel di TOML).
+// Hasilnya berbentuk seperti variabel lingkungan supaya dibaca dengan aturan yang sama.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	known := fileKeys()
+	settings := make(map[string]string, len(values))
+	for key, value := range values {
+		if !known[key] {
+			return nil, fmt.Errorf("config file %s: unknown setting %q", path, key)
+		}
+		envKey := strings.ToUpper(key)
+		switch value := value.(type) {
+		case nil:
+			settings[envKey] = ""
+		case map[string]any:
+			if settings[envKey], err = formatIntMap(value); err != nil {
+				return nil, fmt.Errorf("config file %s: %s: %w", path, key, err)
+			}
+		case []any:
+			return nil, fmt.Errorf("config file %s: %s must not be a list", path, key)
+		default:
+			settings[envKey] = fmt.Sprint(value)
+		}
+	}
+	return settings, nil
+}
+
+// fileKeys mengembalikan kunci yang boleh dipakai di file konfigurasi, diambil dari tag yaml Config
+func fileKeys() map[string]bool {
+	keys := map[string]bool{}
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		if tag := configType.Field(i).Tag.Get("yaml"); tag != "" && tag != "-" {
+			keys[tag] = true
+		}
+	}
+	return keys
+}
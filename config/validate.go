@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Secret bawaan yang hanya boleh dipakai di development
+const (
+	defaultJWTSecret = "supersecretjwtkey"
+	defaultDBPass    = "password"
+)
+
+// MinJWTSecretLength adalah panjang minimal JWT_SECRET di luar development (256 bit untuk HS256)
+const MinJWTSecretLength = 32
+
+// insecureSecrets berisi nilai default dan contoh dari .env.sample yang ditolak di luar development
+var insecureSecrets = map[string]bool{
+	defaultJWTSecret:      true,
+	defaultDBPass:         true,
+	"your_jwt_secret_key": true,
+	"your_db_password":    true,
+	"postgres":            true,
+	"minioadmin":          true,
+}
+
+// redacted menggantikan nilai secret pada output config print
+const redacted = "[redacted]"
+
+// Validate memeriksa konfigurasi saat startup dan mengembalikan semua pelanggaran sekaligus.
+// Di luar development secret default atau contoh dan JWT_SECRET yang terlalu pendek ditolak.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Env == EnvDevelopment || c.Env == EnvStaging || c.Env == EnvProduction,
+		"APP_ENV must be development, staging or production, got %q", c.Env)
+	check(isPort(c.Port), "PORT must be a port number, got %q", c.Port)
+	check(isPort(c.DBPort), "DB_PORT must be a port number, got %q", c.DBPort)
+	check(c.DBMaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(c.DBMaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.DBMaxOpenConns == 0 || c.DBMaxIdleConns <= c.DBMaxOpenConns,
+		"DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.DBMaxIdleConns, c.DBMaxOpenConns)
+	check(c.DBConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(c.DBConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	check(c.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+
+	check(c.JWTSecret != "", "JWT_SECRET is required")
+	check(c.AccessTokenTTL > 0, "ACCESS_TOKEN_TTL must be positive")
+	check(c.RefreshTokenTTL > c.AccessTokenTTL, "REFRESH_TOKEN_TTL must be longer than ACCESS_TOKEN_TTL")
+
+	check(c.LoanPeriodDays > 0, "LOAN_PERIOD_DAYS must be positive")
+	check(c.MaxRenewals >= 0, "MAX_RENEWALS must not be negative")
+	check(c.RenewalGraceDays >= 0, "RENEWAL_GRACE_DAYS must not be negative")
+	check(c.FineDailyRate >= 0, "FINE_DAILY_RATE must not be negative")
+	check(c.FineGraceDays >= 0, "FINE_GRACE_DAYS must not be negative")
+	check(c.FineMaxAmount >= 0, "FINE_MAX_AMOUNT must not be negative")
+	check(c.MaxOutstandingFine >= 0, "MAX_OUTSTANDING_FINE must not be negative")
+	check(c.HoldPickupDays > 0, "HOLD_PICKUP_DAYS must be positive")
+	for category, days := range c.CategoryLoanPeriods {
+		check(days > 0, "CATEGORY_LOAN_PERIODS: %s must be positive", category)
+	}
+	for category, rate := range c.CategoryFineRates {
+		check(rate >= 0, "CATEGORY_FINE_RATES: %s must not be negative", category)
+	}
+
+	switch c.StorageDriver {
+	case "local":
+		check(c.StorageLocalDir != "", "STORAGE_LOCAL_DIR is required for the local storage driver")
+	case "s3":
+		check(c.S3Bucket != "", "S3_BUCKET is required for the s3 storage driver")
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER must be local or s3, got %q", c.StorageDriver))
+	}
+
+	if !c.IsDevelopment() {
+		check(!insecureSecrets[c.JWTSecret], "JWT_SECRET is a default or example value, set a random secret for %s", c.Env)
+		check(len(c.JWTSecret) >= MinJWTSecretLength, "JWT_SECRET must be at least %d characters in %s", MinJWTSecretLength, c.Env)
+		check(!insecureSecrets[c.DBPass], "DB_PASS is a default or example value, set the real database password for %s", c.Env)
+		if c.StorageDriver == "s3" {
+			check(!insecureSecrets[c.S3SecretKey], "S3_SECRET_KEY is a default or example value, set the real key for %s", c.Env)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Warnings mengembalikan peringatan untuk development yang memakai secret default,
+// supaya fallback ke secret bawaan tidak terjadi diam-diam
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if c.JWTSecret == defaultJWTSecret {
+		warnings = append(warnings, "JWT_SECRET is not set, using the built-in development secret")
+	}
+	if c.DBPass == defaultDBPass {
+		warnings = append(warnings, "DB_PASS is not set, using the built-in development password")
+	}
+	return warnings
+}
+
+// Redacted mengembalikan salinan konfigurasi dengan secret disamarkan, untuk ditampilkan atau dicatat
+func (c *Config) Redacted() *Config {
+	copied := *c
+	for _, secret := range []*string{&copied.DBPass, &copied.JWTSecret, &copied.S3SecretKey} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	return &copied
+}
+
+// isPort memeriksa nomor port TCP
+func isPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"library/config"
-	"library/database"
 	"library/helpers"
 	"library/middleware"
 	"library/models"
+	"library/repositories"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -25,16 +27,29 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// AuthHandler menangani login, refresh token dan logout dengan secret serta masa berlaku token
+// dari konfigurasi yang dimuat saat startup
+type AuthHandler struct {
+	Config *config.Config
+	Users  repositories.UserRepository
+	Tokens repositories.RefreshTokenRepository
+}
+
+// NewAuthHandler membuat AuthHandler
+func NewAuthHandler(cfg *config.Config, users repositories.UserRepository, tokens repositories.RefreshTokenRepository) *AuthHandler {
+	return &AuthHandler{Config: cfg, Users: users, Tokens: tokens}
+}
+
 // Login mengautentikasi pengguna dan mengembalikan Access Token & Refresh Token
-func Login(c *fiber.Ctx) error {
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	req := new(LoginRequest)
 	if err := c.BodyParser(req); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	user := new(models.User)
 	// Cari pengguna berdasarkan email
-	if result := database.DBClient.Where("email = ?", req.Email).First(&user); result.Error != nil {
+	user, err := h.Users.FindByEmail(c.UserContext(), req.Email)
+	if err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid credentials")
 	}
 
@@ -43,19 +58,25 @@ func Login(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid credentials")
 	}
 
-	cfg := config.LoadConfig()
-
 	// Generate Access Token
-	accessToken, err := middleware.GenerateAccessToken(user.ID, cfg)
+	accessToken, err := middleware.GenerateAccessToken(user.ID, user.Role, h.Config)
 	if err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate access token")
 	}
 
-	// Generate Refresh Token
-	refreshToken, err := middleware.GenerateRefreshToken(user.ID, cfg)
+	// Setiap login memulai family (sesi) refresh token baru
+	session := &models.RefreshToken{
+		UserID:           user.ID,
+		FamilyID:         uuid.New(),
+		SessionStartedAt: time.Now(),
+	}
+	refreshToken, err := newRefreshToken(c, session, h.Config)
 	if err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate refresh token")
 	}
+	if err := h.Tokens.Create(c.UserContext(), session); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate refresh token")
+	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Login successful", fiber.Map{
 		"access_token":  accessToken,
@@ -63,56 +84,129 @@ func Login(c *fiber.Ctx) error {
 	})
 }
 
-// RefreshAccessToken memperbarui Access Token menggunakan Refresh Token
-func RefreshAccessToken(c *fiber.Ctx) error {
+// RefreshAccessToken memperbarui Access Token menggunakan Refresh Token.
+// Refresh token lama langsung dicabut (rotasi); jika token yang sudah dicabut dipakai lagi,
+// seluruh family dianggap bocor dan ikut dicabut.
+func (h *AuthHandler) RefreshAccessToken(c *fiber.Ctx) error {
 	req := new(RefreshTokenRequest)
 	if err := c.BodyParser(req); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	cfg := config.LoadConfig()
+	parsedUserID, err := parseRefreshToken(req.RefreshToken, h.Config)
+	if err != nil {
+		// Log error lebih detail untuk debugging
+		fmt.Printf("Refresh token parsing error: %v\n", err)
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	// Ambil ulang pengguna agar role di token baru selalu mengikuti data terkini
+	user, err := h.Users.FindByID(c.UserContext(), parsedUserID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User no longer exists")
+	}
+
+	next := &models.RefreshToken{UserID: user.ID}
+	refreshToken, err := newRefreshToken(c, next, h.Config)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate new refresh token")
+	}
+	err = h.Tokens.Rotate(c.UserContext(), middleware.HashToken(req.RefreshToken), user.ID, next)
+	if errors.Is(err, repositories.ErrTokenReused) {
+		// Token lama dipakai ulang: cabut seluruh sesi di family tersebut
+		if err := h.Tokens.RevokeFamilyOf(c.UserContext(), middleware.HashToken(req.RefreshToken)); err != nil {
+			return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not revoke session")
+		}
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Refresh token has already been used, session revoked")
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate new refresh token")
+	}
 
+	// Generate Access Token baru
+	newAccessToken, err := middleware.GenerateAccessToken(user.ID, user.Role, h.Config)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate new access token")
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Access token refreshed successfully", fiber.Map{
+		"access_token":  newAccessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// Logout mencabut sesi milik refresh token yang dikirim
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	req := new(RefreshTokenRequest)
+	if err := c.BodyParser(req); err != nil || req.RefreshToken == "" {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.Tokens.RevokeFamilyOf(c.UserContext(), middleware.HashToken(req.RefreshToken)); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not revoke session")
+	}
+
+	// Token yang tidak dikenal tetap dianggap berhasil agar logout bersifat idempoten
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll mencabut semua sesi milik pengguna yang sedang login
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	revoked, err := h.Tokens.RevokeAll(c.UserContext(), userID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Logged out from all sessions", fiber.Map{
+		"revoked_sessions": revoked,
+	})
+}
+
+// parseRefreshToken memverifikasi tanda tangan dan masa berlaku refresh token lalu mengembalikan user ID
+func parseRefreshToken(tokenString string, cfg *config.Config) (uuid.UUID, error) {
 	// Parse dan verifikasi Refresh Token
-	token, err := jwt.Parse(req.RefreshToken, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(cfg.JWTSecret), nil
 	})
-
 	if err != nil {
-		// Log error lebih detail untuk debugging
-		fmt.Printf("Refresh token parsing error: %v\n", err)
-		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid or expired refresh token")
+		return uuid.Nil, err
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid refresh token claims")
+		return uuid.Nil, errors.New("invalid refresh token claims")
 	}
 
 	// Ambil userID dari claims
-	userIDStr, ok := claims["user_id"].(string) // claims["user_id"] biasanya di-parse sebagai float64
+	userIDStr, ok := claims["user_id"].(string)
 	if !ok {
-		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID in refresh token is not a string or missing")
-	}
-	parsedUserID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid user ID format in refresh token")
-	}
-	// Generate Access Token baru
-	newAccessToken, err := middleware.GenerateAccessToken(parsedUserID, cfg)
-	if err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate new access token")
+		return uuid.Nil, errors.New("user ID in refresh token is not a string or missing")
 	}
+	return uuid.Parse(userIDStr)
+}
 
-	newRefreshToken, err := middleware.GenerateRefreshToken(parsedUserID, cfg)
+// newRefreshToken membuat refresh token baru untuk record.UserID dan mengisi hash, masa berlaku
+// serta info perangkatnya ke record. Record belum disimpan.
+func newRefreshToken(c *fiber.Ctx, record *models.RefreshToken, cfg *config.Config) (string, error) {
+	token, expiresAt, err := middleware.GenerateRefreshToken(record.UserID, cfg)
 	if err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not generate new refresh token")
+		return "", err
 	}
 
-	return helpers.SuccessResponse(c, fiber.StatusOK, "Access token refreshed successfully", fiber.Map{
-		"access_token":  newAccessToken,
-		"refresh_token": newRefreshToken, // Kirim refresh token baru jika rotasi diaktifkan
-	})
+	record.TokenHash = middleware.HashToken(token)
+	record.ExpiresAt = expiresAt
+	record.UserAgent = c.Get(fiber.HeaderUserAgent)
+	record.IPAddress = c.IP()
+	return token, nil
 }
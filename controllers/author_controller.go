@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AuthorHandler menangani endpoint data penulis
+type AuthorHandler struct {
+	Authors *services.AuthorService
+}
+
+// NewAuthorHandler membuat AuthorHandler
+func NewAuthorHandler(authors *services.AuthorService) *AuthorHandler {
+	return &AuthorHandler{Authors: authors}
+}
+
+// AuthorRequest adalah body permintaan membuat atau mengganti nama penulis
+type AuthorRequest struct {
+	Name string `json:"name"`
+}
+
+// authorListSpec adalah filter, urutan dan field yang didukung GetAllAuthors
+var authorListSpec = helpers.ListSpec{
+	Filters: map[string]helpers.FilterSpec{
+		"name": {Column: "name", Operator: helpers.FilterContains},
+	},
+	Sortable: map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	Fields: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"created_at": "CreatedAt",
+		"updated_at": "UpdatedAt",
+	},
+	DefaultSort: "name",
+	TieBreaker:  "id",
+}
+
+// GetAllAuthors menampilkan daftar penulis dengan filter ?name=, ?sort= dan pagination
+// yang sama seperti GetAllBooks
+func (h *AuthorHandler) GetAllAuthors(c *fiber.Ctx) error {
+	pagination, err := helpers.ParsePagination(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	listQuery, err := helpers.ParseListQuery(c, authorListSpec)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+
+	authors, page, err := h.Authors.List(c.UserContext(), listQuery, pagination)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	if page.Data, err = listQuery.Project(authors); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Authors retrieved successfully", page)
+}
+
+// GetAuthorByID menampilkan seorang penulis beserta buku-bukunya
+func (h *AuthorHandler) GetAuthorByID(c *fiber.Ctx) error {
+	authorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid author ID format")
+	}
+
+	author, err := h.Authors.Get(c.UserContext(), authorID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Author retrieved successfully", author)
+}
+
+// CreateAuthor menambahkan penulis baru (staff)
+func (h *AuthorHandler) CreateAuthor(c *fiber.Ctx) error {
+	req := new(AuthorRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	author, err := h.Authors.Create(c.UserContext(), req.Name)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Author created successfully", author)
+}
+
+// UpdateAuthor mengganti nama penulis (staff). Kolom author pada buku-bukunya ikut diperbarui.
+func (h *AuthorHandler) UpdateAuthor(c *fiber.Ctx) error {
+	authorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid author ID format")
+	}
+
+	req := new(AuthorRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	author, err := h.Authors.Rename(c.UserContext(), authorID, req.Name)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Author updated successfully", author)
+}
+
+// DeleteAuthor menghapus penulis yang tidak lagi dihubungkan ke buku mana pun (staff)
+func (h *AuthorHandler) DeleteAuthor(c *fiber.Ctx) error {
+	authorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid author ID format")
+	}
+
+	if err := h.Authors.Delete(c.UserContext(), authorID); err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Author deleted successfully", nil)
+}
@@ -1,85 +1,172 @@
 package controllers
 
 import (
-	"library/database" // Sesuaikan dengan nama proyekmu
-	"library/helpers"  // Sesuaikan dengan nama proyekmu
-	"library/models"   // Sesuaikan dengan nama proyekmu
-	"strconv"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"library/services"     // Sesuaikan dengan nama proyekmu
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
-// CreateBooks membuat pengguna baru
-func CreateBook(c *fiber.Ctx) error {
+// BookHandler menangani endpoint katalog buku
+type BookHandler struct {
+	Catalog *services.CatalogService
+}
+
+// NewBookHandler membuat BookHandler
+func NewBookHandler(catalog *services.CatalogService) *BookHandler {
+	return &BookHandler{Catalog: catalog}
+}
+
+// BookLinksRequest berisi ID penulis dan kategori yang dihubungkan ke buku. Field yang tidak
+// dikirim diisi dari teks author dan category: penulis (dipisah titik koma) dan kategori dicari
+// tanpa membedakan huruf besar/kecil, dan dibuat jika belum ada. Daftar kosong melepas semuanya.
+type BookLinksRequest struct {
+	AuthorIDs   []uuid.UUID `json:"author_ids"`
+	CategoryIDs []uuid.UUID `json:"category_ids"`
+}
+
+// CreateBook membuat buku baru. ISBN divalidasi dan disimpan sebagai ISBN-13 tanpa tanda hubung.
+// Penulis dan kategori dihubungkan lewat author_ids dan category_ids, atau dari teks author dan category.
+func (h *BookHandler) CreateBook(c *fiber.Ctx) error {
 	Books := new(models.Book)
+	links := new(BookLinksRequest)
 
 	if err := c.BodyParser(Books); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
+	if err := c.BodyParser(links); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
 
-	if result := database.DBClient.Create(&Books); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	// Quantity saat membuat buku dipakai untuk membuat eksemplar dengan barcode otomatis,
+	// label barcode asli bisa didaftarkan lewat /books/:id/copies
+	err := h.Catalog.Create(c.UserContext(), Books, repositories.BookLinks{
+		AuthorIDs:   links.AuthorIDs,
+		Author:      Books.Author,
+		CategoryIDs: links.CategoryIDs,
+		Category:    Books.Category,
+	})
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusCreated, "Books created successfully", Books)
 }
 
-// GetAllBook mendapatkan semua pengguna
-func GetAllBooks(c *fiber.Ctx) error {
-	var books []models.Book
-	var total int64
-	page, err := strconv.Atoi(c.Query("page", "1")) // Ambil "page" dari URL, default "1"
-	if err != nil || page < 1 {
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page number")
-	}
+// bookListSpec adalah filter, urutan dan field yang didukung GetAllBooks
+var bookListSpec = helpers.ListSpec{
+	Filters: map[string]helpers.FilterSpec{
+		"category":     {Column: "category", Operator: helpers.FilterIEquals},
+		"author":       {Column: "author", Operator: helpers.FilterIEquals},
+		"title":        {Column: "title", Operator: helpers.FilterContains},
+		"isbn":         {Column: "isbn", Operator: helpers.FilterEquals},
+		"created_from": {Column: "created_at", Operator: helpers.FilterDateFrom},
+		"created_to":   {Column: "created_at", Operator: helpers.FilterDateTo},
+	},
+	Sortable: map[string]string{
+		"title":      "title",
+		"author":     "author",
+		"category":   "category",
+		"quantity":   "quantity",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	Fields: map[string]string{
+		"id":               "id",
+		"title":            "title",
+		"author":           "author",
+		"isbn":             "isbn",
+		"quantity":         "quantity",
+		"category":         "category",
+		"replacement_cost": "replacement_cost",
+		"authors":          "authors",
+		"categories":       "categories",
+		"covers":           "covers",
+		"created_at":       "CreatedAt",
+		"updated_at":       "UpdatedAt",
+	},
+	DefaultSort: "created_at",
+	TieBreaker:  "id",
+	Nullable:    map[string]bool{"title": true, "author": true, "category": true, "created_at": true, "updated_at": true},
+}
 
-	limit, err := strconv.Atoi(c.Query("limit", "10")) // Ambil "limit" dari URL, default "10"
-	if err != nil || limit < 1 {
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit number")
+// GetAllBooks mendapatkan semua buku. Mendukung filter (category, author, title, isbn,
+// created_from, created_to), ?sort= dan ?fields= sesuai bookListSpec, serta pagination
+// offset (?page=) atau cursor (?pagination=cursor lalu ?cursor=).
+func (h *BookHandler) GetAllBooks(c *fiber.Ctx) error {
+	pagination, err := helpers.ParsePagination(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
 	}
-	offset := (page - 1) * limit
-
-	if result := database.DBClient.Model(&models.Book{}).Count(&total); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	listQuery, err := helpers.ParseListQuery(c, bookListSpec)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
 	}
-	if result := database.DBClient.Limit(limit).Offset(offset).Find(&books); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+
+	books, page, err := h.Catalog.List(c.UserContext(), listQuery, pagination)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
 	}
-	if len(books) == 0 && page > 1 { // Jika halaman lebih dari 1 dan tidak ada buku, berarti halaman kosong
+	if len(books) == 0 && pagination.Page > 1 { // Jika halaman lebih dari 1 dan tidak ada buku, berarti halaman kosong
 		return helpers.ErrorResponse(c, fiber.StatusNotFound, "No books found on this page")
-	} else if len(books) == 0 { // Jika di halaman 1 pun tidak ada buku sama sekali
-		return helpers.SuccessResponse(c, fiber.StatusOK, "No books found", []models.Book{})
 	}
-	totalPages := (total + int64(limit) - 1) / int64(limit)
-	return helpers.SuccessResponse(c, fiber.StatusOK, "Books retrieved successfully", fiber.Map{
-		"data":         books,
-		"total_items":  total,
-		"current_page": page,
-		"per_page":     limit,
-		"total_pages":  totalPages,
-	})
+	if page.Data, err = listQuery.Project(books); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if len(books) == 0 { // Jika di halaman pertama pun tidak ada buku sama sekali
+		return helpers.SuccessResponse(c, fiber.StatusOK, "No books found", page)
+	}
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Books retrieved successfully", page)
 }
 
 // GetBooksByID mendapatkan pengguna berdasarkan ID
-func GetBooksByID(c *fiber.Ctx) error {
+func (h *BookHandler) GetBooksByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	bookID, err := uuid.Parse(idStr)
 	if err != nil {
 		// Jika ID dari URL bukan UUID yang valid
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
 	}
-	Books := new(models.Book)
 
-	if result := database.DBClient.First(&Books, bookID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Books not found")
+	Books, err := h.Catalog.Get(c.UserContext(), bookID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Books retrieved successfully", Books)
 }
 
+// GetBookByIsbn mencari buku berdasarkan ISBN-10 atau ISBN-13, dengan atau tanpa tanda hubung
+func (h *BookHandler) GetBookByIsbn(c *fiber.Ctx) error {
+	book, err := h.Catalog.GetByISBN(c.UserContext(), c.Params("isbn"))
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Books retrieved successfully", book)
+}
+
+// BookUpdateRequest menampung field buku yang boleh diperbarui.
+// Field numerik berupa pointer supaya nilai 0 bisa dibedakan dari field yang tidak dikirim.
+// author_ids dan category_ids menggantikan penulis dan kategori buku; jika tidak dikirim,
+// teks author atau category yang dikirim dipakai seperti pada CreateBook.
+type BookUpdateRequest struct {
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	Isbn     string `json:"isbn"`
+	Quantity *int   `json:"quantity"`
+	Category string `json:"category"`
+
+	ReplacementCost *int64 `json:"replacement_cost"`
+
+	BookLinksRequest
+}
+
 // UpdateBooks memperbarui pengguna
-func UpdateBooks(c *fiber.Ctx) error {
+func (h *BookHandler) UpdateBooks(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 
 	BooksID, err := uuid.Parse(idStr)
@@ -88,41 +175,33 @@ func UpdateBooks(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Books ID format")
 	}
 
-	Books := new(models.Book)
-
-	if result := database.DBClient.First(&Books, BooksID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Books not found")
-	}
-
-	updates := new(models.Book)
+	updates := new(BookUpdateRequest)
 	if err := c.BodyParser(updates); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if updates.Title != "" {
-		Books.Title = updates.Title
-	}
-	if updates.Author != "" {
-		Books.Author = updates.Author
-	}
-	if updates.Isbn != "" {
-		Books.Isbn = updates.Isbn
-	}
-	if updates.Quantity != "" {
-		Books.Quantity = updates.Quantity
-	}
-	if updates.Category != "" {
-		Books.Category = updates.Category
-	}
-	if result := database.DBClient.Save(&Books); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	// Quantity dihitung dari eksemplar, perubahan jumlah dilakukan lewat /books/:id/copies
+	Books, err := h.Catalog.Update(c.UserContext(), BooksID, services.BookUpdate{
+		Title:           updates.Title,
+		Isbn:            updates.Isbn,
+		Quantity:        updates.Quantity,
+		ReplacementCost: updates.ReplacementCost,
+		Links: repositories.BookLinks{
+			AuthorIDs:   updates.AuthorIDs,
+			Author:      updates.Author,
+			CategoryIDs: updates.CategoryIDs,
+			Category:    updates.Category,
+		},
+	})
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Books updated successfully", Books)
 }
 
 // DeleteBooks menghapus pengguna
-func DeleteBooks(c *fiber.Ctx) error {
+func (h *BookHandler) DeleteBooks(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	BooksID, err := uuid.Parse(idStr)
 	if err != nil {
@@ -130,24 +209,18 @@ func DeleteBooks(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Books ID format")
 	}
 
-	Books := new(models.Book)
-
-	if result := database.DBClient.First(&Books, BooksID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Books not found")
-	}
-
-	if result := database.DBClient.Delete(&Books); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	if err := h.Catalog.Delete(c.UserContext(), BooksID); err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Books deleted successfully", nil)
 }
 
 // GetAllBooksNoPagination mendapatkan semua buku tanpa pagination
-func GetAllBooksNoPagination(c *fiber.Ctx) error {
-	var books []models.Book
-	if result := database.DBClient.Find(&books); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+func (h *BookHandler) GetAllBooksNoPagination(c *fiber.Ctx) error {
+	books, err := h.Catalog.All(c.UserContext())
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 	if len(books) == 0 {
 		return helpers.SuccessResponse(c, fiber.StatusOK, "No books found", []models.Book{})
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/models"
+	"library/repositories"
+	"library/services"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	testBookID        = uuid.MustParse("2b8f4c1e-5a6d-4e3f-8b7a-9c0d1e2f3a4b")
+	testDeletedBookID = uuid.MustParse("3c9a5d2f-6b7e-4f80-9c8b-0d1e2f3a4b5c")
+	testAuthorID      = uuid.MustParse("4dab6e30-7c8f-4091-8d9c-1e2f3a4b5c6d")
+)
+
+// newTestBookRepository berisi satu buku aktif dengan 2 eksemplar available,
+// satu buku yang sudah dihapus, dan satu penulis
+func newTestBookRepository() *repositories.MemoryBookRepository {
+	books := repositories.NewMemoryBookRepository(
+		models.Book{ID: testBookID, Title: "The Odyssey", Author: "Homer", Isbn: "9780140449136", Quantity: 3, Category: "Classics"},
+		models.Book{ID: testDeletedBookID, Title: "Introduction to Algorithms", Isbn: "9780262033848",
+			Model: gorm.Model{DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}},
+	)
+	books.SetAvailableCopies(testBookID, 2)
+	books.AddAuthors(models.Author{ID: testAuthorID, Name: "Ursula K. Le Guin"})
+	return books
+}
+
+func bookRoutes(h *BookHandler) func(app *fiber.App) {
+	return func(app *fiber.App) {
+		app.Post("/books", h.CreateBook)
+		app.Get("/books", h.GetAllBooks)
+		app.Get("/books/all", h.GetAllBooksNoPagination)
+		app.Get("/books/isbn/:isbn", h.GetBookByIsbn)
+		app.Get("/books/:id", h.GetBooksByID)
+		app.Put("/books/:id", h.UpdateBooks)
+		app.Delete("/books/:id", h.DeleteBooks)
+	}
+}
+
+func TestBookHandler(t *testing.T) {
+	tests := []handlerCase{
+		{
+			name: "create normalizes isbn and links authors from text", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/books",
+			body:       `{"title":"Sample","author":"Jane Doe; john roe ;Jane Doe","isbn":"0-306-40615-2","quantity":2,"category":"Science"}`,
+			wantStatus: fiber.StatusCreated,
+			check: func(t *testing.T, resp testResponse) {
+				var book models.Book
+				decodeData(t, resp, &book)
+				if book.Isbn != "9780306406157" {
+					t.Errorf("isbn = %q, want 9780306406157", book.Isbn)
+				}
+				if book.Author != "Jane Doe; john roe" || len(book.Authors) != 2 {
+					t.Errorf("author = %q with %d authors, want 2 distinct authors", book.Author, len(book.Authors))
+				}
+				if book.Category != "Science" || len(book.Categories) != 1 {
+					t.Errorf("category = %q with %d categories", book.Category, len(book.Categories))
+				}
+			},
+		},
+		{
+			name: "create links authors by id", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/books",
+			body:       `{"title":"The Dispossessed","author":"ignored","isbn":"9781861972712","author_ids":["` + testAuthorID.String() + `"]}`,
+			wantStatus: fiber.StatusCreated,
+			check: func(t *testing.T, resp testResponse) {
+				var book models.Book
+				decodeData(t, resp, &book)
+				if book.Author != "Ursula K. Le Guin" {
+					t.Errorf("author = %q, want Ursula K. Le Guin", book.Author)
+				}
+			},
+		},
+		{
+			name: "create rejects unknown author id", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/books",
+			body:       `{"title":"Sample","isbn":"9780306406157","author_ids":["` + uuid.NewString() + `"]}`,
+			wantStatus: fiber.StatusNotFound, wantCode: helpers.ErrCodeAuthorNotFound,
+		},
+		{
+			name: "create rejects invalid isbn", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/books",
+			body:       `{"title":"Sample","isbn":"12345"}`,
+			wantStatus: fiber.StatusBadRequest, wantCode: helpers.ErrCodeInvalidISBN,
+		},
+		{
+			name: "create rejects negative quantity", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/books",
+			body:       `{"title":"Sample","isbn":"9780306406157","quantity":-1}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "create rejects isbn held by a deleted book", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/books",
+			body:       `{"title":"CLRS","isbn":"978-0-262-03384-8"}`,
+			wantStatus: fiber.StatusConflict, wantCode: helpers.ErrCodeISBNExists,
+		},
+		{
+			name: "list first page", role: models.RoleMember,
+			method: http.MethodGet, path: "/books?limit=10",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var page struct {
+					Data       []models.Book `json:"data"`
+					TotalItems int64         `json:"total_items"`
+				}
+				decodeData(t, resp, &page)
+				if len(page.Data) != 1 || page.TotalItems != 1 {
+					t.Errorf("got %d books of %d, want 1 of 1 (deleted books hidden)", len(page.Data), page.TotalItems)
+				}
+			},
+		},
+		{
+			name: "list projects requested fields", role: models.RoleMember,
+			method: http.MethodGet, path: "/books?fields=title",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var page struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				decodeData(t, resp, &page)
+				if len(page.Data) != 1 || len(page.Data[0]) != 1 || page.Data[0]["title"] != "The Odyssey" {
+					t.Errorf("data = %v, want only the title field", page.Data)
+				}
+			},
+		},
+		{
+			name: "list page past the end", role: models.RoleMember,
+			method: http.MethodGet, path: "/books?page=2",
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "list rejects unknown sort field", role: models.RoleMember,
+			method: http.MethodGet, path: "/books?sort=password",
+			wantStatus: fiber.StatusBadRequest, wantCode: helpers.ErrCodeInvalidQuery,
+		},
+		{
+			name: "list all without pagination", role: models.RoleMember,
+			method: http.MethodGet, path: "/books/all",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var books []models.Book
+				decodeData(t, resp, &books)
+				if len(books) != 1 {
+					t.Errorf("got %d books, want 1", len(books))
+				}
+			},
+		},
+		{
+			name: "get by id includes available copies", role: models.RoleMember,
+			method: http.MethodGet, path: "/books/" + testBookID.String(),
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var book models.Book
+				decodeData(t, resp, &book)
+				if book.AvailableCopies == nil || *book.AvailableCopies != 2 {
+					t.Errorf("available_copies = %v, want 2", book.AvailableCopies)
+				}
+			},
+		},
+		{
+			name: "get by id rejects malformed id", role: models.RoleMember,
+			method: http.MethodGet, path: "/books/not-a-uuid",
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "get by id hides deleted book", role: models.RoleMember,
+			method: http.MethodGet, path: "/books/" + testDeletedBookID.String(),
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "get by isbn-10 with hyphens", role: models.RoleMember,
+			method: http.MethodGet, path: "/books/isbn/0-14-044913-2",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var book models.Book
+				decodeData(t, resp, &book)
+				if book.ID != testBookID {
+					t.Errorf("id = %s, want %s", book.ID, testBookID)
+				}
+			},
+		},
+		{
+			name: "get by unknown isbn", role: models.RoleMember,
+			method: http.MethodGet, path: "/books/isbn/9780306406157",
+			wantStatus: fiber.StatusNotFound, wantCode: helpers.ErrCodeBookNotFound,
+		},
+		{
+			name: "update title and category", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/books/" + testBookID.String(),
+			body:       `{"title":"The Odyssey (Fagles)","category":"Poetry"}`,
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var book models.Book
+				decodeData(t, resp, &book)
+				if book.Title != "The Odyssey (Fagles)" || book.Category != "Poetry" || book.Author != "Homer" {
+					t.Errorf("got title %q, category %q, author %q", book.Title, book.Category, book.Author)
+				}
+			},
+		},
+		{
+			name: "update rejects quantity change", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/books/" + testBookID.String(),
+			body:       `{"quantity":10}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "update rejects isbn of another book", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/books/" + testBookID.String(),
+			body:       `{"isbn":"9780262033848"}`,
+			wantStatus: fiber.StatusConflict, wantCode: helpers.ErrCodeISBNExists,
+		},
+		{
+			name: "update unknown book", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/books/" + uuid.NewString(),
+			body:       `{"title":"Missing"}`,
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "delete book", role: models.RoleLibrarian,
+			method: http.MethodDelete, path: "/books/" + testBookID.String(),
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "delete already deleted book", role: models.RoleLibrarian,
+			method: http.MethodDelete, path: "/books/" + testDeletedBookID.String(),
+			wantStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runHandlerCase(t, tc, bookRoutes(NewBookHandler(services.NewCatalogService(newTestBookRepository()))))
+		})
+	}
+}
+
+func TestBookHandlerEmptyCatalogue(t *testing.T) {
+	h := NewBookHandler(services.NewCatalogService(repositories.NewMemoryBookRepository()))
+	runHandlerCase(t, handlerCase{
+		role: models.RoleMember, method: http.MethodGet, path: "/books",
+		wantStatus: fiber.StatusOK,
+		check: func(t *testing.T, resp testResponse) {
+			if resp.Message != "No books found" {
+				t.Errorf("message = %q, want No books found", resp.Message)
+			}
+		},
+	}, bookRoutes(h))
+}
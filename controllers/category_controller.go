@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CategoryHandler menangani endpoint pohon kategori
+type CategoryHandler struct {
+	Categories *services.CategoryService
+}
+
+// NewCategoryHandler membuat CategoryHandler
+func NewCategoryHandler(categories *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{Categories: categories}
+}
+
+// CategoryRequest adalah body permintaan membuat atau memperbarui kategori.
+// parent_id "" menjadikan kategori sebagai kategori utama, field yang tidak dikirim tidak diubah.
+type CategoryRequest struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+// GetCategories menampilkan semua kategori sebagai pohon, diurutkan berdasarkan nama
+func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
+	tree, err := h.Categories.Tree(c.UserContext())
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Categories retrieved successfully", tree)
+}
+
+// GetCategoryByID menampilkan sebuah kategori beserta subkategori langsungnya
+func (h *CategoryHandler) GetCategoryByID(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid category ID format")
+	}
+
+	category, err := h.Categories.Get(c.UserContext(), categoryID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Category retrieved successfully", category)
+}
+
+// CreateCategory membuat kategori baru, sebagai subkategori jika parent_id diisi (staff)
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	req := new(CategoryRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	parentID, err := parseParentID(req.ParentID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid parent ID format")
+	}
+
+	category, err := h.Categories.Create(c.UserContext(), req.Name, parentID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Category created successfully", category)
+}
+
+// UpdateCategory mengganti nama atau memindahkan kategori ke induk lain (staff).
+// Kategori tidak bisa dipindahkan ke bawah dirinya sendiri atau subkategorinya.
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid category ID format")
+	}
+
+	req := new(CategoryRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	parentID, err := parseParentID(req.ParentID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid parent ID format")
+	}
+
+	category, err := h.Categories.Update(c.UserContext(), categoryID, services.CategoryUpdate{
+		Name:     req.Name,
+		Move:     req.ParentID != nil,
+		ParentID: parentID,
+	})
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Category updated successfully", category)
+}
+
+// DeleteCategory menghapus kategori yang tidak punya subkategori maupun buku (staff)
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid category ID format")
+	}
+
+	if err := h.Categories.Delete(c.UserContext(), categoryID); err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Category deleted successfully", nil)
+}
+
+// parseParentID membaca parent_id dari request; nil atau "" berarti kategori utama
+func parseParentID(value *string) (*uuid.UUID, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(strings.TrimSpace(*value))
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
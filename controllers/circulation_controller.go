@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CirculationHandler menangani endpoint meja sirkulasi, perpanjangan, hold dan denda
+type CirculationHandler struct {
+	Circulation *services.CirculationService
+}
+
+// NewCirculationHandler membuat CirculationHandler
+func NewCirculationHandler(circulation *services.CirculationService) *CirculationHandler {
+	return &CirculationHandler{Circulation: circulation}
+}
+
+// CheckoutRequest adalah body permintaan peminjaman di meja sirkulasi dengan memindai barcode
+type CheckoutRequest struct {
+	Barcode string `json:"barcode"`
+	UserID  string `json:"user_id"`
+}
+
+// BarcodeReturnRequest adalah body permintaan pengembalian dengan memindai barcode
+type BarcodeReturnRequest struct {
+	Barcode string `json:"barcode"`
+}
+
+// CheckoutByBarcode meminjamkan eksemplar hasil pindaian barcode kepada anggota (staff)
+func (h *CirculationHandler) CheckoutByBarcode(c *fiber.Ctx) error {
+	req := new(CheckoutRequest)
+	if err := c.BodyParser(req); err != nil || req.Barcode == "" {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
+	}
+
+	record, err := h.Circulation.CheckoutByBarcode(c.UserContext(), req.Barcode, userID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Checkout successful", record)
+}
+
+// ReturnByBarcode memproses pengembalian eksemplar hasil pindaian barcode (staff)
+func (h *CirculationHandler) ReturnByBarcode(c *fiber.Ctx) error {
+	req := new(BarcodeReturnRequest)
+	if err := c.BodyParser(req); err != nil || req.Barcode == "" {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	record, fine, err := h.Circulation.ReturnByBarcode(c.UserContext(), req.Barcode)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Book returned successfully", ReturnResponse{
+		Lending_records: *record,
+		FineAmount:      fine,
+	})
+}
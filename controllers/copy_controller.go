@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// BookCopyRequest adalah body permintaan menambah atau memperbarui eksemplar
+type BookCopyRequest struct {
+	Barcode       string     `json:"barcode"`
+	ShelfLocation string     `json:"shelf_location"`
+	Condition     string     `json:"condition"`
+	Status        string     `json:"status"`
+	AcquiredAt    *time.Time `json:"acquired_at"`
+}
+
+// CopyHandler menangani endpoint eksemplar. Perubahan eksemplar bisa meneruskan antrean hold,
+// jadi semuanya lewat CirculationService.
+type CopyHandler struct {
+	Circulation *services.CirculationService
+}
+
+// NewCopyHandler membuat CopyHandler
+func NewCopyHandler(circulation *services.CirculationService) *CopyHandler {
+	return &CopyHandler{Circulation: circulation}
+}
+
+// input mengubah body permintaan menjadi services.CopyInput
+func (r *BookCopyRequest) input() services.CopyInput {
+	return services.CopyInput{
+		Barcode:       r.Barcode,
+		ShelfLocation: r.ShelfLocation,
+		Condition:     r.Condition,
+		Status:        r.Status,
+		AcquiredAt:    r.AcquiredAt,
+	}
+}
+
+// GetBookCopies menampilkan semua eksemplar sebuah buku (staff)
+func (h *CopyHandler) GetBookCopies(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+
+	copies, err := h.Circulation.Copies(c.UserContext(), bookID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Copies retrieved successfully", copies)
+}
+
+// CreateBookCopy mendaftarkan eksemplar baru untuk sebuah buku (staff)
+func (h *CopyHandler) CreateBookCopy(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+
+	req := new(BookCopyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	bookCopy, err := h.Circulation.AddCopy(c.UserContext(), bookID, req.input())
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Copy created successfully", bookCopy)
+}
+
+// GetCopyByBarcode mencari eksemplar berdasarkan hasil pindaian barcode (staff)
+func (h *CopyHandler) GetCopyByBarcode(c *fiber.Ctx) error {
+	bookCopy, err := h.Circulation.CopyByBarcode(c.UserContext(), c.Params("barcode"))
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Copy retrieved successfully", bookCopy)
+}
+
+// UpdateBookCopy memperbarui lokasi rak, kondisi atau status eksemplar (staff).
+// Status hanya bisa diatur manual antara available, damaged dan withdrawn;
+// eksemplar yang sedang dipinjam, disisihkan atau hilang diatur oleh alur sirkulasi.
+func (h *CopyHandler) UpdateBookCopy(c *fiber.Ctx) error {
+	copyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid copy ID format")
+	}
+
+	req := new(BookCopyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	bookCopy, err := h.Circulation.UpdateCopy(c.UserContext(), copyID, req.input())
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Copy updated successfully", bookCopy)
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"library/helpers"
+	"library/models"
+	"library/repositories"
+	"library/storage"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// coverCacheControl dipakai untuk gambar cover. URL cover memuat hash gambar, sehingga
+// isinya tidak pernah berubah dan boleh di-cache selamanya.
+const coverCacheControl = "public, max-age=31536000, immutable"
+
+// CoverHandler menangani unggah, hapus dan penyajian cover buku. File cover disimpan di Storage.
+type CoverHandler struct {
+	Books   repositories.BookRepository
+	Storage storage.Storage
+}
+
+// NewCoverHandler membuat CoverHandler
+func NewCoverHandler(books repositories.BookRepository, store storage.Storage) *CoverHandler {
+	return &CoverHandler{Books: books, Storage: store}
+}
+
+// UploadBookCover mengunggah cover buku (multipart field "file", JPEG/PNG/WebP).
+// Gambar divalidasi lalu disimpan sebagai JPEG dalam setiap ukuran models.CoverSizes;
+// cover lama dihapus dari storage setelah cover baru tersimpan.
+func (h *CoverHandler) UploadBookCover(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+	book, err := h.Books.FindByID(c.UserContext(), bookID)
+	if err != nil {
+		return coverBookError(c, err)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "An image file is required in the 'file' field")
+	}
+	if fileHeader.Size > helpers.MaxCoverUploadBytes {
+		return helpers.ErrorResponseWithCode(c, fiber.StatusRequestEntityTooLarge, helpers.ErrCodeImageTooLarge,
+			fmt.Sprintf("Cover images must be at most %d MB", helpers.MaxCoverUploadBytes>>20))
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Could not read uploaded file")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, helpers.MaxCoverUploadBytes+1))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Could not read uploaded file")
+	}
+
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:8])
+	if version == book.CoverVersion {
+		return helpers.SuccessResponse(c, fiber.StatusOK, "Cover is unchanged", book)
+	}
+
+	img, err := helpers.DecodeCoverImage(data)
+	if err != nil {
+		return helpers.ErrorResponseWithCode(c, fiber.StatusBadRequest, helpers.ErrCodeInvalidImage, err.Error())
+	}
+	for _, size := range models.CoverSizes {
+		thumbnail, err := helpers.EncodeJPEG(helpers.ResizeToWidth(img, size.Width))
+		if err != nil {
+			return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+		}
+		key := models.CoverKey(book.ID, version, size.Name)
+		if err := h.Storage.Put(c.Context(), key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+			return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not store cover: "+err.Error())
+		}
+	}
+
+	previous := book.CoverVersion
+	if err := h.Books.SetCoverVersion(c.UserContext(), book, version); err != nil {
+		h.deleteCoverFiles(c, book.ID, version)
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	book.SetCoverURLs()
+	if previous != "" {
+		h.deleteCoverFiles(c, book.ID, previous)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Cover uploaded successfully", book)
+}
+
+// DeleteBookCover menghapus cover buku
+func (h *CoverHandler) DeleteBookCover(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+	book, err := h.Books.FindByID(c.UserContext(), bookID)
+	if err != nil {
+		return coverBookError(c, err)
+	}
+	if book.CoverVersion == "" {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Book has no cover")
+	}
+
+	previous := book.CoverVersion
+	if err := h.Books.SetCoverVersion(c.UserContext(), book, ""); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	h.deleteCoverFiles(c, book.ID, previous)
+	book.SetCoverURLs()
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Cover deleted successfully", book)
+}
+
+// GetCoverImage menyajikan gambar cover dari storage (publik, tanpa login) dengan header
+// cache jangka panjang, dipakai oleh URL pada field covers buku
+func (h *CoverHandler) GetCoverImage(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Cover not found")
+	}
+	version := c.Params("version")
+	if _, err := hex.DecodeString(version); err != nil || len(version) != 16 {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Cover not found")
+	}
+	sizeName := strings.TrimSuffix(c.Params("file"), ".jpg")
+	if !validCoverSize(sizeName) || c.Params("file") != sizeName+".jpg" {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Cover not found")
+	}
+
+	etag := fmt.Sprintf(`"%s-%s"`, version, sizeName)
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		c.Set(fiber.HeaderETag, etag)
+		c.Set(fiber.HeaderCacheControl, coverCacheControl)
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	object, err := h.Storage.Get(c.Context(), models.CoverKey(bookID, version, sizeName))
+	if errors.Is(err, storage.ErrNotFound) {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Cover not found")
+	}
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderCacheControl, coverCacheControl)
+	c.Set(fiber.HeaderETag, etag)
+	// Cover boleh ditampilkan dari origin lain, misalnya frontend web atau aplikasi e-reader
+	c.Set("Cross-Origin-Resource-Policy", "cross-origin")
+	return c.SendStream(object.Body, int(object.Size))
+}
+
+// coverBookError mengirimkan respons untuk kegagalan mengambil buku yang cover-nya diubah
+func coverBookError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return helpers.ErrorResponseWithCode(c, fiber.StatusNotFound, helpers.ErrCodeBookNotFound, "Book not found")
+	}
+	return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+}
+
+// validCoverSize memeriksa apakah nama ukuran termasuk models.CoverSizes
+func validCoverSize(name string) bool {
+	for _, size := range models.CoverSizes {
+		if size.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteCoverFiles menghapus semua ukuran cover satu versi. Kegagalan hanya dicatat karena
+// file yang tertinggal tidak lagi dirujuk buku mana pun.
+func (h *CoverHandler) deleteCoverFiles(c *fiber.Ctx, bookID uuid.UUID, version string) {
+	for _, size := range models.CoverSizes {
+		if err := h.Storage.Delete(c.Context(), models.CoverKey(bookID, version, size.Name)); err != nil {
+			log.Printf("Failed to delete cover %s of book %s: %v", version, bookID, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"library/models"
+	"library/repositories"
+	"library/storage"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testCoverVersion adalah versi cover yang tersimpan di storage uji
+const testCoverVersion = "0123456789abcdef"
+
+func TestGetCoverImage(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "small cover"
+	key := models.CoverKey(testBookID, testCoverVersion, "small")
+	if err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewCoverHandler(repositories.NewMemoryBookRepository(), store)
+	app := fiber.New()
+	app.Get("/covers/:id/:version/:file", h.GetCoverImage)
+
+	base := "/covers/" + testBookID.String() + "/"
+	tests := []struct {
+		name        string
+		path        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{name: "stored cover", path: base + testCoverVersion + "/small.jpg", wantStatus: fiber.StatusOK},
+		{name: "matching etag", path: base + testCoverVersion + "/small.jpg", ifNoneMatch: `"` + testCoverVersion + `-small"`, wantStatus: fiber.StatusNotModified},
+		{name: "size without a stored file", path: base + testCoverVersion + "/large.jpg", wantStatus: fiber.StatusNotFound},
+		{name: "malformed book id", path: "/covers/abc/" + testCoverVersion + "/small.jpg", wantStatus: fiber.StatusNotFound},
+		{name: "version not hex", path: base + "zzzzzzzzzzzzzzzz/small.jpg", wantStatus: fiber.StatusNotFound},
+		{name: "version too short", path: base + "0123/small.jpg", wantStatus: fiber.StatusNotFound},
+		{name: "unknown size", path: base + testCoverVersion + "/huge.jpg", wantStatus: fiber.StatusNotFound},
+		{name: "wrong extension", path: base + testCoverVersion + "/small.png", wantStatus: fiber.StatusNotFound},
+		{name: "path traversal in file", path: base + testCoverVersion + "/..%2Fsmall.jpg", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set(fiber.HeaderIfNoneMatch, tc.ifNoneMatch)
+			}
+			res, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tc.wantStatus)
+			}
+			if tc.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != content {
+				t.Errorf("body = %q, want %q", body, content)
+			}
+			if got := res.Header.Get(fiber.HeaderContentType); got != "image/jpeg" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := res.Header.Get(fiber.HeaderCacheControl); got != coverCacheControl {
+				t.Errorf("Cache-Control = %q", got)
+			}
+			if got := res.Header.Get(fiber.HeaderETag); got != `"`+testCoverVersion+`-small"` {
+				t.Errorf("ETag = %q", got)
+			}
+		})
+	}
+}
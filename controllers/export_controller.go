@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"library/helpers"
+	"library/middleware"
+	"library/models"
+	"library/repositories"
+	"library/services"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// exportFlushRows adalah jumlah baris yang ditulis sebelum data dikirim ke klien,
+// sehingga export besar mulai terunduh tanpa menunggu query selesai dibaca
+const exportFlushRows = 500
+
+// exportScanner membaca satu baris hasil query menjadi nilai kolom export
+type exportScanner func(rows *services.ExportRows) ([]interface{}, error)
+
+// exportBody menulis semua baris hasil query ke body respons
+type exportBody func(w *bufio.Writer, rows *services.ExportRows) error
+
+// ExportHandler menangani unduhan export. Hasil query dibaca baris demi baris lewat cursor
+// database dari ExportService.
+type ExportHandler struct {
+	Exports *services.ExportService
+}
+
+// NewExportHandler membuat ExportHandler
+func NewExportHandler(exports *services.ExportService) *ExportHandler {
+	return &ExportHandler{Exports: exports}
+}
+
+// exportSpec menambahkan ?format= ke parameter yang diterima spec list
+func exportSpec(spec helpers.ListSpec) helpers.ListSpec {
+	spec.Params = append(append([]string{}, spec.Params...), "format")
+	return spec
+}
+
+// ExportBooks mengunduh katalog buku sebagai CSV, NDJSON, XLSX atau MARCXML (?format=).
+// Filter dan ?sort= sama dengan GetAllBooks.
+func (h *ExportHandler) ExportBooks(c *fiber.Ctx) error {
+	listQuery, err := helpers.ParseListQuery(c, exportSpec(bookListSpec))
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	if c.Query("format") == helpers.ExportMARCXML {
+		rows, err := h.Exports.Books(c.UserContext(), listQuery)
+		if err != nil {
+			return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+		}
+		return streamExport(c, helpers.ExportMARCXML, "books", rows, marcXMLExport)
+	}
+
+	format, err := helpers.ParseExportFormat(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	rows, err := h.Exports.Books(c.UserContext(), listQuery)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	headers := []string{"id", "title", "author", "isbn", "category", "quantity", "replacement_cost", "created_at", "updated_at"}
+	return streamExport(c, format, "books", rows, tableExport(format, "books", headers, func(rows *services.ExportRows) ([]interface{}, error) {
+		var book models.Book
+		if err := rows.Scan(&book); err != nil {
+			return nil, err
+		}
+		return []interface{}{
+			book.ID.String(), book.Title, book.Author, book.Isbn, book.Category,
+			book.Quantity, book.ReplacementCost, book.CreatedAt, book.UpdatedAt,
+		}, nil
+	}))
+}
+
+// ExportUsers mengunduh daftar pengguna beserta kode tier-nya sebagai CSV, NDJSON atau XLSX.
+// Filter dan ?sort= sama dengan GetAllUsers; hash password tidak pernah ikut diekspor.
+func (h *ExportHandler) ExportUsers(c *fiber.Ctx) error {
+	format, err := helpers.ParseExportFormat(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	listQuery, err := helpers.ParseListQuery(c, exportSpec(userListSpec))
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+
+	rows, err := h.Exports.Users(c.UserContext(), listQuery)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	headers := []string{"id", "name", "email", "role", "tier", "created_at", "updated_at"}
+	return streamExport(c, format, "users", rows, tableExport(format, "users", headers, func(rows *services.ExportRows) ([]interface{}, error) {
+		var user services.UserExportRow
+		if err := rows.Scan(&user); err != nil {
+			return nil, err
+		}
+		return []interface{}{
+			user.ID.String(), user.Name, user.Email, user.Role, user.Tier, user.CreatedAt, user.UpdatedAt,
+		}, nil
+	}))
+}
+
+// ExportRecords mengunduh riwayat peminjaman sebagai CSV, NDJSON atau XLSX.
+// Filter (termasuk ?status= dan ?user_id= untuk staff) dan ?sort= sama dengan GetAllRecord,
+// dan anggota hanya mengekspor peminjamannya sendiri.
+func (h *ExportHandler) ExportRecords(c *fiber.Ctx) error {
+	format, err := helpers.ParseExportFormat(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	listQuery, err := helpers.ParseListQuery(c, exportSpec(recordListSpec))
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	owner, err := recordOwnerFor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+	filter := repositories.RecordFilter{UserID: owner, Now: time.Now()}
+	if userID := c.Query("user_id"); userID != "" && middleware.IsStaff(c) {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
+		}
+		filter.UserID = &id
+	}
+	if status := c.Query("status"); status != "" {
+		if !models.IsValidRecordStatus(status) {
+			return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid status filter")
+		}
+		filter.Status = status
+	}
+
+	rows, err := h.Exports.Records(c.UserContext(), filter, listQuery)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	headers := []string{
+		"id", "book_id", "book_title", "book_isbn", "copy_barcode", "user_id", "user_name", "user_email",
+		"borrow_date", "due_date", "return_date", "status", "renewal_count",
+	}
+	return streamExport(c, format, "lending-records", rows, tableExport(format, "lending-records", headers, func(rows *services.ExportRows) ([]interface{}, error) {
+		var record services.RecordExportRow
+		if err := rows.Scan(&record); err != nil {
+			return nil, err
+		}
+		// Scan tidak menjalankan hook AfterFind, jadi status overdue disesuaikan di sini
+		record.SyncOverdueStatus(filter.Now)
+		return []interface{}{
+			record.ID.String(), record.Book_id.String(), record.BookTitle, record.BookIsbn, record.CopyBarcode,
+			record.User_id.String(), record.UserName, record.UserEmail,
+			record.Borrow_date, record.DueDate, record.ReturnDate, record.Status, record.RenewalCount,
+		}, nil
+	}))
+}
+
+// streamExport mengirim baris hasil query sebagai lampiran bernama <name>-<waktu>.<format>.
+// Query sudah dijalankan sebelum respons dikirim supaya kesalahan query masih bisa dibalas 500;
+// kesalahan saat streaming hanya bisa dicatat di log karena status sudah terkirim.
+func streamExport(c *fiber.Ctx, format string, name string, rows *services.ExportRows, body exportBody) error {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), exportExtension(format))
+	c.Set(fiber.HeaderContentType, helpers.ExportContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer rows.Close()
+		if err := body(w, rows); err != nil {
+			log.Printf("Export %s interrupted: %v", filename, err)
+		}
+	})
+	return nil
+}
+
+// exportExtension mengembalikan ekstensi file untuk format export
+func exportExtension(format string) string {
+	if format == helpers.ExportMARCXML {
+		return "xml"
+	}
+	return format
+}
+
+// tableExport menulis baris query sebagai tabel CSV, NDJSON atau XLSX
+func tableExport(format string, sheet string, headers []string, scan exportScanner) exportBody {
+	return func(w *bufio.Writer, rows *services.ExportRows) error {
+		writer, err := helpers.NewExportWriter(format, w, headers, sheet)
+		if err != nil {
+			return err
+		}
+		written := 0
+		for rows.Next() {
+			values, err := scan(rows)
+			if err != nil {
+				return err
+			}
+			if err := writer.WriteRow(values); err != nil {
+				return err
+			}
+			written++
+			if written%exportFlushRows == 0 {
+				if err := writer.Flush(); err != nil {
+					return err
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+}
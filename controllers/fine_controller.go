@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"errors"
+	"library/helpers"
+	"library/models"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// FineTransactionRequest adalah body permintaan pembayaran, pembebasan atau penyesuaian denda
+type FineTransactionRequest struct {
+	Amount   int64  `json:"amount"`
+	Note     string `json:"note"`
+	RecordID string `json:"record_id"`
+}
+
+// GetMyFines menampilkan saldo dan riwayat denda pengguna yang sedang login
+func (h *CirculationHandler) GetMyFines(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+	return h.respondFineSummary(c, userID, fiber.StatusOK)
+}
+
+// GetUserFines menampilkan saldo dan riwayat denda anggota tertentu (staff)
+func (h *CirculationHandler) GetUserFines(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
+	}
+	return h.respondFineSummary(c, userID, fiber.StatusOK)
+}
+
+// PayFine mencatat pembayaran denda anggota
+func (h *CirculationHandler) PayFine(c *fiber.Ctx) error {
+	return h.recordFineSettlement(c, models.FineTypePayment)
+}
+
+// WaiveFine membebaskan sebagian atau seluruh denda anggota
+func (h *CirculationHandler) WaiveFine(c *fiber.Ctx) error {
+	return h.recordFineSettlement(c, models.FineTypeWaiver)
+}
+
+// AdjustFine mencatat koreksi manual saldo denda. Amount positif menambah tagihan,
+// negatif mengurangi tagihan tanpa melewati saldo, dan catatan wajib diisi sebagai alasan koreksi.
+func (h *CirculationHandler) AdjustFine(c *fiber.Ctx) error {
+	entry, err := parseFineEntry(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.Circulation.AdjustFine(c.UserContext(), *entry); err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return h.respondFineSummary(c, entry.UserID, fiber.StatusCreated)
+}
+
+// MarkRecordLost menandai buku yang dipinjam sebagai hilang, lalu menagihkan biaya penggantian
+// dan denda keterlambatan yang sudah berjalan
+func (h *CirculationHandler) MarkRecordLost(c *fiber.Ctx) error {
+	recordID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Records ID format")
+	}
+	staffID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	record, charged, err := h.Circulation.MarkLost(c.UserContext(), recordID, staffID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Record marked as lost", fiber.Map{
+		"record":         record,
+		"charged_amount": charged,
+	})
+}
+
+// recordFineSettlement mencatat transaksi yang mengurangi saldo (pembayaran atau pembebasan)
+func (h *CirculationHandler) recordFineSettlement(c *fiber.Ctx, fineType string) error {
+	entry, err := parseFineEntry(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.Circulation.SettleFine(c.UserContext(), fineType, *entry); err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return h.respondFineSummary(c, entry.UserID, fiber.StatusCreated)
+}
+
+// parseFineEntry membaca ID anggota dari URL dan body permintaan transaksi denda,
+// dicatat atas nama staff yang sedang login
+func parseFineEntry(c *fiber.Ctx) (*services.FineEntry, error) {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, errors.New("Invalid user ID format")
+	}
+	req := new(FineTransactionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	entry := &services.FineEntry{
+		UserID: userID,
+		Amount: req.Amount,
+		Note:   req.Note,
+	}
+	if staffID, err := currentUserID(c); err == nil {
+		entry.CreatedBy = &staffID
+	}
+	if req.RecordID != "" {
+		recordID, err := uuid.Parse(req.RecordID)
+		if err != nil {
+			return nil, errors.New("Invalid record ID format")
+		}
+		entry.RecordID = &recordID
+	}
+	return entry, nil
+}
+
+// respondFineSummary mengirimkan saldo dan riwayat denda anggota
+func (h *CirculationHandler) respondFineSummary(c *fiber.Ctx, userID uuid.UUID, status int) error {
+	summary, err := h.Circulation.FineSummary(c.UserContext(), userID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+	return helpers.SuccessResponse(c, status, "Fines retrieved successfully", summary)
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// testResponse adalah helpers.APIResponse dengan data yang belum di-decode
+type testResponse struct {
+	Code      int             `json:"code"`
+	Success   bool            `json:"success"`
+	Message   string          `json:"message"`
+	ErrorCode string          `json:"error_code"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// handlerCase adalah satu kasus uji handler: request yang dikirim dan respons yang diharapkan
+type handlerCase struct {
+	name       string
+	role       string
+	method     string
+	path       string
+	body       string
+	wantStatus int
+	wantCode   string
+	check      func(t *testing.T, resp testResponse)
+}
+
+// testUserID adalah pengguna yang sedang login pada setiap kasus uji
+var testUserID = uuid.MustParse("6f1c1a52-7d8e-4f0e-9a51-3c2b9d4e8a01")
+
+// runHandlerCase mengirim request kasus uji ke app yang disusun routes, dengan userID dan role
+// di Locals seperti yang dilakukan middleware.AuthRequired
+func runHandlerCase(t *testing.T, tc handlerCase, routes func(app *fiber.App)) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", testUserID.String())
+		c.Locals("role", tc.role)
+		return c.Next()
+	})
+	routes(app)
+
+	req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+	if tc.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	var resp testResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("decode body %q: %v", raw, err)
+	}
+
+	if res.StatusCode != tc.wantStatus {
+		t.Fatalf("status = %d, want %d (message %q)", res.StatusCode, tc.wantStatus, resp.Message)
+	}
+	if resp.ErrorCode != tc.wantCode {
+		t.Fatalf("error_code = %q, want %q", resp.ErrorCode, tc.wantCode)
+	}
+	if tc.check != nil {
+		tc.check(t, resp)
+	}
+}
+
+// decodeData membaca data respons ke dest
+func decodeData(t *testing.T, resp testResponse, dest interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(resp.Data, dest); err != nil {
+		t.Fatalf("decode data %s: %v", resp.Data, err)
+	}
+}
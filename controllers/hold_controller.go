@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"library/helpers"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// PlaceHold menempatkan pengguna yang sedang login ke antrean reservasi sebuah buku.
+// Hold hanya bisa dibuat jika semua eksemplar sedang dipinjam atau disisihkan.
+func (h *CirculationHandler) PlaceHold(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	hold, err := h.Circulation.PlaceHold(c.UserContext(), bookID, userID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Hold placed successfully", hold)
+}
+
+// GetMyHolds menampilkan hold aktif milik pengguna yang sedang login beserta posisinya
+func (h *CirculationHandler) GetMyHolds(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	holds, err := h.Circulation.ActiveHolds(c.UserContext(), userID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Holds retrieved successfully", holds)
+}
+
+// GetBookHolds menampilkan antrean hold aktif sebuah buku (staff)
+func (h *CirculationHandler) GetBookHolds(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+
+	holds, err := h.Circulation.BookHolds(c.UserContext(), bookID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Holds retrieved successfully", holds)
+}
+
+// CancelHold membatalkan hold. Anggota hanya dapat membatalkan hold miliknya sendiri.
+// Jika hold yang dibatalkan sudah ready, eksemplarnya diteruskan ke antrean berikutnya.
+func (h *CirculationHandler) CancelHold(c *fiber.Ctx) error {
+	holdID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid hold ID format")
+	}
+	actor, err := currentActor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	hold, err := h.Circulation.CancelHold(c.UserContext(), holdID, actor)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Hold cancelled successfully", hold)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportHandler menangani impor katalog buku dari file
+type ImportHandler struct {
+	Imports *services.ImportService
+}
+
+// NewImportHandler membuat ImportHandler
+func NewImportHandler(imports *services.ImportService) *ImportHandler {
+	return &ImportHandler{Imports: imports}
+}
+
+// ImportBooksFile mengimpor katalog buku dari file CSV, XLSX, MARC21 atau MARCXML (staff).
+// Query ?dry_run=true hanya memvalidasi, ?chunk_size= mengatur ukuran transaksi dan
+// ?start_row= melanjutkan impor yang sebelumnya terhenti.
+func (h *ImportHandler) ImportBooksFile(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "A CSV, XLSX, MARC21 or MARCXML file is required in the 'file' field")
+	}
+
+	opts := services.BookImportOptions{
+		DryRun:    c.QueryBool("dry_run", false),
+		ChunkSize: c.QueryInt("chunk_size", services.DefaultImportChunkSize),
+		StartRow:  c.QueryInt("start_row", 0),
+	}
+	if opts.ChunkSize < 1 {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "chunk_size must be at least 1")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Could not read uploaded file")
+	}
+	defer file.Close()
+
+	rows, err := services.ParseBookImportFile(file, fileHeader.Filename)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	report := h.Imports.ImportBooks(c.UserContext(), rows, opts)
+	switch {
+	case report.Invalid > 0 && !opts.DryRun:
+		return helpers.ErrorResponseWithData(c, fiber.StatusUnprocessableEntity, helpers.ErrCodeImportInvalidRows,
+			"Some rows are invalid, nothing was imported", report)
+	case report.Error != "":
+		return helpers.ErrorResponseWithData(c, fiber.StatusInternalServerError, helpers.ErrCodeImportInterrupted,
+			"Import stopped before finishing, resume from resume_from_row", report)
+	case opts.DryRun:
+		return helpers.SuccessResponse(c, fiber.StatusOK, "Dry run completed, nothing was saved", report)
+	default:
+		return helpers.SuccessResponse(c, fiber.StatusOK, "Books imported successfully", report)
+	}
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"library/middleware"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentActor mengembalikan pengguna yang sedang login beserta role-nya untuk services
+func currentActor(c *fiber.Ctx) (services.Actor, error) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return services.Actor{}, err
+	}
+	return services.Actor{UserID: userID, Role: middleware.CurrentRole(c)}, nil
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"bufio"
+	"library/helpers"
+	"library/models"
+	"library/services"
+	"strings"
+)
+
+// marcBookLeader adalah leader record buku (bahasa, monograf, UTF-8). Panjang record dan
+// alamat data diisi nol karena tidak dipakai di MARCXML.
+const marcBookLeader = "00000nam a2200000   4500"
+
+// marcRecordForBook membuat record MARC21 bibliografis minimal untuk sebuah buku
+func marcRecordForBook(book *models.Book) *helpers.MARCRecord {
+	record := &helpers.MARCRecord{
+		Leader: marcBookLeader,
+		ControlFields: []helpers.MARCControlField{
+			{Tag: "001", Value: book.ID.String()},
+			{Tag: "005", Value: book.UpdatedAt.UTC().Format("20060102150405.0")},
+		},
+	}
+	if book.Isbn != "" {
+		record.DataFields = append(record.DataFields, marcDataField("020", " ", " ", "a", book.Isbn))
+	}
+	titleInd1 := "0"
+	if book.Author != "" {
+		// Indikator 1 adalah "1" untuk nama berformat "Nama keluarga, nama depan"
+		nameInd1 := "0"
+		if strings.Contains(book.Author, ",") {
+			nameInd1 = "1"
+		}
+		record.DataFields = append(record.DataFields, marcDataField("100", nameInd1, " ", "a", book.Author))
+		titleInd1 = "1"
+	}
+	record.DataFields = append(record.DataFields, marcDataField("245", titleInd1, "0", "a", book.Title))
+	if book.Category != "" {
+		record.DataFields = append(record.DataFields, marcDataField("650", " ", "4", "a", book.Category))
+	}
+	return record
+}
+
+// marcDataField membuat field data dengan satu subfield
+func marcDataField(tag string, ind1 string, ind2 string, code string, value string) helpers.MARCDataField {
+	return helpers.MARCDataField{
+		Tag:       tag,
+		Ind1:      ind1,
+		Ind2:      ind2,
+		Subfields: []helpers.MARCSubfield{{Code: code, Value: value}},
+	}
+}
+
+// marcXMLExport menulis buku hasil query sebagai koleksi MARCXML
+func marcXMLExport(w *bufio.Writer, rows *services.ExportRows) error {
+	writer, err := helpers.NewMARCXMLWriter(w)
+	if err != nil {
+		return err
+	}
+	written := 0
+	for rows.Next() {
+		var book models.Book
+		if err := rows.Scan(&book); err != nil {
+			return err
+		}
+		if err := writer.WriteRecord(marcRecordForBook(&book)); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushRows == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
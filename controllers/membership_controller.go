@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// MembershipHandler menangani endpoint tier keanggotaan
+type MembershipHandler struct {
+	Memberships *services.MembershipService
+}
+
+// NewMembershipHandler membuat MembershipHandler
+func NewMembershipHandler(memberships *services.MembershipService) *MembershipHandler {
+	return &MembershipHandler{Memberships: memberships}
+}
+
+// MembershipTierRequest adalah body permintaan membuat atau memperbarui tier keanggotaan.
+// Field numerik berupa pointer supaya nilai 0 bisa dibedakan dari field yang tidak dikirim.
+type MembershipTierRequest struct {
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	MaxLoans       *int   `json:"max_loans"`
+	LoanPeriodDays *int   `json:"loan_period_days"`
+	MaxRenewals    *int   `json:"max_renewals"`
+	FineDailyRate  *int64 `json:"fine_daily_rate"`
+}
+
+// AssignTierRequest adalah body permintaan mengubah tier seorang anggota
+type AssignTierRequest struct {
+	TierCode string `json:"tier_code"`
+}
+
+// input mengubah body permintaan menjadi services.TierInput
+func (r *MembershipTierRequest) input() services.TierInput {
+	return services.TierInput{
+		Code:           r.Code,
+		Name:           r.Name,
+		MaxLoans:       r.MaxLoans,
+		LoanPeriodDays: r.LoanPeriodDays,
+		MaxRenewals:    r.MaxRenewals,
+		FineDailyRate:  r.FineDailyRate,
+	}
+}
+
+// GetMembershipTiers menampilkan semua tier keanggotaan beserta aturan peminjamannya
+func (h *MembershipHandler) GetMembershipTiers(c *fiber.Ctx) error {
+	tiers, err := h.Memberships.Tiers(c.UserContext())
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Membership tiers retrieved successfully", tiers)
+}
+
+// CreateMembershipTier membuat tier keanggotaan baru (admin)
+func (h *MembershipHandler) CreateMembershipTier(c *fiber.Ctx) error {
+	req := new(MembershipTierRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	tier, err := h.Memberships.CreateTier(c.UserContext(), req.input())
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Membership tier created successfully", tier)
+}
+
+// UpdateMembershipTier memperbarui aturan peminjaman sebuah tier (admin).
+// Perubahan berlaku untuk peminjaman dan perpanjangan berikutnya, bukan yang sudah berjalan.
+func (h *MembershipHandler) UpdateMembershipTier(c *fiber.Ctx) error {
+	tierID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid tier ID format")
+	}
+
+	req := new(MembershipTierRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	tier, err := h.Memberships.UpdateTier(c.UserContext(), tierID, req.input())
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Membership tier updated successfully", tier)
+}
+
+// AssignUserTier mengubah tier keanggotaan seorang pengguna (staff)
+func (h *MembershipHandler) AssignUserTier(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
+	}
+
+	req := new(AssignTierRequest)
+	if err := c.BodyParser(req); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "tier_code is required")
+	}
+
+	user, err := h.Memberships.AssignTier(c.UserContext(), userID, req.TierCode)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Membership tier assigned successfully", user)
+}
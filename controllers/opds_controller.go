@@ -0,0 +1,384 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"library/helpers"
+	"library/models"
+	"library/repositories"
+	"library/services"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// opdsBasePath adalah prefix rute feed OPDS, dipakai untuk membuat link absolut
+const opdsBasePath = "/api/v1/opds"
+
+// opdsPageSize adalah jumlah buku per halaman acquisition feed
+const opdsPageSize = 25
+
+// opdsCatalogTitle adalah judul katalog yang ditampilkan aplikasi e-reader
+const opdsCatalogTitle = "Library Catalogue"
+
+// Tipe media OPDS 1.2 dan OpenSearch
+const (
+	opdsNavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	opdsAcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	opdsEntryType       = "application/atom+xml;type=entry;profile=opds-catalog"
+	openSearchType      = "application/opensearchdescription+xml"
+)
+
+// Namespace XML yang dipakai feed
+const (
+	atomNamespace       = "http://www.w3.org/2005/Atom"
+	dcNamespace         = "http://purl.org/dc/terms/"
+	opdsNamespace       = "http://opds-spec.org/2010/catalog"
+	openSearchNamespace = "http://a9.com/-/spec/opensearch/1.1/"
+)
+
+// opdsFeed adalah Atom feed OPDS, baik navigation feed maupun acquisition feed
+type opdsFeed struct {
+	XMLName         xml.Name    `xml:"feed"`
+	Xmlns           string      `xml:"xmlns,attr"`
+	XmlnsDC         string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS       string      `xml:"xmlns:opds,attr"`
+	XmlnsOpenSearch string      `xml:"xmlns:opensearch,attr"`
+	ID              string      `xml:"id"`
+	Title           string      `xml:"title"`
+	Updated         string      `xml:"updated"`
+	Author          opdsAuthor  `xml:"author"`
+	TotalResults    *int64      `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage    int         `xml:"opensearch:itemsPerPage,omitempty"`
+	Links           []opdsLink  `xml:"link"`
+	Entries         []opdsEntry `xml:"entry"`
+}
+
+// opdsEntry adalah satu entri feed: buku pada acquisition feed atau tautan pada navigation feed.
+// Atribut namespace hanya diisi jika entri dikirim sebagai dokumen tersendiri.
+type opdsEntry struct {
+	XMLName    xml.Name       `xml:"entry"`
+	Xmlns      string         `xml:"xmlns,attr,omitempty"`
+	XmlnsDC    string         `xml:"xmlns:dc,attr,omitempty"`
+	XmlnsOPDS  string         `xml:"xmlns:opds,attr,omitempty"`
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Authors    []opdsAuthor   `xml:"author"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Categories []opdsCategory `xml:"category"`
+	Content    *opdsContent   `xml:"content"`
+	Links      []opdsLink     `xml:"link"`
+}
+
+type opdsAuthor struct {
+	Name string `xml:"name"`
+}
+
+type opdsCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type opdsContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type opdsLink struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+// openSearchDescription adalah dokumen OpenSearch yang memberi tahu aplikasi cara mencari katalog
+type openSearchDescription struct {
+	XMLName        xml.Name      `xml:"OpenSearchDescription"`
+	Xmlns          string        `xml:"xmlns,attr"`
+	ShortName      string        `xml:"ShortName"`
+	Description    string        `xml:"Description"`
+	InputEncoding  string        `xml:"InputEncoding"`
+	OutputEncoding string        `xml:"OutputEncoding"`
+	URL            openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// OPDSHandler menangani katalog OPDS. Feed dibaca lewat SearchService, sama dengan
+// pencarian, sedangkan entri satu buku dibaca lewat BookRepository.
+type OPDSHandler struct {
+	Search *services.SearchService
+	Books  repositories.BookRepository
+}
+
+// NewOPDSHandler membuat OPDSHandler
+func NewOPDSHandler(search *services.SearchService, books repositories.BookRepository) *OPDSHandler {
+	return &OPDSHandler{Search: search, Books: books}
+}
+
+// OPDSRoot adalah navigation feed awal katalog OPDS: buku baru, kategori dan semua buku
+func (h *OPDSHandler) OPDSRoot(c *fiber.Ctx) error {
+	feed := newOPDSFeed(c, "urn:library:opds:root", opdsCatalogTitle, "/", opdsNavigationType)
+	now := opdsTime(time.Now())
+	feed.Entries = []opdsEntry{
+		opdsNavigationEntry(c, "urn:library:opds:new", "New arrivals", "Recently added books", now,
+			"/new", "http://opds-spec.org/sort/new", opdsAcquisitionType),
+		opdsNavigationEntry(c, "urn:library:opds:categories", "Categories", "Browse books by category", now,
+			"/categories", "subsection", opdsNavigationType),
+		opdsNavigationEntry(c, "urn:library:opds:books", "All books", "The whole catalogue by title", now,
+			"/books", "subsection", opdsAcquisitionType),
+	}
+	return opdsResponse(c, opdsNavigationType, feed)
+}
+
+// OPDSNewArrivals adalah acquisition feed buku terbaru
+func (h *OPDSHandler) OPDSNewArrivals(c *fiber.Ctx) error {
+	return h.acquisitionFeed(c, "urn:library:opds:new", "New arrivals", "/new", nil,
+		services.BookFeed{Newest: true})
+}
+
+// OPDSAllBooks adalah acquisition feed seluruh katalog, urut judul
+func (h *OPDSHandler) OPDSAllBooks(c *fiber.Ctx) error {
+	return h.acquisitionFeed(c, "urn:library:opds:books", "All books", "/books", nil,
+		services.BookFeed{})
+}
+
+// OPDSCategories adalah navigation feed berisi satu entri per kategori beserta jumlah bukunya,
+// termasuk buku di subkategorinya
+func (h *OPDSHandler) OPDSCategories(c *fiber.Ctx) error {
+	categories, err := h.Search.CategoryCounts(c.UserContext())
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	feed := newOPDSFeed(c, "urn:library:opds:categories", "Categories", "/categories", opdsNavigationType)
+	feed.Links = append(feed.Links, opdsLink{Rel: "up", Href: opdsURL(c, "/", nil), Type: opdsNavigationType})
+	now := opdsTime(time.Now())
+	for _, category := range categories {
+		feed.Entries = append(feed.Entries, opdsNavigationEntry(c,
+			"urn:library:opds:category:"+url.PathEscape(category.Value), category.Value,
+			fmt.Sprintf("%d books", category.Count), now,
+			"/categories/"+url.PathEscape(category.Value), "subsection", opdsAcquisitionType))
+	}
+	return opdsResponse(c, opdsNavigationType, feed)
+}
+
+// OPDSCategoryBooks adalah acquisition feed buku dalam satu kategori dan subkategorinya
+func (h *OPDSHandler) OPDSCategoryBooks(c *fiber.Ctx) error {
+	category, err := url.PathUnescape(c.Params("category"))
+	if err != nil || strings.TrimSpace(category) == "" {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid category")
+	}
+	up := &opdsLink{Rel: "up", Href: opdsURL(c, "/categories", nil), Type: opdsNavigationType}
+	return h.acquisitionFeed(c, "urn:library:opds:category:"+url.PathEscape(category), category,
+		"/categories/"+url.PathEscape(category), up, services.BookFeed{Category: category})
+}
+
+// OPDSSearch adalah acquisition feed hasil pencarian ?q=, memakai pencarian yang sama
+// dengan SearchBooks dan diurutkan berdasarkan relevansi
+func (h *OPDSHandler) OPDSSearch(c *fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Query parameter q is required")
+	}
+	return h.acquisitionFeed(c, "urn:library:opds:search:"+url.QueryEscape(q), "Search: "+q, "/search", nil,
+		services.BookFeed{Q: q})
+}
+
+// OPDSBook mengembalikan satu buku sebagai dokumen entri OPDS
+func (h *OPDSHandler) OPDSBook(c *fiber.Ctx) error {
+	bookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
+	book, err := h.Books.FindByID(c.UserContext(), bookID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Books not found")
+	}
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	available, err := h.Books.AvailableCopies(c.UserContext(), book.ID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	entry := opdsBookEntry(c, book, available)
+	entry.Xmlns = atomNamespace
+	entry.XmlnsDC = dcNamespace
+	entry.XmlnsOPDS = opdsNamespace
+	return opdsResponse(c, opdsEntryType, entry)
+}
+
+// OPDSOpenSearch mengembalikan OpenSearch description untuk pencarian katalog dari aplikasi
+func (h *OPDSHandler) OPDSOpenSearch(c *fiber.Ctx) error {
+	return opdsResponse(c, openSearchType, openSearchDescription{
+		Xmlns:          openSearchNamespace,
+		ShortName:      opdsCatalogTitle,
+		Description:    "Search the library catalogue by title, author, category or ISBN",
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+		URL: openSearchURL{
+			Type:     opdsAcquisitionType,
+			Template: opdsURL(c, "/search", nil) + "?q={searchTerms}",
+		},
+	})
+}
+
+// acquisitionFeed menulis acquisition feed berhalaman (?page=) berisi buku yang dipilih
+// selection, lengkap dengan link first/previous/next/last
+func (h *OPDSHandler) acquisitionFeed(c *fiber.Ctx, id string, title string, path string, up *opdsLink, selection services.BookFeed) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page number")
+	}
+
+	selection.Limit = opdsPageSize
+	selection.Offset = (page - 1) * opdsPageSize
+	result, err := h.Search.Feed(c.UserContext(), selection)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	total := result.Total
+
+	// Parameter lain (misalnya ?q= pada pencarian) dipertahankan pada link halaman
+	params := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if string(key) != "page" {
+			params.Add(string(key), string(value))
+		}
+	})
+	pageURL := func(number int) string {
+		values := url.Values{}
+		for key, list := range params {
+			values[key] = list
+		}
+		if number > 1 {
+			values.Set("page", strconv.Itoa(number))
+		}
+		return opdsURL(c, path, values)
+	}
+
+	feed := newOPDSFeed(c, id, title, path, opdsAcquisitionType)
+	feed.Links[0].Href = pageURL(page)
+	feed.TotalResults = &total
+	feed.ItemsPerPage = opdsPageSize
+	if up != nil {
+		feed.Links = append(feed.Links, *up)
+	}
+	lastPage := int((total + opdsPageSize - 1) / opdsPageSize)
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	feed.Links = append(feed.Links,
+		opdsLink{Rel: "first", Href: pageURL(1), Type: opdsAcquisitionType},
+		opdsLink{Rel: "last", Href: pageURL(lastPage), Type: opdsAcquisitionType})
+	if page > 1 {
+		feed.Links = append(feed.Links, opdsLink{Rel: "previous", Href: pageURL(page - 1), Type: opdsAcquisitionType})
+	}
+	if page < lastPage {
+		feed.Links = append(feed.Links, opdsLink{Rel: "next", Href: pageURL(page + 1), Type: opdsAcquisitionType})
+	}
+
+	for i := range result.Books {
+		feed.Entries = append(feed.Entries, opdsBookEntry(c, &result.Books[i], result.Available[result.Books[i].ID]))
+	}
+	return opdsResponse(c, opdsAcquisitionType, feed)
+}
+
+// opdsBookEntry membuat entri OPDS untuk satu buku. Koleksi perpustakaan berupa buku fisik,
+// sehingga entri tidak membawa link unduhan; ketersediaan eksemplar ditulis di content.
+func opdsBookEntry(c *fiber.Ctx, book *models.Book, available int) opdsEntry {
+	entry := opdsEntry{
+		ID:      "urn:uuid:" + book.ID.String(),
+		Title:   book.Title,
+		Updated: opdsTime(book.UpdatedAt),
+		Content: &opdsContent{
+			Type:  "text",
+			Value: fmt.Sprintf("%d of %d copies available", available, book.Quantity),
+		},
+		Links: []opdsLink{{
+			Rel:  "alternate",
+			Href: opdsURL(c, "/books/"+book.ID.String(), nil),
+			Type: opdsEntryType,
+		}},
+	}
+	if book.Author != "" {
+		entry.Authors = []opdsAuthor{{Name: book.Author}}
+	}
+	if book.Isbn != "" {
+		entry.Identifier = "urn:isbn:" + book.Isbn
+	}
+	if book.Category != "" {
+		entry.Categories = []opdsCategory{{Term: book.Category, Label: book.Category}}
+	}
+	if book.CoverVersion != "" {
+		entry.Links = append(entry.Links,
+			opdsLink{Rel: "http://opds-spec.org/image", Href: c.BaseURL() + book.Covers["large"], Type: "image/jpeg"},
+			opdsLink{Rel: "http://opds-spec.org/image/thumbnail", Href: c.BaseURL() + book.Covers["small"], Type: "image/jpeg"})
+	}
+	return entry
+}
+
+// opdsNavigationEntry membuat entri navigation feed yang menunjuk ke feed lain
+func opdsNavigationEntry(c *fiber.Ctx, id string, title string, content string, updated string, path string, rel string, linkType string) opdsEntry {
+	return opdsEntry{
+		ID:      id,
+		Title:   title,
+		Updated: updated,
+		Content: &opdsContent{Type: "text", Value: content},
+		Links:   []opdsLink{{Rel: rel, Href: opdsURL(c, path, nil), Type: linkType}},
+	}
+}
+
+// newOPDSFeed membuat feed kosong dengan link self, start dan search
+func newOPDSFeed(c *fiber.Ctx, id string, title string, path string, feedType string) *opdsFeed {
+	return &opdsFeed{
+		Xmlns:           atomNamespace,
+		XmlnsDC:         dcNamespace,
+		XmlnsOPDS:       opdsNamespace,
+		XmlnsOpenSearch: openSearchNamespace,
+		ID:              id,
+		Title:           title,
+		Updated:         opdsTime(time.Now()),
+		Author:          opdsAuthor{Name: opdsCatalogTitle},
+		Links: []opdsLink{
+			{Rel: "self", Href: opdsURL(c, path, nil), Type: feedType},
+			{Rel: "start", Href: opdsURL(c, "/", nil), Type: opdsNavigationType},
+			{Rel: "search", Href: opdsURL(c, "/opensearch.xml", nil), Type: openSearchType},
+		},
+	}
+}
+
+// opdsURL membuat URL absolut ke rute OPDS, karena aplikasi e-reader menyimpan link apa adanya
+func opdsURL(c *fiber.Ctx, path string, query url.Values) string {
+	link := c.BaseURL() + opdsBasePath + strings.TrimSuffix(path, "/")
+	if len(query) > 0 {
+		link += "?" + query.Encode()
+	}
+	return link
+}
+
+// opdsTime memformat waktu untuk elemen updated Atom
+func opdsTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// opdsResponse menulis dokumen XML dengan tipe media OPDS
+func opdsResponse(c *fiber.Ctx, contentType string, document interface{}) error {
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	c.Set(fiber.HeaderContentType, contentType+";charset=utf-8")
+	return c.Send(append([]byte(xml.Header), body...))
+}
@@ -1,101 +1,262 @@
 package controllers
 
 import (
-	"library/database" // Sesuaikan dengan nama proyekmu
-	"library/helpers"  // Sesuaikan dengan nama proyekmu
-	"library/models"   // Sesuaikan dengan nama proyekmu
+	"context"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/middleware"   // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"library/services"     // Sesuaikan dengan nama proyekmu
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
-// CreateRecords membuat pengguna baru
-func CreateRecord(c *fiber.Ctx) error {
-	record := new(models.Lending_records)
+// RecordHandler menangani endpoint peminjaman
+type RecordHandler struct {
+	Records     repositories.LendingRepository
+	Circulation Circulation
+}
+
+// NewRecordHandler membuat RecordHandler
+func NewRecordHandler(records repositories.LendingRepository, circulation Circulation) *RecordHandler {
+	return &RecordHandler{Records: records, Circulation: circulation}
+}
+
+// Circulation menjalankan peminjaman dan pengembalian buku, diimplementasikan oleh
+// services.CirculationService. Error yang bisa ditampilkan ke klien dikembalikan sebagai services.Error.
+type Circulation interface {
+	// Checkout meminjamkan buku dan mengembalikan record (dengan Book, User dan Copy) beserta sisa eksemplar
+	Checkout(ctx context.Context, bookID uuid.UUID, userID uuid.UUID, borrowDate time.Time) (*models.Lending_records, int, error)
+	// Return mencatat pengembalian dan mengembalikan record beserta denda keterlambatannya.
+	// Selain staff, actor hanya dapat mengembalikan peminjamannya sendiri.
+	Return(ctx context.Context, recordID uuid.UUID, actor services.Actor) (*models.Lending_records, int64, error)
+}
+
+// BorrowRequest adalah body permintaan peminjaman. borrow_date hanya dipakai untuk staff.
+type BorrowRequest struct {
+	BookID     string    `json:"book_id"`
+	BorrowDate time.Time `json:"borrow_date"`
+}
+
+// BorrowResponse adalah record peminjaman beserta sisa eksemplar buku setelah dipinjam
+type BorrowResponse struct {
+	models.Lending_records
+	AvailableCopies int `json:"available_copies"`
+}
 
-	if err := c.BodyParser(record); err != nil {
+// CreateRecord meminjam buku untuk pengguna yang sedang login lewat Circulation.Checkout.
+// borrow_date di body hanya dipakai untuk staff yang mencatat peminjaman mundur.
+func (h *RecordHandler) CreateRecord(c *fiber.Ctx) error {
+	req := new(BorrowRequest)
+
+	if err := c.BodyParser(req); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
 	// Ambil user_id dari JWT token (middleware simpan di Locals)
-	userIDStr, ok := c.Locals("userID").(string)
-	if !ok || userIDStr == "" {
+	userID, err := currentUserID(c)
+	if err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
 	}
 
-	// Set user_id dari token ke struct
-	record.User_id = userIDStr
+	bookID, err := uuid.Parse(req.BookID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid book ID format")
+	}
 
-	// Simpan ke database
-	if result := database.DBClient.Create(&record); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	// Tanggal pinjam menentukan jatuh tempo, jadi anggota selalu meminjam per hari ini.
+	// Hanya staff yang boleh mencatat peminjaman mundur, dan tidak ke masa depan.
+	borrowDate := time.Now()
+	if !req.BorrowDate.IsZero() && middleware.IsStaff(c) {
+		if req.BorrowDate.After(borrowDate) {
+			return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Borrow date cannot be in the future")
+		}
+		borrowDate = req.BorrowDate
 	}
 
-	// Preload relasi Book dan User
-	if err := database.DBClient.Preload("Book").Preload("User").First(&record, "id = ?", record.ID).Error; err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to preload related data: "+err.Error())
+	record, remaining, err := h.Circulation.Checkout(c.UserContext(), bookID, userID, borrowDate)
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
-	return helpers.SuccessResponse(c, fiber.StatusCreated, "Record created successfully", record)
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Record created successfully", BorrowResponse{
+		Lending_records: *record,
+		AvailableCopies: remaining,
+	})
+}
+
+// recordListSpec adalah filter, urutan dan field yang didukung GetAllRecord
+var recordListSpec = helpers.ListSpec{
+	Filters: map[string]helpers.FilterSpec{
+		"book_id":          {Column: "book_id", Operator: helpers.FilterUUID},
+		"borrow_date_from": {Column: "borrow_date", Operator: helpers.FilterDateFrom},
+		"borrow_date_to":   {Column: "borrow_date", Operator: helpers.FilterDateTo},
+		"due_date_from":    {Column: "due_date", Operator: helpers.FilterDateFrom},
+		"due_date_to":      {Column: "due_date", Operator: helpers.FilterDateTo},
+	},
+	Sortable: map[string]string{
+		"borrow_date":   "borrow_date",
+		"due_date":      "due_date",
+		"return_date":   "return_date",
+		"status":        "status",
+		"renewal_count": "renewal_count",
+	},
+	Fields: map[string]string{
+		"id":            "id",
+		"book_id":       "book_id",
+		"copy_id":       "copy_id",
+		"user_id":       "user_id",
+		"borrow_date":   "borrow_date",
+		"due_date":      "due_date",
+		"return_date":   "return_date",
+		"status":        "status",
+		"renewal_count": "renewal_count",
+		"book":          "Book",
+		"user":          "User",
+	},
+	DefaultSort: "-borrow_date",
+	TieBreaker:  "id",
+	Params:      []string{"status", "user_id"},
+	Nullable:    map[string]bool{"return_date": true},
 }
 
-// GetAllUsers mendapatkan semua pengguna
-func GetAllRecord(c *fiber.Ctx) error {
-	var Records []models.Lending_records
-	var total int64
-	page, err := strconv.Atoi(c.Query("page", "1")) // Ambil "page" dari URL, default "1"
+// GetAllRecord mendapatkan semua peminjaman. Anggota hanya melihat peminjamannya sendiri.
+// Selain ?status= dan ?user_id= (staff), mendukung filter book_id, borrow_date_from/to,
+// due_date_from/to, ?sort= dan ?fields= sesuai recordListSpec, serta pagination
+// offset (?page=) atau cursor (?pagination=cursor lalu ?cursor=).
+func (h *RecordHandler) GetAllRecord(c *fiber.Ctx) error {
+	pagination, err := helpers.ParsePagination(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	listQuery, err := helpers.ParseListQuery(c, recordListSpec)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	owner, err := recordOwnerFor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+	filter := repositories.RecordFilter{UserID: owner}
+	// Staff dapat melihat peminjaman anggota tertentu
+	if userID := c.Query("user_id"); userID != "" && middleware.IsStaff(c) {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
+		}
+		filter.UserID = &id
+	}
+	if status := c.Query("status"); status != "" {
+		if !models.IsValidRecordStatus(status) {
+			return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid status filter")
+		}
+		filter.Status = status
+		filter.Now = time.Now()
+	}
+
+	Records, page, err := h.Records.List(c.UserContext(), filter, listQuery, pagination)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	if len(Records) == 0 && pagination.Page > 1 { // Jika halaman lebih dari 1 dan tidak ada peminjaman, berarti halaman kosong
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "No Records found on this page")
+	}
+	if page.Data, err = listQuery.Project(Records); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if len(Records) == 0 { // Jika di halaman pertama pun tidak ada peminjaman sama sekali
+		return helpers.SuccessResponse(c, fiber.StatusOK, "No Records found", page)
+	}
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Records retrieved successfully", page)
+}
+
+// MyLoansResponse berisi peminjaman yang masih berjalan dan riwayat peminjaman anggota
+type MyLoansResponse struct {
+	Current     []models.Lending_records `json:"current"`
+	Past        []models.Lending_records `json:"past"`
+	TotalPast   int64                    `json:"total_past"`
+	CurrentPage int                      `json:"current_page"`
+	PerPage     int                      `json:"per_page"`
+	TotalPages  int64                    `json:"total_pages"`
+}
+
+// GetMyLoans menampilkan peminjaman pengguna yang sedang login: semua peminjaman yang masih
+// berjalan (urut jatuh tempo) dan riwayat peminjaman yang sudah selesai dengan pagination
+func (h *RecordHandler) GetMyLoans(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+	page, err := strconv.Atoi(c.Query("page", "1"))
 	if err != nil || page < 1 {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page number")
 	}
-
-	limit, err := strconv.Atoi(c.Query("limit", "10")) // Ambil "limit" dari URL, default "10"
-	if err != nil || limit < 1 {
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit number")
+	limit, err := helpers.ParseLimit(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
 	}
-	offset := (page - 1) * limit
 
-	if result := database.DBClient.Model(&models.Lending_records{}).Count(&total); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	current, err := h.Records.CurrentLoans(c.UserContext(), userID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
-	if result := database.DBClient.Preload("Book").
-		Preload("User").Limit(limit).Offset(offset).Find(&Records); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	past, totalPast, err := h.Records.PastLoans(c.UserContext(), userID, limit, (page-1)*limit)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
-	if len(Records) == 0 && page > 1 { // Jika halaman lebih dari 1 dan tidak ada buku, berarti halaman kosong
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "No Records found on this page")
-	} else if len(Records) == 0 { // Jika di halaman 1 pun tidak ada buku sama sekali
-		return helpers.SuccessResponse(c, fiber.StatusOK, "No Records found", []models.Lending_records{})
-	}
-	totalPages := (total + int64(limit) - 1) / int64(limit)
-	return helpers.SuccessResponse(c, fiber.StatusOK, "Records retrieved successfully", fiber.Map{
-		"data":         Records,
-		"total_items":  total,
-		"current_page": page,
-		"per_page":     limit,
-		"total_pages":  totalPages,
-	})
+
+	response := MyLoansResponse{
+		Current:     current,
+		Past:        past,
+		TotalPast:   totalPast,
+		CurrentPage: page,
+		PerPage:     limit,
+		TotalPages:  (totalPast + int64(limit) - 1) / int64(limit),
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Loans retrieved successfully", response)
 }
 
-// GetRecordsByID mendapatkan pengguna berdasarkan ID
-func GetRecordByID(c *fiber.Ctx) error {
+// GetRecordByID mendapatkan peminjaman berdasarkan ID. Peminjaman milik anggota lain
+// dilaporkan tidak ditemukan supaya keberadaannya tidak bocor.
+func (h *RecordHandler) GetRecordByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	RecordID, err := uuid.Parse(idStr)
 	if err != nil {
 		// Jika ID dari URL bukan UUID yang valid
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Record ID format")
 	}
-	Records := new(models.Lending_records)
 
-	if result := database.DBClient.First(&Records, RecordID); result.Error != nil {
+	owner, err := recordOwnerFor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+	Records, err := h.Records.FindByID(c.UserContext(), RecordID, owner)
+	if err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Records not found")
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Records retrieved successfully", Records)
 }
 
-// UpdateRecords memperbarui pengguna
-func UpdateRecords(c *fiber.Ctx) error {
+// RecordUpdateRequest adalah body permintaan UpdateRecords. Selain due_date, field lain hanya
+// dibaca untuk menolaknya karena diatur oleh alur sirkulasi.
+type RecordUpdateRequest struct {
+	DueDate    *time.Time `json:"due_date"`
+	BookID     string     `json:"book_id"`
+	UserID     string     `json:"user_id"`
+	BorrowDate *time.Time `json:"borrow_date"`
+	ReturnDate *time.Time `json:"return_date"`
+	Status     string     `json:"status"`
+}
+
+// UpdateRecords mengubah jatuh tempo peminjaman yang masih berjalan (staff). Pengembalian dan
+// kehilangan harus lewat /record/:id/return dan /record/:id/lost supaya eksemplar, denda dan
+// antrean hold ikut diperbarui; buku, peminjam dan tanggal pinjam tidak bisa diganti.
+func (h *RecordHandler) UpdateRecords(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 
 	RecordsID, err := uuid.Parse(idStr)
@@ -104,44 +265,46 @@ func UpdateRecords(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Records ID format")
 	}
 
-	Records := new(models.Lending_records)
-
-	if result := database.DBClient.First(&Records, RecordsID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Records not found")
-	}
-
-	updates := new(models.Lending_records)
+	updates := new(RecordUpdateRequest)
 	if err := c.BodyParser(updates); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
-
-	if updates.Book_id != "" {
-		Records.Book_id = updates.Book_id
-	}
-	if updates.User_id != "" {
-		Records.User_id = updates.User_id
+	if updates.ReturnDate != nil || updates.Status != "" {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest,
+			"return_date and status are set by circulation, use POST /record/:id/return or /record/:id/lost")
 	}
-	if updates.Borrow_date.IsZero() {
-		Records.Borrow_date = updates.Borrow_date
+	if updates.BookID != "" || updates.UserID != "" || updates.BorrowDate != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest,
+			"book_id, user_id and borrow_date cannot be changed, return the loan and create a new one")
 	}
-	if updates.ReturnDate != nil {
-		Records.ReturnDate = updates.ReturnDate
+	if updates.DueDate == nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "due_date is required")
 	}
 
-	if result := database.DBClient.Save(&Records); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	Records, err := h.Records.FindByID(c.UserContext(), RecordsID, nil)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Records not found")
 	}
-	if err := database.DBClient.Model(&Records).Association("Book").Find(&Records.Book); err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to load Book data")
+	if !Records.IsOpen() {
+		return helpers.ErrorResponseWithCode(c, fiber.StatusConflict, helpers.ErrCodeRecordNotOpen, "This loan has already been closed")
 	}
-	if err := database.DBClient.Model(&Records).Association("User").Find(&Records.User); err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to load User data")
+	if !updates.DueDate.After(Records.Borrow_date) {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Due date must be after the borrow date")
 	}
+
+	Records.DueDate = *updates.DueDate
+	// Status overdue hanya hasil turunan dari jatuh tempo, simpan sebagai borrowed
+	Records.Status = models.RecordStatusBorrowed
+
+	if err := h.Records.Save(c.UserContext(), Records); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	Records.SyncOverdueStatus(time.Now())
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Records updated successfully", Records)
 }
 
-// DeleteRecords menghapus pengguna
-func DeleteRecords(c *fiber.Ctx) error {
+// DeleteRecords menghapus peminjaman yang sudah selesai (dikembalikan atau hilang)
+func (h *RecordHandler) DeleteRecords(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	RecordsID, err := uuid.Parse(idStr)
 	if err != nil {
@@ -149,15 +312,67 @@ func DeleteRecords(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Records ID format")
 	}
 
-	Records := new(models.Lending_records)
-
-	if result := database.DBClient.First(&Records, RecordsID); result.Error != nil {
+	owner, err := recordOwnerFor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+	Records, err := h.Records.FindByID(c.UserContext(), RecordsID, owner)
+	if err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Records not found")
 	}
+	// Peminjaman yang masih berjalan memegang eksemplar; menghapusnya membuat eksemplar
+	// tertahan on_loan selamanya
+	if Records.IsOpen() {
+		return helpers.ErrorResponseWithCode(c, fiber.StatusConflict, helpers.ErrCodeRecordOpen,
+			"This loan is still open, return it or mark it lost before deleting")
+	}
 
-	if result := database.DBClient.Delete(&Records); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	if err := h.Records.Delete(c.UserContext(), Records); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "Records deleted successfully", nil)
 }
+
+// ReturnResponse adalah record peminjaman yang dikembalikan beserta denda keterlambatannya
+type ReturnResponse struct {
+	models.Lending_records
+	FineAmount int64 `json:"fine_amount"`
+}
+
+// ReturnRecord mencatat pengembalian buku oleh staff. Anggota tidak dapat menutup
+// peminjamannya sendiri, mereka hanya dapat memperpanjangnya.
+func (h *RecordHandler) ReturnRecord(c *fiber.Ctx) error {
+	recordID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Records ID format")
+	}
+
+	actor, err := currentActor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	record, fine, err := h.Circulation.Return(c.UserContext(), recordID, actor)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Book returned successfully", ReturnResponse{
+		Lending_records: *record,
+		FineAmount:      fine,
+	})
+}
+
+// recordOwnerFor mengembalikan ID pengguna yang sedang login sebagai batas peminjaman yang
+// boleh dilihatnya, atau nil untuk staff yang boleh melihat semua peminjaman
+func recordOwnerFor(c *fiber.Ctx) (*uuid.UUID, error) {
+	if middleware.IsStaff(c) {
+		return nil, nil
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		return nil, err
+	}
+	return &userID, nil
+}
@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"library/helpers"
+	"library/models"
+	"library/repositories"
+	"library/services"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	testOpenRecordID     = uuid.MustParse("70de9163-afb2-43c4-901f-4b5c6d7e8f90")
+	testOverdueRecordID  = uuid.MustParse("81efa274-b0c3-44d5-a120-5c6d7e8f9001")
+	testReturnedRecordID = uuid.MustParse("92f0b385-c1d4-45e6-b231-6d7e8f900112")
+	testLostRecordID     = uuid.MustParse("b412d5a7-e3f6-4708-9453-8f9001122334")
+	testUnavailableBook  = uuid.MustParse("a301c496-d2e5-46f7-8342-7e8f90011223")
+)
+
+// fakeCirculation meminjamkan buku sesuai jumlah eksemplar available di memori dan menutup
+// peminjaman di MemoryLendingRepository, dengan error yang sama seperti services.CirculationService
+type fakeCirculation struct {
+	records   *repositories.MemoryLendingRepository
+	available map[uuid.UUID]int
+}
+
+func (f *fakeCirculation) Checkout(ctx context.Context, bookID uuid.UUID, userID uuid.UUID, borrowDate time.Time) (*models.Lending_records, int, error) {
+	available, ok := f.available[bookID]
+	if !ok {
+		return nil, 0, &services.Error{Kind: services.KindNotFound, Code: helpers.ErrCodeBookNotFound, Message: "Book not found"}
+	}
+	if available == 0 {
+		return nil, 0, &services.Error{Kind: services.KindConflict, Code: helpers.ErrCodeBookUnavailable, Message: "No copies of this book are currently available"}
+	}
+	f.available[bookID] = available - 1
+	return &models.Lending_records{
+		ID:          uuid.New(),
+		Book_id:     bookID,
+		User_id:     userID,
+		Borrow_date: borrowDate,
+		DueDate:     borrowDate.AddDate(0, 0, 14),
+		Status:      models.RecordStatusBorrowed,
+	}, available - 1, nil
+}
+
+func (f *fakeCirculation) Return(ctx context.Context, recordID uuid.UUID, actor services.Actor) (*models.Lending_records, int64, error) {
+	if !actor.IsStaff() {
+		return nil, 0, &services.Error{Kind: services.KindForbidden, Message: "Only staff can record a return"}
+	}
+	record, err := f.records.FindByID(ctx, recordID, nil)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, 0, &services.Error{Kind: services.KindNotFound, Code: helpers.ErrCodeRecordNotFound, Message: "Records not found"}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if !record.IsOpen() {
+		return nil, 0, &services.Error{Kind: services.KindConflict, Code: helpers.ErrCodeRecordNotOpen, Message: "This loan has already been closed"}
+	}
+
+	var fine int64
+	now := time.Now()
+	if record.DueDate.Before(now) {
+		fine = 1000
+	}
+	record.ReturnDate = &now
+	record.Status = models.RecordStatusReturned
+	return record, fine, f.records.Save(ctx, record)
+}
+
+// newTestRecordHandler berisi peminjaman berjalan, riwayat dan peminjaman hilang milik pengguna
+// yang sedang login, serta peminjaman terlambat milik anggota lain
+func newTestRecordHandler() *RecordHandler {
+	now := time.Now()
+	returnedAt := now.AddDate(0, 0, -1)
+	records := repositories.NewMemoryLendingRepository(
+		models.Lending_records{ID: testOpenRecordID, Book_id: testBookID, User_id: testUserID,
+			Borrow_date: now.AddDate(0, 0, -3), DueDate: now.AddDate(0, 0, 11), Status: models.RecordStatusBorrowed},
+		models.Lending_records{ID: testOverdueRecordID, Book_id: testBookID, User_id: testMemberID,
+			Borrow_date: now.AddDate(0, 0, -20), DueDate: now.AddDate(0, 0, -6), Status: models.RecordStatusBorrowed},
+		models.Lending_records{ID: testReturnedRecordID, Book_id: testBookID, User_id: testUserID,
+			Borrow_date: now.AddDate(0, 0, -10), DueDate: now.AddDate(0, 0, 4), ReturnDate: &returnedAt, Status: models.RecordStatusReturned},
+		models.Lending_records{ID: testLostRecordID, Book_id: testBookID, User_id: testUserID,
+			Borrow_date: now.AddDate(0, 0, -30), DueDate: now.AddDate(0, 0, -16), Status: models.RecordStatusLost},
+	)
+	circulation := &fakeCirculation{
+		records:   records,
+		available: map[uuid.UUID]int{testBookID: 2, testUnavailableBook: 0},
+	}
+	return NewRecordHandler(records, circulation)
+}
+
+func recordRoutes(h *RecordHandler) func(app *fiber.App) {
+	return func(app *fiber.App) {
+		app.Post("/record", h.CreateRecord)
+		app.Get("/record", h.GetAllRecord)
+		app.Get("/users/me/loans", h.GetMyLoans)
+		app.Get("/record/:id", h.GetRecordByID)
+		app.Put("/record/:id", h.UpdateRecords)
+		app.Post("/record/:id/return", h.ReturnRecord)
+		app.Delete("/record/:id", h.DeleteRecords)
+	}
+}
+
+// recordPageIDs membaca ID peminjaman dari data respons list
+func recordPageIDs(t *testing.T, resp testResponse) []uuid.UUID {
+	t.Helper()
+	var page struct {
+		Data []models.Lending_records `json:"data"`
+	}
+	decodeData(t, resp, &page)
+	ids := make([]uuid.UUID, 0, len(page.Data))
+	for _, record := range page.Data {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
+// wantRecordIDs membuat pemeriksa respons list yang berisi tepat want, sesuai urutan
+func wantRecordIDs(want ...uuid.UUID) func(t *testing.T, resp testResponse) {
+	return func(t *testing.T, resp testResponse) {
+		got := recordPageIDs(t, resp)
+		if len(got) != len(want) {
+			t.Fatalf("got records %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("got records %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestRecordHandler(t *testing.T) {
+	tests := []handlerCase{
+		{
+			name: "borrow reports remaining copies", role: models.RoleMember,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"` + testBookID.String() + `"}`,
+			wantStatus: fiber.StatusCreated,
+			check: func(t *testing.T, resp testResponse) {
+				var borrowed BorrowResponse
+				decodeData(t, resp, &borrowed)
+				if borrowed.AvailableCopies != 1 || borrowed.User_id != testUserID {
+					t.Errorf("got available_copies %d for user %s", borrowed.AvailableCopies, borrowed.User_id)
+				}
+			},
+		},
+		{
+			name: "member cannot choose the borrow date", role: models.RoleMember,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"` + testBookID.String() + `","borrow_date":"2999-01-01T00:00:00Z"}`,
+			wantStatus: fiber.StatusCreated,
+			check: func(t *testing.T, resp testResponse) {
+				var borrowed BorrowResponse
+				decodeData(t, resp, &borrowed)
+				if time.Since(borrowed.Borrow_date) > time.Minute || borrowed.DueDate.After(time.Now().AddDate(0, 0, 15)) {
+					t.Errorf("borrow_date = %v, due_date = %v, want today's loan", borrowed.Borrow_date, borrowed.DueDate)
+				}
+			},
+		},
+		{
+			name: "staff backdates a loan", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"` + testBookID.String() + `","borrow_date":"2024-05-01T10:00:00Z"}`,
+			wantStatus: fiber.StatusCreated,
+			check: func(t *testing.T, resp testResponse) {
+				var borrowed BorrowResponse
+				decodeData(t, resp, &borrowed)
+				if !borrowed.Borrow_date.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+					t.Errorf("borrow_date = %v, want 2024-05-01", borrowed.Borrow_date)
+				}
+			},
+		},
+		{
+			name: "staff cannot borrow in the future", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"` + testBookID.String() + `","borrow_date":"2999-01-01T00:00:00Z"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "borrow rejects malformed book id", role: models.RoleMember,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"abc"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "borrow unknown book", role: models.RoleMember,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"` + uuid.NewString() + `"}`,
+			wantStatus: fiber.StatusNotFound, wantCode: helpers.ErrCodeBookNotFound,
+		},
+		{
+			name: "borrow book without available copies", role: models.RoleMember,
+			method: http.MethodPost, path: "/record",
+			body:       `{"book_id":"` + testUnavailableBook.String() + `"}`,
+			wantStatus: fiber.StatusConflict, wantCode: helpers.ErrCodeBookUnavailable,
+		},
+		{
+			name: "member lists only own records", role: models.RoleMember,
+			method: http.MethodGet, path: "/record",
+			wantStatus: fiber.StatusOK,
+			check:      wantRecordIDs(testOpenRecordID, testReturnedRecordID, testLostRecordID),
+		},
+		{
+			name: "member cannot list another member's records", role: models.RoleMember,
+			method: http.MethodGet, path: "/record?user_id=" + testMemberID.String(),
+			wantStatus: fiber.StatusOK,
+			check:      wantRecordIDs(testOpenRecordID, testReturnedRecordID, testLostRecordID),
+		},
+		{
+			name: "staff lists one member's records", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record?user_id=" + testMemberID.String(),
+			wantStatus: fiber.StatusOK,
+			check:      wantRecordIDs(testOverdueRecordID),
+		},
+		{
+			name: "staff filters overdue records", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record?status=overdue",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				wantRecordIDs(testOverdueRecordID)(t, resp)
+				var page struct {
+					Data []models.Lending_records `json:"data"`
+				}
+				decodeData(t, resp, &page)
+				if page.Data[0].Status != models.RecordStatusOverdue {
+					t.Errorf("status = %q, want overdue", page.Data[0].Status)
+				}
+			},
+		},
+		{
+			name: "staff filters borrowed records excluding overdue", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record?status=borrowed",
+			wantStatus: fiber.StatusOK,
+			check:      wantRecordIDs(testOpenRecordID),
+		},
+		{
+			name: "list rejects unknown status", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record?status=missing",
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "list rejects malformed user id", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record?user_id=abc",
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "list rejects unknown query parameter", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record?stauts=overdue",
+			wantStatus: fiber.StatusBadRequest, wantCode: helpers.ErrCodeInvalidQuery,
+		},
+		{
+			name: "my loans split current and past without lost loans", role: models.RoleMember,
+			method: http.MethodGet, path: "/users/me/loans",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var loans MyLoansResponse
+				decodeData(t, resp, &loans)
+				if len(loans.Current) != 1 || loans.Current[0].ID != testOpenRecordID {
+					t.Errorf("current = %v, want only the open record", loans.Current)
+				}
+				if len(loans.Past) != 1 || loans.TotalPast != 1 || loans.TotalPages != 1 {
+					t.Errorf("past = %d of %d in %d pages, want 1 of 1 in 1", len(loans.Past), loans.TotalPast, loans.TotalPages)
+				}
+			},
+		},
+		{
+			name: "my loans rejects invalid page", role: models.RoleMember,
+			method: http.MethodGet, path: "/users/me/loans?page=0",
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "member gets own record", role: models.RoleMember,
+			method: http.MethodGet, path: "/record/" + testOpenRecordID.String(),
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "member cannot see another member's record", role: models.RoleMember,
+			method: http.MethodGet, path: "/record/" + testOverdueRecordID.String(),
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "staff gets any record", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/record/" + testOverdueRecordID.String(),
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "update extends the due date of an overdue loan", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/record/" + testOverdueRecordID.String(),
+			body:       `{"due_date":"` + time.Now().AddDate(0, 0, 7).UTC().Format(time.RFC3339) + `"}`,
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var record models.Lending_records
+				decodeData(t, resp, &record)
+				if record.Status != models.RecordStatusBorrowed {
+					t.Errorf("status = %q, want borrowed", record.Status)
+				}
+			},
+		},
+		{
+			name: "update rejects return date", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/record/" + testOpenRecordID.String(),
+			body:       `{"return_date":"2024-05-01T10:00:00Z"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "update rejects status", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/record/" + testOpenRecordID.String(),
+			body:       `{"status":"returned"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "update rejects moving the loan to another book", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/record/" + testOpenRecordID.String(),
+			body:       `{"book_id":"` + testUnavailableBook.String() + `"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "update rejects due date of a returned loan", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/record/" + testReturnedRecordID.String(),
+			body:       `{"due_date":"2999-01-01T00:00:00Z"}`,
+			wantStatus: fiber.StatusConflict, wantCode: helpers.ErrCodeRecordNotOpen,
+		},
+		{
+			name: "update rejects due date before borrow date", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/record/" + testOpenRecordID.String(),
+			body:       `{"due_date":"2000-01-01T00:00:00Z"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "member cannot return own loan", role: models.RoleMember,
+			method: http.MethodPost, path: "/record/" + testOpenRecordID.String() + "/return",
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name: "staff returns loan", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/record/" + testOpenRecordID.String() + "/return",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var returned ReturnResponse
+				decodeData(t, resp, &returned)
+				if returned.Status != models.RecordStatusReturned || returned.FineAmount != 0 {
+					t.Errorf("status = %q, fine = %d", returned.Status, returned.FineAmount)
+				}
+			},
+		},
+		{
+			name: "member cannot return another member's loan", role: models.RoleMember,
+			method: http.MethodPost, path: "/record/" + testOverdueRecordID.String() + "/return",
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name: "staff returns overdue loan with fine", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/record/" + testOverdueRecordID.String() + "/return",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var returned ReturnResponse
+				decodeData(t, resp, &returned)
+				if returned.FineAmount != 1000 {
+					t.Errorf("fine = %d, want 1000", returned.FineAmount)
+				}
+			},
+		},
+		{
+			name: "return closed loan", role: models.RoleLibrarian,
+			method: http.MethodPost, path: "/record/" + testReturnedRecordID.String() + "/return",
+			wantStatus: fiber.StatusConflict, wantCode: helpers.ErrCodeRecordNotOpen,
+		},
+		{
+			name: "member cannot delete another member's record", role: models.RoleMember,
+			method: http.MethodDelete, path: "/record/" + testOverdueRecordID.String(),
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "staff cannot delete an open loan", role: models.RoleLibrarian,
+			method: http.MethodDelete, path: "/record/" + testOverdueRecordID.String(),
+			wantStatus: fiber.StatusConflict, wantCode: helpers.ErrCodeRecordOpen,
+		},
+		{
+			name: "staff deletes returned record", role: models.RoleLibrarian,
+			method: http.MethodDelete, path: "/record/" + testReturnedRecordID.String(),
+			wantStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runHandlerCase(t, tc, recordRoutes(newTestRecordHandler()))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"library/helpers"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RenewRecord memperpanjang jatuh tempo peminjaman sebesar lama peminjaman tier peminjam.
+// Perpanjangan ditolak jika batas perpanjangan tier tercapai, keterlambatan melewati masa
+// toleransi, atau ada anggota lain yang mengantre buku tersebut.
+func (h *CirculationHandler) RenewRecord(c *fiber.Ctx) error {
+	recordID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Records ID format")
+	}
+
+	actor, err := currentActor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	record, remaining, err := h.Circulation.Renew(c.UserContext(), recordID, actor)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Loan renewed successfully", fiber.Map{
+		"record":             record,
+		"renewals_remaining": remaining,
+	})
+}
+
+// GetRecordRenewals menampilkan riwayat perpanjangan sebuah peminjaman.
+// Anggota hanya dapat melihat riwayat peminjamannya sendiri.
+func (h *CirculationHandler) GetRecordRenewals(c *fiber.Ctx) error {
+	recordID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Records ID format")
+	}
+	actor, err := currentActor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	renewals, err := h.Circulation.RenewalHistory(c.UserContext(), recordID, actor)
+	if err != nil {
+		return serviceErrorResponse(c, err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Renewal history retrieved successfully", renewals)
+}
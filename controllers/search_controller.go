@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SearchHandler menangani pencarian katalog
+type SearchHandler struct {
+	Search *services.SearchService
+}
+
+// NewSearchHandler membuat SearchHandler
+func NewSearchHandler(search *services.SearchService) *SearchHandler {
+	return &SearchHandler{Search: search}
+}
+
+// SearchBooks mencari buku berdasarkan judul, penulis, kategori atau ISBN.
+// Hasil diurutkan berdasarkan relevansi full-text ditambah kemiripan trigram, dan dikembalikan
+// bersama jumlah hasil per kategori dan per penulis. Filter ?category= dan ?author=
+// mempersempit hasil; facet sebuah field tidak ikut difilter oleh field itu sendiri
+// supaya pilihan lain tetap terlihat.
+func (h *SearchHandler) SearchBooks(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page number")
+	}
+	limit, err := helpers.ParseLimit(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	offset := (page - 1) * limit
+
+	results, err := h.Search.Search(c.UserContext(), services.BookSearch{
+		Q:        strings.TrimSpace(c.Query("q")),
+		Category: strings.TrimSpace(c.Query("category")),
+		Author:   strings.TrimSpace(c.Query("author")),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	totalPages := (results.Total + int64(limit) - 1) / int64(limit)
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Books retrieved successfully", fiber.Map{
+		"data":         results.Books,
+		"total_items":  results.Total,
+		"current_page": page,
+		"per_page":     limit,
+		"total_pages":  totalPages,
+		"facets": fiber.Map{
+			"category": results.CategoryFacets,
+			"author":   results.AuthorFacets,
+		},
+	})
+}
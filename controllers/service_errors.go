@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"library/helpers"
+	"library/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// serviceErrorStatus memetakan jenis services.Error ke status HTTP
+var serviceErrorStatus = map[services.Kind]int{
+	services.KindInvalid:   fiber.StatusBadRequest,
+	services.KindNotFound:  fiber.StatusNotFound,
+	services.KindConflict:  fiber.StatusConflict,
+	services.KindForbidden: fiber.StatusForbidden,
+}
+
+// serviceErrorResponse mengirimkan respons untuk services.Error sesuai jenisnya.
+// Error lain dianggap kegagalan server.
+func serviceErrorResponse(c *fiber.Ctx, err error) error {
+	if domainErr := services.AsError(err); domainErr != nil {
+		status := serviceErrorStatus[domainErr.Kind]
+		if domainErr.Data != nil {
+			return helpers.ErrorResponseWithData(c, status, domainErr.Code, domainErr.Message, domainErr.Data)
+		}
+		return helpers.ErrorResponseWithCode(c, status, domainErr.Code, domainErr.Message)
+	}
+	return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+}
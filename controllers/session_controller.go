@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"library/helpers"
+	"library/repositories"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SessionResponse adalah representasi satu sesi login yang masih aktif
+type SessionResponse struct {
+	ID               uuid.UUID `json:"id"`
+	UserAgent        string    `json:"user_agent"`
+	IPAddress        string    `json:"ip_address"`
+	SessionStartedAt time.Time `json:"session_started_at"`
+	LastRefreshedAt  time.Time `json:"last_refreshed_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// SessionHandler menangani daftar dan pencabutan sesi login milik pengguna sendiri
+type SessionHandler struct {
+	Tokens repositories.RefreshTokenRepository
+}
+
+// NewSessionHandler membuat SessionHandler
+func NewSessionHandler(tokens repositories.RefreshTokenRepository) *SessionHandler {
+	return &SessionHandler{Tokens: tokens}
+}
+
+// GetMySessions menampilkan semua sesi aktif milik pengguna yang sedang login
+func (h *SessionHandler) GetMySessions(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	// Dalam satu family hanya ada satu token aktif, jadi setiap baris mewakili satu sesi
+	tokens, err := h.Tokens.Active(c.UserContext(), userID, time.Now())
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	sessions := make([]SessionResponse, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, SessionResponse{
+			ID:               token.FamilyID,
+			UserAgent:        token.UserAgent,
+			IPAddress:        token.IPAddress,
+			SessionStartedAt: token.SessionStartedAt,
+			LastRefreshedAt:  token.CreatedAt,
+			ExpiresAt:        token.ExpiresAt,
+		})
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeMySession mencabut satu sesi (family) milik pengguna yang sedang login
+func (h *SessionHandler) RevokeMySession(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
+	}
+
+	familyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid session ID format")
+	}
+
+	revoked, err := h.Tokens.RevokeFamily(c.UserContext(), userID, familyID)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if revoked == 0 {
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "Session not found")
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Session revoked successfully", nil)
+}
+
+// currentUserID mengambil ID pengguna yang sedang login dari context
+func currentUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return uuid.Nil, errors.New("user ID not found in token")
+	}
+	return uuid.Parse(userIDStr)
+}
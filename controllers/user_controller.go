@@ -1,93 +1,121 @@
 package controllers
 
 import (
-	"library/database" // Sesuaikan dengan nama proyekmu
 	"library/helpers"  // Sesuaikan dengan nama proyekmu
 	"library/models"   // Sesuaikan dengan nama proyekmu
-	"strconv"
+	"library/services" // Sesuaikan dengan nama proyekmu
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// UserHandler menangani endpoint pengelolaan pengguna
+type UserHandler struct {
+	Accounts *services.AccountService
+}
+
+// NewUserHandler membuat UserHandler
+func NewUserHandler(accounts *services.AccountService) *UserHandler {
+	return &UserHandler{Accounts: accounts}
+}
+
 // CreateUser membuat pengguna baru
-func CreateUser(c *fiber.Ctx) error {
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 	user := new(models.User)
 
 	if err := c.BodyParser(user); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not hash password")
-	}
-	user.Password = string(hashedPassword)
-
-	if result := database.DBClient.Create(&user); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	// Registrasi publik selalu menjadi member dengan tier default
+	if err := h.Accounts.Register(c.UserContext(), user); err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusCreated, "User created successfully", user)
 }
 
-// GetAllUsers mendapatkan semua pengguna
-func GetAllUsers(c *fiber.Ctx) error {
-	var user []models.User
-	var total int64
-	page, err := strconv.Atoi(c.Query("page", "1")) // Ambil "page" dari URL, default "1"
-	if err != nil || page < 1 {
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page number")
-	}
+// userListSpec adalah filter, urutan dan field yang didukung GetAllUsers
+var userListSpec = helpers.ListSpec{
+	Filters: map[string]helpers.FilterSpec{
+		"name":         {Column: "name", Operator: helpers.FilterContains},
+		"email":        {Column: "email", Operator: helpers.FilterIEquals},
+		"role":         {Column: "role", Operator: helpers.FilterEquals},
+		"created_from": {Column: "created_at", Operator: helpers.FilterDateFrom},
+		"created_to":   {Column: "created_at", Operator: helpers.FilterDateTo},
+	},
+	Sortable: map[string]string{
+		"name":       "name",
+		"email":      "email",
+		"role":       "role",
+		"created_at": "created_at",
+	},
+	Fields: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"email":      "email",
+		"role":       "role",
+		"tier_id":    "tier_id",
+		"created_at": "CreatedAt",
+		"updated_at": "UpdatedAt",
+	},
+	DefaultSort: "created_at",
+	TieBreaker:  "id",
+	Nullable:    map[string]bool{"name": true, "created_at": true},
+}
 
-	limit, err := strconv.Atoi(c.Query("limit", "10")) // Ambil "limit" dari URL, default "10"
-	if err != nil || limit < 1 {
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit number")
+// GetAllUsers mendapatkan semua pengguna. Mendukung filter (name, email, role,
+// created_from, created_to), ?sort= dan ?fields= sesuai userListSpec, serta pagination
+// offset (?page=) atau cursor (?pagination=cursor lalu ?cursor=).
+func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
+	pagination, err := helpers.ParsePagination(c)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	listQuery, err := helpers.ParseListQuery(c, userListSpec)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
 	}
-	offset := (page - 1) * limit
 
-	if result := database.DBClient.Model(&models.Book{}).Count(&total); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	user, page, err := h.Accounts.List(c.UserContext(), listQuery, pagination)
+	if err != nil {
+		return helpers.ListErrorResponse(c, err)
+	}
+	// Hapus password sebelum return
+	for i := range user {
+		user[i].Password = ""
 	}
-	if result := database.DBClient.Limit(limit).Offset(offset).Find(&user); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	if len(user) == 0 && pagination.Page > 1 { // Jika halaman lebih dari 1 dan tidak ada pengguna, berarti halaman kosong
+		return helpers.ErrorResponse(c, fiber.StatusNotFound, "No users found on this page")
 	}
-	if len(user) == 0 && page > 1 { // Jika halaman lebih dari 1 dan tidak ada buku, berarti halaman kosong
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "No books found on this page")
-	} else if len(user) == 0 { // Jika di halaman 1 pun tidak ada buku sama sekali
-		return helpers.SuccessResponse(c, fiber.StatusOK, "No books found", []models.Book{})
+	if page.Data, err = listQuery.Project(user); err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
-	totalPages := (total + int64(limit) - 1) / int64(limit)
-	return helpers.SuccessResponse(c, fiber.StatusOK, "Books retrieved successfully", fiber.Map{
-		"data":         user,
-		"total_items":  total,
-		"current_page": page,
-		"per_page":     limit,
-		"total_pages":  totalPages,
-	})
+	if len(user) == 0 { // Jika di halaman pertama pun tidak ada pengguna sama sekali
+		return helpers.SuccessResponse(c, fiber.StatusOK, "No users found", page)
+	}
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Users retrieved successfully", page)
 }
 
 // GetUserByID mendapatkan pengguna berdasarkan ID
-func GetUserByID(c *fiber.Ctx) error {
+func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
-	bookID, err := uuid.Parse(idStr)
+	userID, err := uuid.Parse(idStr)
 	if err != nil {
 		// Jika ID dari URL bukan UUID yang valid
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid User ID format")
 	}
-	user := new(models.User)
 
-	if result := database.DBClient.First(&user, bookID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "User not found")
+	user, err := h.Accounts.Get(c.UserContext(), userID)
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "User retrieved successfully", user)
 }
 
 // UpdateUser memperbarui pengguna
-func UpdateUser(c *fiber.Ctx) error {
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 
 	userID, err := uuid.Parse(idStr)
@@ -96,41 +124,31 @@ func UpdateUser(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
 	}
 
-	user := new(models.User)
-
-	if result := database.DBClient.First(&user, userID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "User not found")
-	}
-
 	updates := new(models.User)
 	if err := c.BodyParser(updates); err != nil {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if updates.Name != "" {
-		user.Name = updates.Name
-	}
-	if updates.Email != "" {
-		user.Email = updates.Email
-	}
-	// Perbarui password jika ada
-	if updates.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updates.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return helpers.ErrorResponse(c, fiber.StatusInternalServerError, "Could not hash password")
-		}
-		user.Password = string(hashedPassword)
+	actor, err := currentActor(c)
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
 	}
-
-	if result := database.DBClient.Save(&user); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	// Perubahan role hanya boleh dilakukan oleh admin
+	user, err := h.Accounts.Update(c.UserContext(), userID, services.UserUpdate{
+		Name:     updates.Name,
+		Email:    updates.Email,
+		Password: updates.Password,
+		Role:     updates.Role,
+	}, actor)
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "User updated successfully", user)
 }
 
 // DeleteUser menghapus pengguna
-func DeleteUser(c *fiber.Ctx) error {
+func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	userID, err := uuid.Parse(idStr)
 	if err != nil {
@@ -138,20 +156,15 @@ func DeleteUser(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
 	}
 
-	user := new(models.User)
-
-	if result := database.DBClient.First(&user, userID); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "User not found")
-	}
-
-	if result := database.DBClient.Delete(&user); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	if err := h.Accounts.Delete(c.UserContext(), userID); err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	return helpers.SuccessResponse(c, fiber.StatusOK, "User deleted successfully", nil)
 }
 
-func GetCurrentUser(c *fiber.Ctx) error {
+// GetCurrentUser menampilkan profil pengguna yang sedang login beserta tier-nya
+func (h *UserHandler) GetCurrentUser(c *fiber.Ctx) error {
 	userID := c.Locals("userID")
 	if userID == nil {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token")
@@ -162,9 +175,9 @@ func GetCurrentUser(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format")
 	}
 
-	user := new(models.User)
-	if result := database.DBClient.First(&user, id); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusNotFound, "User not found")
+	user, err := h.Accounts.Get(c.UserContext(), id)
+	if err != nil {
+		return serviceErrorResponse(c, err)
 	}
 
 	user.Password = ""
@@ -172,12 +185,12 @@ func GetCurrentUser(c *fiber.Ctx) error {
 	return helpers.SuccessResponse(c, fiber.StatusOK, "User retrieved successfully", user)
 }
 
-func GetAllUsersNoPagination(c *fiber.Ctx) error {
-	var users []models.User
-
+// GetAllUsersNoPagination mendapatkan semua pengguna tanpa pagination
+func (h *UserHandler) GetAllUsersNoPagination(c *fiber.Ctx) error {
 	// Ambil semua user
-	if result := database.DBClient.Find(&users); result.Error != nil {
-		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, result.Error.Error())
+	users, err := h.Accounts.All(c.UserContext())
+	if err != nil {
+		return helpers.ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
 	// Hapus password sebelum return
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"library/config"
+	"library/helpers"
+	"library/models"
+	"library/repositories"
+	"library/services"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	testMemberID = uuid.MustParse("5ebc7f41-8d90-41a2-9e0d-2f3a4b5c6d7e")
+	testTierID   = uuid.MustParse("6fcd8052-9ea1-42b3-8f1e-3a4b5c6d7e8f")
+)
+
+// newTestUserRepository berisi pengguna yang sedang login (dengan tier public) dan seorang anggota
+func newTestUserRepository() *repositories.MemoryUserRepository {
+	tierID := testTierID
+	users := repositories.NewMemoryUserRepository(
+		models.User{ID: testUserID, Name: "Current User", Email: "me@example.com", Password: "hash", Role: models.RoleMember, TierID: &tierID},
+		models.User{ID: testMemberID, Name: "Member", Email: "member@example.com", Password: "hash", Role: models.RoleMember},
+	)
+	users.AddTiers(models.MembershipTier{ID: testTierID, Code: "public", Name: "Public"})
+	return users
+}
+
+func userRoutes(h *UserHandler) func(app *fiber.App) {
+	return func(app *fiber.App) {
+		app.Post("/users", h.CreateUser)
+		app.Get("/users", h.GetAllUsers)
+		app.Get("/users/all", h.GetAllUsersNoPagination)
+		app.Get("/users/me", h.GetCurrentUser)
+		app.Get("/users/:id", h.GetUserByID)
+		app.Put("/users/:id", h.UpdateUser)
+		app.Delete("/users/:id", h.DeleteUser)
+	}
+}
+
+func TestUserHandler(t *testing.T) {
+	tests := []handlerCase{
+		{
+			name:   "register hashes password and forces member role with default tier",
+			method: http.MethodPost, path: "/users",
+			body:       `{"name":"New","email":"new@example.com","password":"secret123","role":"admin","tier_id":"` + uuid.NewString() + `"}`,
+			wantStatus: fiber.StatusCreated,
+			check: func(t *testing.T, resp testResponse) {
+				var user models.User
+				decodeData(t, resp, &user)
+				if user.Role != models.RoleMember {
+					t.Errorf("role = %q, want member", user.Role)
+				}
+				if user.TierID == nil || *user.TierID != testTierID {
+					t.Errorf("tier_id = %v, want default tier %s", user.TierID, testTierID)
+				}
+				if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("secret123")) != nil {
+					t.Errorf("password is not a bcrypt hash of the submitted password")
+				}
+			},
+		},
+		{
+			name:   "register rejects malformed body",
+			method: http.MethodPost, path: "/users",
+			body:       `{"name":`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "list hides passwords", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/users",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var page struct {
+					Data []models.User `json:"data"`
+				}
+				decodeData(t, resp, &page)
+				if len(page.Data) != 2 {
+					t.Fatalf("got %d users, want 2", len(page.Data))
+				}
+				for _, user := range page.Data {
+					if user.Password != "" {
+						t.Errorf("password of %s is exposed", user.Email)
+					}
+				}
+			},
+		},
+		{
+			name: "list rejects malformed date filter", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/users?created_from=yesterday",
+			wantStatus: fiber.StatusBadRequest, wantCode: helpers.ErrCodeInvalidQuery,
+		},
+		{
+			name: "list all hides passwords", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/users/all",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var users []models.User
+				decodeData(t, resp, &users)
+				if len(users) != 2 || users[0].Password != "" || users[1].Password != "" {
+					t.Errorf("got %d users, passwords must be empty", len(users))
+				}
+			},
+		},
+		{
+			name: "current user includes tier", role: models.RoleMember,
+			method: http.MethodGet, path: "/users/me",
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var user models.User
+				decodeData(t, resp, &user)
+				if user.ID != testUserID || user.Tier == nil || user.Tier.Code != "public" || user.Password != "" {
+					t.Errorf("got user %s with tier %v", user.ID, user.Tier)
+				}
+			},
+		},
+		{
+			name: "get by id", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/users/" + testMemberID.String(),
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "get by id rejects malformed id", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/users/42",
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "get unknown user", role: models.RoleLibrarian,
+			method: http.MethodGet, path: "/users/" + uuid.NewString(),
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "librarian updates name", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/users/" + testMemberID.String(),
+			body:       `{"name":"Renamed"}`,
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var user models.User
+				decodeData(t, resp, &user)
+				if user.Name != "Renamed" || user.Email != "member@example.com" {
+					t.Errorf("got name %q, email %q", user.Name, user.Email)
+				}
+			},
+		},
+		{
+			name: "librarian cannot change role", role: models.RoleLibrarian,
+			method: http.MethodPut, path: "/users/" + testMemberID.String(),
+			body:       `{"role":"admin"}`,
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name: "admin rejects unknown role", role: models.RoleAdmin,
+			method: http.MethodPut, path: "/users/" + testMemberID.String(),
+			body:       `{"role":"superuser"}`,
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "admin promotes to librarian", role: models.RoleAdmin,
+			method: http.MethodPut, path: "/users/" + testMemberID.String(),
+			body:       `{"role":"librarian"}`,
+			wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, resp testResponse) {
+				var user models.User
+				decodeData(t, resp, &user)
+				if user.Role != models.RoleLibrarian {
+					t.Errorf("role = %q, want librarian", user.Role)
+				}
+			},
+		},
+		{
+			name: "update unknown user", role: models.RoleAdmin,
+			method: http.MethodPut, path: "/users/" + uuid.NewString(),
+			body:       `{"name":"Nobody"}`,
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "delete user", role: models.RoleAdmin,
+			method: http.MethodDelete, path: "/users/" + testMemberID.String(),
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "delete unknown user", role: models.RoleAdmin,
+			method: http.MethodDelete, path: "/users/" + uuid.NewString(),
+			wantStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runHandlerCase(t, tc, userRoutes(NewUserHandler(services.NewAccountService(newTestUserRepository(), &config.Config{DefaultTierCode: "public"}))))
+		})
+	}
+}
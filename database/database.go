@@ -3,7 +3,6 @@ package database
 import (
 	"fmt"
 	"library/config" // Sesuaikan dengan nama proyekmu
-	"library/models" // Sesuaikan dengan nama proyekmu
 	"log"
 
 	"gorm.io/driver/postgres"
@@ -12,8 +11,8 @@ import (
 
 var DBClient *gorm.DB
 
-// InitDatabase menginisialisasi koneksi database
-func InitDatabase(cfg *config.Config) {
+// Connect membuka koneksi database tanpa menjalankan migration
+func Connect(cfg *config.Config) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
 		cfg.DBHost, cfg.DBUser, cfg.DBPass, cfg.DBName, cfg.DBPort)
 
@@ -23,9 +22,50 @@ func InitDatabase(cfg *config.Config) {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Ukuran pool dan umur koneksi dari DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+	// DB_CONN_MAX_LIFETIME dan DB_CONN_MAX_IDLE_TIME
+	sqlDB, err := DBClient.DB()
+	if err != nil {
+		log.Fatalf("Failed to access database pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+
 	log.Println("Database connected successfully!")
+}
 
-	// Migrasi skema database
-	DBClient.AutoMigrate(&models.User{})
+// Close menutup pool koneksi database setelah semua pemakainya berhenti
+func Close() error {
+	if DBClient == nil {
+		return nil
+	}
+	sqlDB, err := DBClient.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// InitDatabase menghubungkan database lalu menjalankan migration skema dan data yang belum
+// diterapkan (kecuali DB_AUTO_MIGRATE=false)
+func InitDatabase(cfg *config.Config) {
+	Connect(cfg)
+
+	// Migrasi skema database dari file migration yang tertanam di binary
+	if cfg.DBAutoMigrate {
+		applied, err := MigrateUp(DBClient, cfg, 0)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		for _, migration := range applied {
+			log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+		}
+	} else if pending, err := PendingMigrations(DBClient); err != nil {
+		log.Fatalf("Failed to check database migrations: %v", err)
+	} else if pending > 0 {
+		log.Fatalf("Database has %d pending migrations, run \"migrate up\" first", pending)
+	}
 	log.Println("Database migration complete!")
 }
@@ -0,0 +1,315 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"library/config" // Sesuaikan dengan nama proyekmu
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles berisi file migration bernomor, misalnya 0001_initial_schema.up.sql dan
+// 0001_initial_schema.down.sql, yang ikut tertanam di binary
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey adalah kunci pg_advisory_lock yang dipegang selama migration berjalan,
+// supaya beberapa instance yang start bersamaan tidak menjalankan migration yang sama
+const migrationLockKey int64 = 7301928374650001
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration adalah satu versi skema database beserta SQL untuk menaikkan dan menurunkannya.
+// Migration data (lihat dataMigrations) memakai UpFunc dan DownFunc sebagai pengganti SQL.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	UpFunc   func(tx *gorm.DB, cfg *config.Config) error
+	DownFunc func(tx *gorm.DB) error
+}
+
+// up menjalankan migration di dalam transaksi tx
+func (m Migration) up(tx *gorm.DB, cfg *config.Config) error {
+	if m.UpFunc != nil {
+		return m.UpFunc(tx, cfg)
+	}
+	return tx.Exec(m.Up).Error
+}
+
+// down membatalkan migration di dalam transaksi tx
+func (m Migration) down(tx *gorm.DB) error {
+	if m.DownFunc != nil {
+		return m.DownFunc(tx)
+	}
+	return tx.Exec(m.Down).Error
+}
+
+// MigrationStatus adalah status sebuah migration. AppliedAt nil berarti belum dijalankan,
+// Missing berarti versi tercatat di database tetapi filenya tidak ada di binary ini.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+	Missing   bool       `json:"missing,omitempty"`
+}
+
+// schemaMigration adalah baris tabel schema_migrations
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations membaca migration SQL yang tertanam di binary ditambah migration data,
+// diurutkan dari versi terkecil. Setiap versi SQL wajib punya file up dan down, dan satu
+// versi tidak boleh dipakai dua migration.
+func LoadMigrations() ([]Migration, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[int64]string, len(migrations))
+	for _, migration := range migrations {
+		versions[migration.Version] = migration.Name
+	}
+	for _, migration := range dataMigrations {
+		if name, ok := versions[migration.Version]; ok {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", migration.Version, name, migration.Name)
+		}
+		versions[migration.Version] = migration.Name
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, path := range paths {
+		filename := path[len("migrations/"):]
+		match := migrationFilePattern.FindStringSubmatch(filename)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected <version>_<name>.up.sql or .down.sql", filename)
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q", filename)
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names (%s, %s)", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MigrateUp menjalankan migration yang belum diterapkan secara berurutan, paling banyak steps
+// migration (0 berarti semua). Setiap migration berjalan dalam transaksinya sendiri, jadi
+// migration yang gagal tidak meninggalkan skema setengah jadi. cfg dipakai migration data
+// yang membutuhkan konfigurasi. Mengembalikan migration yang diterapkan.
+func MigrateUp(db *gorm.DB, cfg *config.Config, steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.up(tx, cfg); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown membatalkan steps migration terakhir yang sudah diterapkan, dari versi terbesar.
+// Mengembalikan migration yang dibatalkan.
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		var rows []schemaMigration
+		if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			migration, ok := byVersion[row.Version]
+			if !ok {
+				return fmt.Errorf("migration %d_%s is applied but its files are not in this build", row.Version, row.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses mengembalikan status semua migration, diurutkan berdasarkan versi
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(db); err != nil {
+		return nil, err
+	}
+	done, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := done[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range done {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// PendingMigrations menghitung migration yang belum diterapkan
+func PendingMigrations(db *gorm.DB) (int, error) {
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withMigrationLock menjalankan fn pada satu koneksi yang memegang advisory lock migration.
+// Proses lain yang memanggilnya akan menunggu sampai lock dilepas.
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		if err := ensureMigrationTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// ensureMigrationTable membuat tabel schema_migrations jika belum ada
+func ensureMigrationTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT NOW()
+	)`).Error
+}
+
+// appliedMigrations mengembalikan migration yang tercatat di schema_migrations per versi
+func appliedMigrations(db *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
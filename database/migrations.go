@@ -0,0 +1,163 @@
+package database
+
+import (
+	"library/config"  // Sesuaikan dengan nama proyekmu
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// dataMigrations adalah perbaikan data lama yang membutuhkan konfigurasi atau logika Go.
+// Versinya melanjutkan nomor migration SQL dan dicatat di schema_migrations yang sama.
+// Membatalkannya hanya menghapus catatan tersebut, data yang sudah diperbaiki tidak dikembalikan.
+var dataMigrations = []Migration{
+	{Version: 4, Name: "backfill_lending_due_dates", UpFunc: backfillLendingDueDates, DownFunc: keepData},
+	{Version: 5, Name: "seed_book_copies", UpFunc: seedBookCopies, DownFunc: keepData},
+	{Version: 6, Name: "normalize_book_isbns", UpFunc: normalizeBookIsbns, DownFunc: keepData},
+	{Version: 7, Name: "seed_membership_tiers", UpFunc: seedMembershipTiers, DownFunc: keepData},
+}
+
+// keepData adalah DownFunc migration data yang tidak bisa dibatalkan
+func keepData(tx *gorm.DB) error {
+	return nil
+}
+
+// backfillLendingDueDates mengisi due_date (borrow_date ditambah LOAN_PERIOD_DAYS) dan status
+// untuk peminjaman lama yang dibuat sebelum kolom tersebut ada
+func backfillLendingDueDates(tx *gorm.DB, cfg *config.Config) error {
+	if err := tx.Exec(`UPDATE lending_records
+		SET due_date = borrow_date + make_interval(days => ?)
+		WHERE due_date IS NULL`, cfg.LoanPeriodDays).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`UPDATE lending_records SET status = 'returned'
+		WHERE return_date IS NOT NULL AND status = 'borrowed'`).Error
+}
+
+// seedBookCopies membuat eksemplar dengan barcode otomatis untuk buku lama yang hanya memiliki quantity.
+// Peminjaman yang masih berjalan dan hold ready dihubungkan ke eksemplar-eksemplar tersebut
+// supaya ketersediaan tetap sama seperti sebelum eksemplar dipakai.
+func seedBookCopies(tx *gorm.DB, _ *config.Config) error {
+	var books []models.Book
+	if err := tx.Where(`quantity > 0 AND NOT EXISTS (
+		SELECT 1 FROM book_copies WHERE book_copies.book_id = books.id)`).Find(&books).Error; err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		if err := seedCopiesForBook(tx, book); err != nil {
+			return err
+		}
+	}
+
+	if len(books) > 0 {
+		log.Printf("Created copies for %d existing books", len(books))
+	}
+	return nil
+}
+
+// seedCopiesForBook membuat eksemplar untuk satu buku lama dan menghubungkan peminjaman
+// yang masih berjalan serta hold ready-nya
+func seedCopiesForBook(tx *gorm.DB, book models.Book) error {
+	var openRecords []models.Lending_records
+	if err := tx.Where("book_id = ? AND return_date IS NULL AND copy_id IS NULL", book.ID).
+		Order("borrow_date ASC").Find(&openRecords).Error; err != nil {
+		return err
+	}
+	var readyHolds []models.Hold
+	if err := tx.Where("book_id = ? AND status = ? AND copy_id IS NULL", book.ID, models.HoldStatusReady).
+		Order("created_at ASC").Find(&readyHolds).Error; err != nil {
+		return err
+	}
+
+	for i := 0; i < book.Quantity; i++ {
+		bookCopy := models.BookCopy{
+			BookID:  book.ID,
+			Barcode: helpers.GenerateCopyBarcode(book.ID, i+1),
+			Status:  models.CopyStatusAvailable,
+		}
+
+		var record *models.Lending_records
+		var hold *models.Hold
+		switch {
+		case i < len(openRecords):
+			record = &openRecords[i]
+			bookCopy.Status = models.CopyStatusOnLoan
+			if record.Status == models.RecordStatusLost {
+				bookCopy.Status = models.CopyStatusLost
+			}
+		case i-len(openRecords) < len(readyHolds):
+			hold = &readyHolds[i-len(openRecords)]
+			bookCopy.Status = models.CopyStatusOnHold
+		}
+
+		if err := tx.Create(&bookCopy).Error; err != nil {
+			return err
+		}
+		if record != nil {
+			if err := tx.Model(&models.Lending_records{}).Where("id = ?", record.ID).
+				Update("copy_id", bookCopy.ID).Error; err != nil {
+				return err
+			}
+		}
+		if hold != nil {
+			if err := tx.Model(&models.Hold{}).Where("id = ?", hold.ID).
+				Update("copy_id", bookCopy.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// Eksemplar hilang tidak lagi dihitung dalam quantity
+	return tx.Exec(`UPDATE books SET quantity = (
+			SELECT COUNT(*) FROM book_copies
+			WHERE book_copies.book_id = books.id AND book_copies.status NOT IN (?, ?)
+		) WHERE id = ?`, models.CopyStatusLost, models.CopyStatusWithdrawn, book.ID).Error
+}
+
+// seedMembershipTiers membuat tier keanggotaan bawaan yang belum ada. Tier yang sudah ada
+// tidak diubah supaya aturan yang disesuaikan admin tidak tertimpa.
+func seedMembershipTiers(tx *gorm.DB, _ *config.Config) error {
+	for _, tier := range models.DefaultMembershipTiers() {
+		if err := tx.Where("code = ?", tier.Code).FirstOrCreate(&tier).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeBookIsbns mengubah ISBN buku lama ke bentuk kanonik ISBN-13 tanpa tanda hubung.
+// ISBN yang tidak valid atau yang bentuk kanoniknya sudah dipakai buku lain dibiarkan
+// dan dicatat di log supaya bisa diperbaiki manual.
+func normalizeBookIsbns(tx *gorm.DB, _ *config.Config) error {
+	var books []models.Book
+	if err := tx.Unscoped().Select("id", "isbn").Where("isbn <> '' AND isbn !~ '^97[89][0-9]{10}$'").Find(&books).Error; err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		isbn, err := helpers.NormalizeISBN(book.Isbn)
+		if err != nil {
+			log.Printf("Book %s has an invalid ISBN %q, please correct it", book.ID, book.Isbn)
+			continue
+		}
+		if isbn == book.Isbn {
+			continue
+		}
+
+		var duplicates int64
+		if err := tx.Unscoped().Model(&models.Book{}).Where("isbn = ?", isbn).Count(&duplicates).Error; err != nil {
+			return err
+		}
+		if duplicates > 0 {
+			log.Printf("Book %s has ISBN %q which duplicates another book as %s, please merge them", book.ID, book.Isbn, isbn)
+			continue
+		}
+		if err := tx.Unscoped().Model(&models.Book{}).Where("id = ?", book.ID).Update("isbn", isbn).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS holds;
+DROP TABLE IF EXISTS fine_transactions;
+DROP TABLE IF EXISTS renewals;
+DROP TABLE IF EXISTS lending_records;
+DROP TABLE IF EXISTS book_copies;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS membership_tiers;
@@ -0,0 +1,175 @@
+-- Skema awal. Semua perintah memakai IF NOT EXISTS supaya database yang sebelumnya dibuat
+-- dengan AutoMigrate atau query.sql lama ikut tercatat tanpa kehilangan data.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- books.quantity dulu berupa teks (misal "5 buku"), diubah menjadi integer sebelum dipakai
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'books' AND column_name = 'quantity'
+            AND data_type IN ('text', 'character varying')
+    ) THEN
+        ALTER TABLE books
+            ALTER COLUMN quantity TYPE integer
+                USING COALESCE(NULLIF(regexp_replace(quantity, '[^0-9]', '', 'g'), ''), '0')::integer,
+            ALTER COLUMN quantity SET DEFAULT 0,
+            ALTER COLUMN quantity SET NOT NULL;
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS membership_tiers (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code varchar(30) NOT NULL,
+    name text,
+    max_loans bigint NOT NULL,
+    loan_period_days bigint NOT NULL,
+    max_renewals bigint NOT NULL,
+    fine_daily_rate bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_membership_tiers_code ON membership_tiers (code);
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text,
+    email text CONSTRAINT uni_users_email UNIQUE,
+    password text,
+    role varchar(20) NOT NULL DEFAULT 'member',
+    tier_id uuid CONSTRAINT fk_users_tier REFERENCES membership_tiers (id)
+);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'member',
+    ADD COLUMN IF NOT EXISTS tier_id uuid;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_tier_id ON users (tier_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    family_id uuid NOT NULL,
+    token_hash char(64) NOT NULL,
+    user_agent text,
+    ip_address text,
+    session_started_at timestamptz,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    replaced_by_id uuid,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS books (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title text,
+    author text,
+    isbn text CONSTRAINT uni_books_isbn UNIQUE,
+    quantity integer NOT NULL DEFAULT 0 CONSTRAINT chk_books_quantity CHECK (quantity >= 0),
+    category text,
+    replacement_cost bigint NOT NULL DEFAULT 0,
+    cover_version varchar(32) NOT NULL DEFAULT ''
+);
+ALTER TABLE books
+    ADD COLUMN IF NOT EXISTS replacement_cost bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cover_version varchar(32) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at);
+
+CREATE TABLE IF NOT EXISTS book_copies (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    book_id uuid NOT NULL CONSTRAINT fk_book_copies_book REFERENCES books (id),
+    barcode varchar(64) NOT NULL,
+    shelf_location text,
+    condition varchar(20) NOT NULL DEFAULT 'good',
+    status varchar(20) NOT NULL DEFAULT 'available',
+    acquired_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_book_copies_book_id ON book_copies (book_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_book_copies_barcode ON book_copies (barcode);
+CREATE INDEX IF NOT EXISTS idx_book_copies_status ON book_copies (status);
+
+CREATE TABLE IF NOT EXISTS lending_records (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    book_id uuid CONSTRAINT lending_records_book_id_fkey REFERENCES books (id),
+    copy_id uuid CONSTRAINT fk_lending_records_copy REFERENCES book_copies (id),
+    user_id uuid CONSTRAINT lending_records_user_id_fkey REFERENCES users (id),
+    borrow_date timestamptz,
+    due_date timestamptz,
+    return_date timestamptz,
+    status varchar(20) NOT NULL DEFAULT 'borrowed',
+    renewal_count bigint NOT NULL DEFAULT 0
+);
+-- Tabel dari AutoMigrate lama menyimpan book_id dan user_id sebagai teks tanpa foreign key,
+-- sedangkan tabel dari query.sql lama memakai tanggal tanpa jam
+ALTER TABLE lending_records
+    ALTER COLUMN book_id TYPE uuid USING book_id::uuid,
+    ALTER COLUMN user_id TYPE uuid USING user_id::uuid,
+    ALTER COLUMN borrow_date TYPE timestamptz,
+    ALTER COLUMN return_date TYPE timestamptz,
+    ADD COLUMN IF NOT EXISTS copy_id uuid,
+    ADD COLUMN IF NOT EXISTS due_date timestamptz,
+    ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'borrowed',
+    ADD COLUMN IF NOT EXISTS renewal_count bigint NOT NULL DEFAULT 0;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'lending_records_book_id_fkey') THEN
+        ALTER TABLE lending_records
+            ADD CONSTRAINT lending_records_book_id_fkey FOREIGN KEY (book_id) REFERENCES books (id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'lending_records_user_id_fkey') THEN
+        ALTER TABLE lending_records
+            ADD CONSTRAINT lending_records_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_lending_records_book_id ON lending_records (book_id);
+CREATE INDEX IF NOT EXISTS idx_lending_records_user_id ON lending_records (user_id);
+CREATE INDEX IF NOT EXISTS idx_lending_records_copy_id ON lending_records (copy_id);
+CREATE INDEX IF NOT EXISTS idx_lending_records_status ON lending_records (status);
+
+CREATE TABLE IF NOT EXISTS renewals (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    record_id uuid NOT NULL,
+    renewed_by uuid NOT NULL,
+    previous_due_date timestamptz,
+    new_due_date timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_renewals_record_id ON renewals (record_id);
+
+CREATE TABLE IF NOT EXISTS fine_transactions (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id uuid NOT NULL,
+    record_id uuid,
+    type varchar(20) NOT NULL,
+    amount bigint NOT NULL,
+    note text,
+    created_by uuid,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_fine_transactions_user_id ON fine_transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_fine_transactions_record_id ON fine_transactions (record_id);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    book_id uuid NOT NULL CONSTRAINT fk_holds_book REFERENCES books (id),
+    user_id uuid NOT NULL CONSTRAINT fk_holds_user REFERENCES users (id),
+    status varchar(20) NOT NULL DEFAULT 'waiting',
+    copy_id uuid,
+    ready_at timestamptz,
+    expires_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_holds_book_id ON holds (book_id);
+CREATE INDEX IF NOT EXISTS idx_holds_user_id ON holds (user_id);
+CREATE INDEX IF NOT EXISTS idx_holds_status ON holds (status);
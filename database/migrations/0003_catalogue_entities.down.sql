@@ -0,0 +1,5 @@
+-- Teks author dan category pada buku tetap berisi nama yang sudah diseragamkan
+DROP TABLE IF EXISTS book_categories;
+DROP TABLE IF EXISTS book_authors;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS authors;
@@ -0,0 +1,80 @@
+-- Penulis dan kategori sebagai entitas tersendiri yang dihubungkan ke buku. Nama unik tanpa
+-- membedakan huruf besar/kecil, dan kategori tersusun sebagai pohon lewat parent_id.
+CREATE TABLE IF NOT EXISTS authors (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_authors_name_lower ON authors (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS categories (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name text NOT NULL,
+    parent_id uuid CONSTRAINT fk_categories_children REFERENCES categories (id) ON DELETE RESTRICT,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name_lower ON categories (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id uuid CONSTRAINT fk_book_authors_book REFERENCES books (id),
+    author_id uuid CONSTRAINT fk_book_authors_author REFERENCES authors (id),
+    PRIMARY KEY (book_id, author_id)
+);
+
+CREATE TABLE IF NOT EXISTS book_categories (
+    book_id uuid CONSTRAINT fk_book_categories_book REFERENCES books (id),
+    category_id uuid CONSTRAINT fk_book_categories_category REFERENCES categories (id),
+    PRIMARY KEY (book_id, category_id)
+);
+
+-- Teks author (beberapa penulis dipisah titik koma) dan category pada buku lama dipindahkan ke
+-- tabel di atas. Nama yang hanya berbeda huruf besar/kecil atau spasi (misalnya "Fiksi" dan
+-- "fiksi ") menjadi satu entitas dengan penulisan yang paling sering dipakai, lalu kolom teks
+-- buku diseragamkan dengan penulisan tersebut. Hanya buku yang belum terhubung yang diproses.
+CREATE TEMPORARY TABLE book_author_names ON COMMIT DROP AS
+SELECT books.id AS book_id, parts.n, regexp_replace(TRIM(parts.part), '\s+', ' ', 'g') AS name
+FROM books CROSS JOIN LATERAL unnest(string_to_array(books.author, ';')) WITH ORDINALITY AS parts(part, n)
+WHERE NOT EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id);
+
+INSERT INTO authors (id, name, created_at, updated_at)
+SELECT uuid_generate_v4(), name, NOW(), NOW() FROM (
+    SELECT DISTINCT ON (LOWER(name)) name FROM book_author_names WHERE name <> ''
+    GROUP BY name ORDER BY LOWER(name), COUNT(*) DESC, name
+) AS canonical
+ON CONFLICT DO NOTHING;
+
+UPDATE books SET author = canonical.value FROM (
+    SELECT book_author_names.book_id, string_agg(authors.name, '; ' ORDER BY book_author_names.n) AS value
+    FROM book_author_names JOIN authors ON LOWER(authors.name) = LOWER(book_author_names.name)
+    GROUP BY book_author_names.book_id
+) AS canonical
+WHERE books.id = canonical.book_id AND books.author IS DISTINCT FROM canonical.value;
+
+INSERT INTO book_authors (book_id, author_id)
+SELECT DISTINCT book_author_names.book_id, authors.id
+FROM book_author_names JOIN authors ON LOWER(authors.name) = LOWER(book_author_names.name)
+ON CONFLICT DO NOTHING;
+
+CREATE TEMPORARY TABLE book_category_names ON COMMIT DROP AS
+SELECT books.id AS book_id, regexp_replace(TRIM(books.category), '\s+', ' ', 'g') AS name
+FROM books
+WHERE NOT EXISTS (SELECT 1 FROM book_categories WHERE book_categories.book_id = books.id);
+
+INSERT INTO categories (id, name, created_at, updated_at)
+SELECT uuid_generate_v4(), name, NOW(), NOW() FROM (
+    SELECT DISTINCT ON (LOWER(name)) name FROM book_category_names WHERE name <> ''
+    GROUP BY name ORDER BY LOWER(name), COUNT(*) DESC, name
+) AS canonical
+ON CONFLICT DO NOTHING;
+
+UPDATE books SET category = categories.name
+FROM book_category_names JOIN categories ON LOWER(categories.name) = LOWER(book_category_names.name)
+WHERE books.id = book_category_names.book_id AND books.category IS DISTINCT FROM categories.name;
+
+INSERT INTO book_categories (book_id, category_id)
+SELECT book_category_names.book_id, categories.id
+FROM book_category_names JOIN categories ON LOWER(categories.name) = LOWER(book_category_names.name)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GenerateCopyBarcode membuat barcode otomatis untuk eksemplar yang belum memiliki label,
+// dari 12 karakter pertama ID buku dan nomor urut eksemplar, contoh: "BK-1A2B3C4D5E6F-003"
+func GenerateCopyBarcode(bookID uuid.UUID, sequence int) string {
+	compact := strings.ToUpper(strings.ReplaceAll(bookID.String(), "-", ""))
+	return fmt.Sprintf("BK-%s-%03d", compact[:12], sequence)
+}
@@ -0,0 +1,51 @@
+package helpers
+
+// Kode error yang dikirim pada field error_code respons API
+const (
+	ErrCodeInvalidQuery = "INVALID_QUERY"
+
+	ErrCodeImportInvalidFile = "IMPORT_INVALID_FILE"
+	ErrCodeImportInvalidRows = "IMPORT_INVALID_ROWS"
+	ErrCodeImportInterrupted = "IMPORT_INTERRUPTED"
+
+	ErrCodeInvalidImage  = "INVALID_IMAGE"
+	ErrCodeImageTooLarge = "IMAGE_TOO_LARGE"
+
+	ErrCodeUserNotFound    = "USER_NOT_FOUND"
+	ErrCodeBookNotFound    = "BOOK_NOT_FOUND"
+	ErrCodeBookUnavailable = "BOOK_UNAVAILABLE"
+	ErrCodeInvalidISBN     = "INVALID_ISBN"
+	ErrCodeISBNExists      = "ISBN_EXISTS"
+	ErrCodeRecordNotFound  = "RECORD_NOT_FOUND"
+	ErrCodeRecordNotOpen   = "RECORD_NOT_OPEN"
+	ErrCodeRecordOpen      = "RECORD_OPEN"
+
+	ErrCodeAuthorNotFound   = "AUTHOR_NOT_FOUND"
+	ErrCodeAuthorExists     = "AUTHOR_EXISTS"
+	ErrCodeAuthorInUse      = "AUTHOR_IN_USE"
+	ErrCodeCategoryNotFound = "CATEGORY_NOT_FOUND"
+	ErrCodeCategoryExists   = "CATEGORY_EXISTS"
+	ErrCodeCategoryInUse    = "CATEGORY_IN_USE"
+	ErrCodeCategoryCycle    = "CATEGORY_CYCLE"
+
+	ErrCodeLoanLimitReached = "LOAN_LIMIT_REACHED"
+	ErrCodeTierNotFound     = "TIER_NOT_FOUND"
+
+	ErrCodeRenewalLimitReached = "RENEWAL_LIMIT_REACHED"
+	ErrCodeRenewalOverdue      = "RENEWAL_OVERDUE"
+	ErrCodeRenewalHoldPending  = "RENEWAL_HOLD_PENDING"
+
+	ErrCodeFinesOutstanding   = "FINES_OUTSTANDING"
+	ErrCodeFineExceedsBalance = "FINE_EXCEEDS_BALANCE"
+
+	ErrCodeHoldNotFound  = "HOLD_NOT_FOUND"
+	ErrCodeHoldExists    = "HOLD_EXISTS"
+	ErrCodeHoldNotNeeded = "HOLD_NOT_NEEDED"
+	ErrCodeHoldNotActive = "HOLD_NOT_ACTIVE"
+
+	ErrCodeCopyNotFound    = "COPY_NOT_FOUND"
+	ErrCodeCopyOnHold      = "COPY_ON_HOLD"
+	ErrCodeCopyUnavailable = "COPY_UNAVAILABLE"
+	ErrCodeCopyNotOnLoan   = "COPY_NOT_ON_LOAN"
+	ErrCodeBarcodeExists   = "BARCODE_EXISTS"
+)
@@ -0,0 +1,223 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// Format export yang didukung
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+	ExportXLSX   = "xlsx"
+)
+
+// ExportWriter menulis baris export satu per satu ke sebuah io.Writer tanpa
+// menampung seluruh data di memori
+type ExportWriter interface {
+	// WriteRow menulis satu baris dengan urutan nilai sesuai header
+	WriteRow(values []interface{}) error
+	// Flush meneruskan baris yang masih tertahan di buffer ke writer tujuan
+	Flush() error
+	// Close menulis sisa data ke writer tujuan
+	Close() error
+}
+
+// ParseExportFormat membaca ?format= (csv, ndjson atau xlsx), default csv
+func ParseExportFormat(c *fiber.Ctx) (string, error) {
+	switch format := c.Query("format", ExportCSV); format {
+	case ExportCSV, ExportNDJSON, ExportXLSX:
+		return format, nil
+	default:
+		return "", &QueryError{fmt.Sprintf("unsupported export format %q, allowed formats: csv, ndjson, xlsx", format)}
+	}
+}
+
+// ExportContentType mengembalikan Content-Type untuk format export
+func ExportContentType(format string) string {
+	switch format {
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportMARCXML:
+		return "application/marcxml+xml"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// NewExportWriter membuat ExportWriter untuk format tertentu. Header dipakai sebagai baris
+// pertama CSV/XLSX dan sebagai nama field tiap objek NDJSON; sheet adalah nama sheet XLSX.
+func NewExportWriter(format string, w io.Writer, headers []string, sheet string) (ExportWriter, error) {
+	switch format {
+	case ExportCSV:
+		writer := &csvExportWriter{writer: csv.NewWriter(w)}
+		if err := writer.writer.Write(headers); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case ExportNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w), headers: headers}, nil
+	case ExportXLSX:
+		return newXLSXExportWriter(w, headers, sheet)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// csvExportWriter menulis export sebagai CSV
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = exportText(value)
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	return e.Flush()
+}
+
+// ndjsonExportWriter menulis export sebagai satu objek JSON per baris
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+	headers []string
+}
+
+func (e *ndjsonExportWriter) WriteRow(values []interface{}) error {
+	row := make(map[string]interface{}, len(e.headers))
+	for i, header := range e.headers {
+		row[header] = values[i]
+	}
+	return e.encoder.Encode(row)
+}
+
+func (e *ndjsonExportWriter) Flush() error {
+	return nil
+}
+
+func (e *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// xlsxExportWriter menulis export sebagai XLSX memakai stream writer excelize, yang
+// memindahkan baris ke file sementara saat data sudah besar
+type xlsxExportWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	out    io.Writer
+	row    int
+}
+
+func newXLSXExportWriter(w io.Writer, headers []string, sheet string) (*xlsxExportWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	e := &xlsxExportWriter{file: file, stream: stream, out: w, row: 1}
+	headerRow := make([]interface{}, len(headers))
+	for i, header := range headers {
+		headerRow[i] = header
+	}
+	if err := e.WriteRow(headerRow); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxExportWriter) WriteRow(values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = xlsxValue(value)
+	}
+	e.row++
+	return e.stream.SetRow(cell, row)
+}
+
+// Flush tidak melakukan apa-apa karena file XLSX baru bisa dikirim setelah lengkap
+func (e *xlsxExportWriter) Flush() error {
+	return nil
+}
+
+func (e *xlsxExportWriter) Close() error {
+	defer e.file.Close()
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := e.file.WriteTo(e.out)
+	return err
+}
+
+// exportText mengubah nilai menjadi teks untuk CSV; waktu ditulis dalam RFC 3339
+// dan nilai kosong (nil) menjadi sel kosong
+func exportText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		// Teks yang diawali karakter rumus diberi tanda kutip supaya tidak dijalankan spreadsheet
+		if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// xlsxValue menyesuaikan nilai supaya excelize menulisnya sebagai tipe sel yang tepat
+func xlsxValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case string, int, int64, float64, bool, time.Time:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"math"
+	"time"
+)
+
+// CalculateOverdueFine menghitung denda keterlambatan. Setiap hari yang dimulai setelah jatuh tempo
+// dihitung satu hari, hari-hari masa toleransi tidak didenda, dan total denda dibatasi maxAmount
+// (0 berarti tanpa batas).
+func CalculateOverdueFine(dueDate, returnedAt time.Time, dailyRate int64, graceDays int, maxAmount int64) int64 {
+	if dueDate.IsZero() || !returnedAt.After(dueDate) {
+		return 0
+	}
+
+	daysLate := int(math.Ceil(returnedAt.Sub(dueDate).Hours() / 24))
+	chargeableDays := daysLate - graceDays
+	if chargeableDays <= 0 {
+		return 0
+	}
+
+	fine := int64(chargeableDays) * dailyRate
+	if maxAmount > 0 && fine > maxAmount {
+		fine = maxAmount
+	}
+	return fine
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateOverdueFine(t *testing.T) {
+	due := time.Date(2024, 5, 10, 17, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		dueDate    time.Time
+		returnedAt time.Time
+		graceDays  int
+		maxAmount  int64
+		want       int64
+	}{
+		{name: "returned early", dueDate: due, returnedAt: due.Add(-2 * day), want: 0},
+		{name: "returned exactly on the due date", dueDate: due, returnedAt: due, want: 0},
+		{name: "one minute late counts as a day", dueDate: due, returnedAt: due.Add(time.Minute), want: 1000},
+		{name: "per day amount", dueDate: due, returnedAt: due.Add(3 * day), want: 3000},
+		{name: "partial day rounds up", dueDate: due, returnedAt: due.Add(3*day + time.Hour), want: 4000},
+		{name: "last grace day", dueDate: due, returnedAt: due.Add(2 * day), graceDays: 2, want: 0},
+		{name: "first day after grace", dueDate: due, returnedAt: due.Add(2*day + time.Minute), graceDays: 2, want: 1000},
+		{name: "grace days are not charged", dueDate: due, returnedAt: due.Add(5 * day), graceDays: 2, want: 3000},
+		{name: "below the cap", dueDate: due, returnedAt: due.Add(4 * day), maxAmount: 5000, want: 4000},
+		{name: "at the cap", dueDate: due, returnedAt: due.Add(5 * day), maxAmount: 5000, want: 5000},
+		{name: "capped", dueDate: due, returnedAt: due.Add(30 * day), maxAmount: 5000, want: 5000},
+		{name: "zero cap means unlimited", dueDate: due, returnedAt: due.Add(30 * day), want: 30000},
+		{name: "no due date", returnedAt: due, want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CalculateOverdueFine(tc.dueDate, tc.returnedAt, 1000, tc.graceDays, tc.maxAmount)
+			if got != tc.want {
+				t.Errorf("CalculateOverdueFine() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
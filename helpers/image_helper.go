@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // Mendaftarkan decoder PNG
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Mendaftarkan decoder WebP
+)
+
+// Batas gambar cover yang diterima
+const (
+	MaxCoverUploadBytes = 5 << 20 // 5 MB
+	maxCoverDimension   = 6000    // Mencegah gambar kecil berukuran piksel raksasa menghabiskan memori
+	minCoverDimension   = 100
+	coverJPEGQuality    = 85
+)
+
+// ErrInvalidImage dikembalikan jika file bukan gambar JPEG, PNG atau WebP yang valid
+var ErrInvalidImage = errors.New("invalid image")
+
+// DecodeCoverImage memvalidasi dan men-decode gambar cover. Format dan ukuran piksel
+// diperiksa dari header dulu sebelum seluruh gambar di-decode.
+func DecodeCoverImage(data []byte) (image.Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: only JPEG, PNG and WebP images are supported", ErrInvalidImage)
+	}
+	if format != "jpeg" && format != "png" && format != "webp" {
+		return nil, fmt.Errorf("%w: only JPEG, PNG and WebP images are supported", ErrInvalidImage)
+	}
+	if config.Width > maxCoverDimension || config.Height > maxCoverDimension {
+		return nil, fmt.Errorf("%w: image must be at most %dx%d pixels", ErrInvalidImage, maxCoverDimension, maxCoverDimension)
+	}
+	if config.Width < minCoverDimension || config.Height < minCoverDimension {
+		return nil, fmt.Errorf("%w: image must be at least %dx%d pixels", ErrInvalidImage, minCoverDimension, minCoverDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return img, nil
+}
+
+// ResizeToWidth mengecilkan gambar ke lebar tertentu dengan rasio tetap.
+// Gambar yang sudah lebih kecil tidak diperbesar.
+func ResizeToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= width {
+		return img
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Over, nil)
+	return resized
+}
+
+// EncodeJPEG meng-encode gambar sebagai JPEG. Latar transparan (PNG/WebP) menjadi putih.
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), img, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: coverJPEGQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+// encodeTestImage membuat gambar polos berukuran width x height dalam format format
+func encodeTestImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	case "jpeg":
+		var data []byte
+		data, err = EncodeJPEG(img)
+		buf.Write(data)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeCoverImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "png", data: encodeTestImage(t, "png", 200, 300)},
+		{name: "jpeg", data: encodeTestImage(t, "jpeg", 400, 600)},
+		{name: "smallest allowed", data: encodeTestImage(t, "png", minCoverDimension, minCoverDimension)},
+		{name: "largest allowed", data: encodeTestImage(t, "png", maxCoverDimension, minCoverDimension)},
+		{name: "too narrow", data: encodeTestImage(t, "png", minCoverDimension-1, 300), wantErr: true},
+		{name: "too short", data: encodeTestImage(t, "png", 300, minCoverDimension-1), wantErr: true},
+		{name: "too wide", data: encodeTestImage(t, "png", maxCoverDimension+1, minCoverDimension), wantErr: true},
+		{name: "too tall", data: encodeTestImage(t, "png", minCoverDimension, maxCoverDimension+1), wantErr: true},
+		{name: "unsupported format", data: encodeTestImage(t, "gif", 200, 300), wantErr: true},
+		{name: "not an image", data: []byte("%PDF-1.7"), wantErr: true},
+		{name: "truncated image", data: encodeTestImage(t, "png", 200, 300)[:60], wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := DecodeCoverImage(tc.data)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidImage) {
+					t.Errorf("error = %v, want ErrInvalidImage", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeCoverImage() error = %v", err)
+			}
+			if img.Bounds().Dx() < minCoverDimension {
+				t.Errorf("decoded width = %d", img.Bounds().Dx())
+			}
+		})
+	}
+}
+
+func TestResizeToWidth(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 600, 900))
+	if got := ResizeToWidth(img, 150).Bounds(); got.Dx() != 150 || got.Dy() != 225 {
+		t.Errorf("ResizeToWidth(600x900, 150) = %dx%d, want 150x225", got.Dx(), got.Dy())
+	}
+	if got := ResizeToWidth(img, 1000).Bounds(); got.Dx() != 600 || got.Dy() != 900 {
+		t.Errorf("ResizeToWidth(600x900, 1000) = %dx%d, want the original size", got.Dx(), got.Dy())
+	}
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidISBN dikembalikan jika ISBN tidak berformat ISBN-10/ISBN-13, ISBN-13 tidak diawali
+// 978 atau 979, atau checksum-nya salah
+var ErrInvalidISBN = errors.New("invalid ISBN")
+
+// NormalizeISBN memvalidasi ISBN-10 atau ISBN-13 dan mengubahnya ke bentuk kanonik ISBN-13
+// tanpa tanda hubung, misalnya "0-306-40615-2" menjadi "9780306406157"
+func NormalizeISBN(raw string) (string, error) {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(raw)))
+
+	switch len(isbn) {
+	case 10:
+		if !validISBN10(isbn) {
+			return "", ErrInvalidISBN
+		}
+		isbn13 := "978" + isbn[:9]
+		return isbn13 + string(isbn13CheckDigit(isbn13)), nil
+	case 13:
+		// Prefix lain (misalnya 977 untuk ISSN) adalah EAN-13 yang valid tetapi bukan ISBN
+		if !allDigits(isbn) || !strings.HasPrefix(isbn, "978") && !strings.HasPrefix(isbn, "979") {
+			return "", ErrInvalidISBN
+		}
+		if isbn13CheckDigit(isbn[:12]) != isbn[12] {
+			return "", ErrInvalidISBN
+		}
+		return isbn, nil
+	default:
+		return "", ErrInvalidISBN
+	}
+}
+
+// validISBN10 memeriksa checksum ISBN-10 (digit terakhir boleh X yang berarti 10)
+func validISBN10(isbn string) bool {
+	if !allDigits(isbn[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(isbn[i]-'0') * (10 - i)
+	}
+	switch last := isbn[9]; {
+	case last == 'X':
+		sum += 10
+	case last >= '0' && last <= '9':
+		sum += int(last - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit menghitung digit cek dari 12 digit pertama ISBN-13
+func isbn13CheckDigit(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(first12[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// allDigits memeriksa apakah semua karakter berupa angka
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
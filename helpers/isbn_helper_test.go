@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "ISBN-13", raw: "9780306406157", want: "9780306406157"},
+		{name: "ISBN-13 with 979 prefix", raw: "979-10-90636-07-1", want: "9791090636071"},
+		{name: "ISBN-13 with hyphens", raw: "978-0-306-40615-7", want: "9780306406157"},
+		{name: "ISBN-13 with spaces", raw: " 978 0 306 40615 7 ", want: "9780306406157"},
+		{name: "ISBN-10 converted to ISBN-13", raw: "0306406152", want: "9780306406157"},
+		{name: "ISBN-10 with hyphens", raw: "0-306-40615-2", want: "9780306406157"},
+		{name: "ISBN-10 with X check digit", raw: "0-8044-2957-X", want: "9780804429573"},
+		{name: "ISBN-10 with lowercase x check digit", raw: "043942089x", want: "9780439420891"},
+		{name: "ISBN-13 wrong check digit", raw: "9780306406158", wantErr: true},
+		{name: "ISBN-13 without 978 or 979 prefix", raw: "9770306406158", wantErr: true},
+		{name: "ISBN-13 with X", raw: "978030640615X", wantErr: true},
+		{name: "ISBN-10 wrong check digit", raw: "0306406153", wantErr: true},
+		{name: "ISBN-10 with X before the check digit", raw: "03064061X2", wantErr: true},
+		{name: "letters", raw: "ISBN030640", wantErr: true},
+		{name: "wrong length", raw: "978030640615", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizeISBN(tc.raw)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidISBN) {
+					t.Errorf("NormalizeISBN(%q) = %q, %v, want ErrInvalidISBN", tc.raw, got, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("NormalizeISBN(%q) = %q, %v, want %q", tc.raw, got, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package helpers
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExportMARCXML adalah format export katalog untuk pertukaran dengan sistem perpustakaan lain
+const ExportMARCXML = "marcxml"
+
+// MARCXMLNamespace adalah namespace MARC21 slim yang dipakai MARCXML
+const MARCXMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+// Karakter pemisah pada format biner MARC21 (ISO 2709)
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+	marcLeaderLength      = 24
+	marcDirectoryEntry    = 12
+)
+
+// MARCRecord adalah satu record bibliografis MARC21
+type MARCRecord struct {
+	XMLName       xml.Name           `xml:"record"`
+	Leader        string             `xml:"leader"`
+	ControlFields []MARCControlField `xml:"controlfield"`
+	DataFields    []MARCDataField    `xml:"datafield"`
+}
+
+// MARCControlField adalah field kontrol (tag 001 sampai 009) yang hanya berisi nilai
+type MARCControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MARCDataField adalah field data dengan dua indikator dan daftar subfield
+type MARCDataField struct {
+	Tag       string         `xml:"tag,attr"`
+	Ind1      string         `xml:"ind1,attr"`
+	Ind2      string         `xml:"ind2,attr"`
+	Subfields []MARCSubfield `xml:"subfield"`
+}
+
+// MARCSubfield adalah satu subfield, misalnya $a pada field 245
+type MARCSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Subfield mengembalikan nilai pertama subfield code pada field tag, atau "" jika tidak ada
+func (r *MARCRecord) Subfield(tag string, code string) string {
+	for _, field := range r.DataFields {
+		if field.Tag != tag {
+			continue
+		}
+		for _, subfield := range field.Subfields {
+			if subfield.Code == code {
+				return subfield.Value
+			}
+		}
+	}
+	return ""
+}
+
+// SubfieldValues mengembalikan semua nilai subfield code dari semua field tag, sesuai urutan
+func (r *MARCRecord) SubfieldValues(tag string, code string) []string {
+	var values []string
+	for _, field := range r.DataFields {
+		if field.Tag != tag {
+			continue
+		}
+		for _, subfield := range field.Subfields {
+			if subfield.Code == code {
+				values = append(values, subfield.Value)
+			}
+		}
+	}
+	return values
+}
+
+// ReadMARC21 membaca semua record dari file biner MARC21 (ISO 2709).
+// Record berencoding MARC-8 (leader posisi 9 bukan "a") hanya terbaca benar untuk teks ASCII;
+// karakter lain diganti U+FFFD, jadi file seperti itu sebaiknya dikonversi ke UTF-8 lebih dulu.
+func ReadMARC21(r io.Reader) ([]MARCRecord, error) {
+	reader := bufio.NewReader(r)
+	var records []MARCRecord
+	for number := 1; ; number++ {
+		// Baris baru atau spasi di antara record diabaikan
+		for {
+			next, err := reader.Peek(1)
+			if errors.Is(err, io.EOF) {
+				if len(records) == 0 {
+					return nil, errors.New("no MARC21 records found")
+				}
+				return records, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if next[0] != '\n' && next[0] != '\r' && next[0] != ' ' {
+				break
+			}
+			reader.ReadByte()
+		}
+		// Lima byte pertama leader adalah panjang record, termasuk leader itu sendiri
+		prefix, err := reader.Peek(5)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: truncated record length", number)
+		}
+		length, err := strconv.Atoi(string(prefix))
+		if err != nil || length < marcLeaderLength+1 {
+			return nil, fmt.Errorf("record %d: invalid record length %q", number, prefix)
+		}
+		raw := make([]byte, length)
+		if _, err := io.ReadFull(reader, raw); err != nil {
+			return nil, fmt.Errorf("record %d: file ends before the record does", number)
+		}
+		record, err := parseMARC21Record(raw)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", number, err)
+		}
+		records = append(records, *record)
+	}
+}
+
+// parseMARC21Record mengurai satu record biner: leader, direktori lalu isi field
+func parseMARC21Record(raw []byte) (*MARCRecord, error) {
+	if raw[len(raw)-1] != marcRecordTerminator {
+		return nil, errors.New("missing record terminator")
+	}
+	leader := string(raw[:marcLeaderLength])
+	base, err := strconv.Atoi(leader[12:17])
+	if err != nil || base <= marcLeaderLength || base > len(raw) {
+		return nil, fmt.Errorf("invalid base address %q", leader[12:17])
+	}
+	utf8Encoded := leader[9] == 'a'
+
+	directory := raw[marcLeaderLength : base-1]
+	if raw[base-1] != marcFieldTerminator || len(directory)%marcDirectoryEntry != 0 {
+		return nil, errors.New("invalid directory")
+	}
+
+	record := &MARCRecord{Leader: leader}
+	for i := 0; i < len(directory); i += marcDirectoryEntry {
+		entry := string(directory[i : i+marcDirectoryEntry])
+		tag := entry[:3]
+		length, lengthErr := strconv.Atoi(entry[3:7])
+		start, startErr := strconv.Atoi(entry[7:12])
+		if lengthErr != nil || startErr != nil || length < 1 || start < 0 || base+start+length > len(raw) {
+			return nil, fmt.Errorf("invalid directory entry for field %s", tag)
+		}
+		// Field diakhiri field terminator yang tidak termasuk isi
+		data := raw[base+start : base+start+length-1]
+
+		if strings.HasPrefix(tag, "00") {
+			record.ControlFields = append(record.ControlFields, MARCControlField{Tag: tag, Value: marcText(data, utf8Encoded)})
+			continue
+		}
+		if len(data) < 2 {
+			return nil, fmt.Errorf("field %s is missing indicators", tag)
+		}
+		field := MARCDataField{Tag: tag, Ind1: string(data[0]), Ind2: string(data[1])}
+		for _, chunk := range strings.Split(string(data[2:]), string(rune(marcSubfieldDelimiter))) {
+			if chunk == "" {
+				continue
+			}
+			field.Subfields = append(field.Subfields, MARCSubfield{
+				Code:  chunk[:1],
+				Value: marcText([]byte(chunk[1:]), utf8Encoded),
+			})
+		}
+		record.DataFields = append(record.DataFields, field)
+	}
+	return record, nil
+}
+
+// marcText mengubah isi field menjadi teks UTF-8 yang valid
+func marcText(data []byte, utf8Encoded bool) string {
+	if utf8Encoded && utf8.Valid(data) {
+		return string(data)
+	}
+	return strings.ToValidUTF8(string(data), "\uFFFD")
+}
+
+// ReadMARCXML membaca semua record dari dokumen MARCXML, baik berupa <collection>
+// maupun satu <record>. Record dibaca satu per satu dari stream dokumen.
+func ReadMARCXML(r io.Reader) ([]MARCRecord, error) {
+	decoder := xml.NewDecoder(r)
+	var records []MARCRecord
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid MARCXML: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+		var record MARCRecord
+		if err := decoder.DecodeElement(&record, &start); err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no MARCXML records found")
+	}
+	return records, nil
+}
+
+// MARCXMLWriter menulis record MARCXML satu per satu di dalam sebuah <collection>
+type MARCXMLWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+}
+
+// NewMARCXMLWriter menulis deklarasi XML dan tag pembuka <collection> ke w
+func NewMARCXMLWriter(w io.Writer) (*MARCXMLWriter, error) {
+	if _, err := fmt.Fprintf(w, "%s<collection xmlns=%q>\n", xml.Header, MARCXMLNamespace); err != nil {
+		return nil, err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("  ", "  ")
+	return &MARCXMLWriter{w: w, encoder: encoder}, nil
+}
+
+// WriteRecord menulis satu record
+func (m *MARCXMLWriter) WriteRecord(record *MARCRecord) error {
+	return m.encoder.Encode(record)
+}
+
+// Close menulis tag penutup </collection>
+func (m *MARCXMLWriter) Close() error {
+	if err := m.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(m.w, "\n</collection>\n")
+	return err
+}
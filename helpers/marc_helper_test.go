@@ -0,0 +1,148 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readFixture membaca file dari testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// writeMARCXML menulis record sebagai koleksi MARCXML
+func writeMARCXML(t *testing.T, records []MARCRecord) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := NewMARCXMLWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		if err := writer.WriteRecord(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestMARC21ToMARCXMLRoundTrip(t *testing.T) {
+	records, err := ReadMARC21(bytes.NewReader(readFixture(t, "books.mrc")))
+	if err != nil {
+		t.Fatalf("ReadMARC21() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("read %d records, want 2", len(records))
+	}
+	if got := records[0].Subfield("245", "b"); got != "sebuah novel /" {
+		t.Errorf("245$b = %q", got)
+	}
+	if got := records[1].Subfield("100", "a"); got != "García Márquez, Gabriel," {
+		t.Errorf("100$a = %q", got)
+	}
+	if got := records[1].SubfieldValues("650", "a"); !reflect.DeepEqual(got, []string{"Fiction.", "Magic realism."}) {
+		t.Errorf("650$a = %q", got)
+	}
+
+	parsed, err := ReadMARCXML(writeMARCXML(t, records))
+	if err != nil {
+		t.Fatalf("ReadMARCXML() error = %v", err)
+	}
+	// Record dari file biner tidak memiliki namespace XML
+	for i := range parsed {
+		parsed[i].XMLName = xml.Name{}
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("records changed after the MARCXML round trip:\n got %+v\nwant %+v", parsed, records)
+	}
+}
+
+func TestMARCXMLRoundTrip(t *testing.T) {
+	records, err := ReadMARCXML(bytes.NewReader(readFixture(t, "books.xml")))
+	if err != nil {
+		t.Fatalf("ReadMARCXML() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Subfield("020", "a") != "9789793062792 (pbk.)" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	parsed, err := ReadMARCXML(writeMARCXML(t, records))
+	if err != nil {
+		t.Fatalf("ReadMARCXML() of the export error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("records changed after the MARCXML round trip:\n got %+v\nwant %+v", parsed, records)
+	}
+}
+
+func TestReadMARC21Malformed(t *testing.T) {
+	fixture := readFixture(t, "books.mrc")
+	valid := fixture[:bytes.IndexByte(fixture, marcRecordTerminator)+1]
+
+	// modified mengganti byte valid mulai dari offset dengan value
+	modified := func(offset int, value string) []byte {
+		raw := bytes.Clone(valid)
+		copy(raw[offset:], value)
+		return raw
+	}
+	// Entri direktori pertama dimulai tepat setelah leader: tag (3), panjang (4), posisi awal (5)
+	entry := marcLeaderLength
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr string
+	}{
+		{name: "empty file", input: nil, wantErr: "no MARC21 records"},
+		{name: "only blank lines", input: []byte("\n\r\n "), wantErr: "no MARC21 records"},
+		{name: "truncated leader", input: valid[:3], wantErr: "truncated record length"},
+		{name: "non-numeric record length", input: modified(0, "02x46"), wantErr: "invalid record length"},
+		{name: "record length shorter than the leader", input: modified(0, "00010"), wantErr: "invalid record length"},
+		{name: "file ends before the record", input: valid[:100], wantErr: "file ends before the record"},
+		{name: "missing record terminator", input: modified(len(valid)-1, "x"), wantErr: "missing record terminator"},
+		{name: "non-numeric base address", input: modified(12, "abcde"), wantErr: "invalid base address"},
+		{name: "base address past the record", input: modified(12, "99999"), wantErr: "invalid base address"},
+		{name: "base address inside the leader", input: modified(12, "00010"), wantErr: "invalid base address"},
+		{name: "base address inside the directory", input: modified(12, "00096"), wantErr: "invalid directory"},
+		{name: "non-numeric field length", input: modified(entry+3, "00x2"), wantErr: "invalid directory entry"},
+		{name: "zero field length", input: modified(entry+3, "0000"), wantErr: "invalid directory entry"},
+		{name: "field past the record", input: modified(entry+3, "9999"), wantErr: "invalid directory entry"},
+		{name: "negative field start", input: modified(entry+7, "-9999"), wantErr: "invalid directory entry"},
+		{name: "data field without indicators", input: modified(entry+2*marcDirectoryEntry+3, "0002"), wantErr: "missing indicators"},
+		{name: "second record truncated", input: append(bytes.Clone(valid), valid[:50]...), wantErr: "record 2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := ReadMARC21(bytes.NewReader(tc.input))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ReadMARC21() = %d records, error %v, want %q", len(records), err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadMARCXMLMalformed(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`<collection xmlns="http://www.loc.gov/MARC21/slim"></collection>`,
+		`<collection><record><leader>00000nam a2200000   4500</leader>`,
+		`<collection><record><datafield tag="245"><subfield code="a">Judul</datafield></record></collection>`,
+	} {
+		if records, err := ReadMARCXML(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadMARCXML(%q) = %+v, want an error", input, records)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package helpers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Mode pagination yang didukung endpoint list
+const (
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+)
+
+// MaxPageLimit adalah jumlah baris terbanyak per halaman; ?limit= yang lebih besar dipotong
+const MaxPageLimit = 100
+
+// Page adalah amplop respons yang sama untuk semua endpoint list.
+// Mode offset mengisi total_items, current_page dan total_pages; mode cursor mengisi
+// next_cursor dan prev_cursor dan tidak menghitung total supaya tetap cepat di tabel besar.
+type Page struct {
+	Data        interface{} `json:"data"`
+	PerPage     int         `json:"per_page"`
+	TotalItems  *int64      `json:"total_items,omitempty"`
+	CurrentPage int         `json:"current_page,omitempty"`
+	TotalPages  *int64      `json:"total_pages,omitempty"`
+	NextCursor  string      `json:"next_cursor,omitempty"`
+	PrevCursor  string      `json:"prev_cursor,omitempty"`
+}
+
+// Pagination adalah parameter pagination hasil parsing query string
+type Pagination struct {
+	Mode   string
+	Page   int
+	Limit  int
+	cursor *pageCursor
+}
+
+// pageCursor adalah isi token cursor: nilai kunci urut baris batas beserta arah halamannya.
+// Sort ikut disimpan supaya cursor tidak dipakai dengan urutan yang berbeda.
+type pageCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	Prev   bool     `json:"p,omitempty"`
+}
+
+// ParsePagination membaca ?page= dan ?limit= (mode offset) atau ?cursor= (mode cursor).
+// Halaman pertama mode cursor diminta dengan ?pagination=cursor.
+func ParsePagination(c *fiber.Ctx) (*Pagination, error) {
+	limit, err := ParseLimit(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := decodeCursor(token)
+		if err != nil {
+			return nil, &QueryError{"Invalid cursor"}
+		}
+		return &Pagination{Mode: PaginationCursor, Limit: limit, cursor: cursor}, nil
+	}
+	if c.Query("pagination") == PaginationCursor {
+		return &Pagination{Mode: PaginationCursor, Limit: limit}, nil
+	}
+
+	page, err := strconv.Atoi(c.Query("page", "1")) // Ambil "page" dari URL, default "1"
+	if err != nil || page < 1 {
+		return nil, &QueryError{"Invalid page number"}
+	}
+	return &Pagination{Mode: PaginationOffset, Page: page, Limit: limit}, nil
+}
+
+// ParseLimit membaca ?limit= (default 10) dan memotongnya ke MaxPageLimit
+func ParseLimit(c *fiber.Ctx) (int, error) {
+	limit, err := strconv.Atoi(c.Query("limit", "10")) // Ambil "limit" dari URL, default "10"
+	if err != nil || limit < 1 {
+		return 0, &QueryError{"Invalid limit number"}
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return limit, nil
+}
+
+// Paginate menjalankan query list (yang sudah difilter) dengan urutan dari ListQuery dan mengisi dest,
+// yaitu pointer ke slice model. Relasi pada preloads hanya dimuat untuk baris halaman, bukan saat Count.
+// Data pada Page berisi dest; pemanggil bisa menggantinya dengan hasil Project.
+func Paginate(db *gorm.DB, list *ListQuery, p *Pagination, dest interface{}, preloads ...string) (*Page, error) {
+	page := &Page{PerPage: p.Limit}
+	withPreloads := func(query *gorm.DB) *gorm.DB {
+		for _, relation := range preloads {
+			query = query.Preload(relation)
+		}
+		return query
+	}
+
+	if p.Mode == PaginationOffset {
+		var total int64
+		if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		if err := list.Order(db.Session(&gorm.Session{})).Scopes(withPreloads).
+			Limit(p.Limit).Offset((p.Page - 1) * p.Limit).Find(dest).Error; err != nil {
+			return nil, err
+		}
+		totalPages := (total + int64(p.Limit) - 1) / int64(p.Limit)
+		page.Data = dest
+		page.TotalItems = &total
+		page.CurrentPage = p.Page
+		page.TotalPages = &totalPages
+		return page, nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return nil, err
+	}
+	fields := list.orderFields()
+	sortKey := list.sortKey()
+	for i, field := range fields {
+		if !list.spec.Nullable[field.Name] {
+			continue
+		}
+		empty, err := emptyCursorValue(stmt, field)
+		if err != nil {
+			return nil, err
+		}
+		fields[i].empty = &empty
+	}
+
+	query := db.Session(&gorm.Session{})
+	backward := false
+	if p.cursor != nil {
+		if p.cursor.Sort != sortKey || len(p.cursor.Values) != len(fields) {
+			return nil, &QueryError{"cursor does not match the requested sort"}
+		}
+		backward = p.cursor.Prev
+		query = query.Where(keysetCondition(fields, p.cursor.Values, backward))
+	}
+
+	// Halaman sebelumnya diambil dengan urutan terbalik lalu dibalik lagi setelah query
+	order := make([]string, 0, len(fields))
+	keys := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		direction := "?"
+		if field.Desc != backward {
+			direction = "? DESC"
+		}
+		order = append(order, direction)
+		keys = append(keys, field.key())
+	}
+	orderBy := clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(order, ", "), Vars: keys}}
+	// Satu baris tambahan dipakai untuk mengetahui apakah masih ada halaman berikutnya
+	if err := query.Clauses(orderBy).Scopes(withPreloads).Limit(p.Limit + 1).Find(dest).Error; err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	hasMore := rows.Len() > p.Limit
+	if hasMore {
+		rows.Set(rows.Slice(0, p.Limit))
+	}
+	if backward {
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			first, last := rows.Index(i).Interface(), rows.Index(j).Interface()
+			rows.Index(i).Set(reflect.ValueOf(last))
+			rows.Index(j).Set(reflect.ValueOf(first))
+		}
+	}
+
+	page.Data = dest
+	if rows.Len() == 0 {
+		return page, nil
+	}
+
+	// Maju: ada halaman berikutnya jika baris tambahan terambil atau kita datang dari halaman berikutnya
+	if (!backward && hasMore) || (backward && p.cursor != nil) {
+		values, err := cursorValues(stmt, rows.Index(rows.Len()-1), fields)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = encodeCursor(&pageCursor{Sort: sortKey, Values: values})
+	}
+	if (backward && hasMore) || (!backward && p.cursor != nil) {
+		values, err := cursorValues(stmt, rows.Index(0), fields)
+		if err != nil {
+			return nil, err
+		}
+		page.PrevCursor = encodeCursor(&pageCursor{Sort: sortKey, Values: values, Prev: true})
+	}
+	return page, nil
+}
+
+// keysetCondition membuat kondisi "baris setelah cursor" untuk urutan multi-kolom:
+// (a > x) OR (a = x AND b > y) OR ..., dengan arah perbandingan mengikuti urutan tiap kolom
+func keysetCondition(fields []SortField, values []string, backward bool) clause.Expression {
+	alternatives := make([]clause.Expression, 0, len(fields))
+	for i, field := range fields {
+		exprs := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			exprs = append(exprs, clause.Expr{SQL: "? = ?", Vars: []interface{}{fields[j].key(), values[j]}})
+		}
+		if field.Desc != backward {
+			exprs = append(exprs, clause.Expr{SQL: "? < ?", Vars: []interface{}{field.key(), values[i]}})
+		} else {
+			exprs = append(exprs, clause.Expr{SQL: "? > ?", Vars: []interface{}{field.key(), values[i]}})
+		}
+		alternatives = append(alternatives, clause.And(exprs...))
+	}
+	// OR dengan satu kondisi akan digabung GORM sebagai "OR" ke kondisi lain, jadi dihindari
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return clause.Or(alternatives...)
+}
+
+// cursorValues mengambil nilai kolom urut dari satu baris sebagai teks
+func cursorValues(stmt *gorm.Statement, row reflect.Value, fields []SortField) ([]string, error) {
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		schemaField := stmt.Schema.LookUpField(field.Column)
+		if schemaField == nil {
+			return nil, fmt.Errorf("unknown sort column %q", field.Column)
+		}
+		value, zero := schemaField.ValueOf(context.Background(), row)
+		if zero && schemaField.FieldType.Kind() == reflect.Ptr {
+			if field.empty == nil {
+				return nil, fmt.Errorf("sort column %q is empty", field.Column)
+			}
+			values = append(values, *field.empty)
+			continue
+		}
+		values = append(values, cursorText(value))
+	}
+	return values, nil
+}
+
+// emptyCursorValue mengembalikan nilai cursor untuk NULL pada kolom Nullable, yaitu nilai nol
+// tipe field-nya. GORM membaca NULL sebagai nilai nol, sehingga baris NULL dan cursor-nya sama.
+func emptyCursorValue(stmt *gorm.Statement, field SortField) (string, error) {
+	schemaField := stmt.Schema.LookUpField(field.Column)
+	if schemaField == nil {
+		return "", fmt.Errorf("unknown sort column %q", field.Column)
+	}
+	fieldType := schemaField.FieldType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return cursorText(reflect.Zero(fieldType).Interface()), nil
+}
+
+// cursorText mengubah nilai kolom urut menjadi teks cursor
+func cursorText(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *time.Time:
+		return v.Format(time.RFC3339Nano)
+	case uuid.UUID:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// encodeCursor mengubah cursor menjadi token opaque yang aman dipakai di URL
+func encodeCursor(cursor *pageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor membaca token cursor dari query string
+func decodeCursor(token string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	cursor := new(pageCursor)
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// QueryError adalah kesalahan parameter list dari klien, bukan kesalahan server
+type QueryError struct {
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+// ListErrorResponse mengirim 400 INVALID_QUERY untuk parameter list yang tidak valid
+// dan 500 untuk kesalahan lainnya
+func ListErrorResponse(c *fiber.Ctx, err error) error {
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return ErrorResponseWithCode(c, fiber.StatusBadRequest, ErrCodeInvalidQuery, queryErr.Message)
+	}
+	return ErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+}
+
+// sortKeyOf menyusun representasi teks urutan untuk diikat ke cursor
+func sortKeyOf(fields []SortField) string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			names = append(names, "-"+field.Name)
+		} else {
+			names = append(names, field.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,258 @@
+package helpers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testListRow adalah model tabel list untuk uji pagination
+type testListRow struct {
+	ID         string
+	Title      string
+	CreatedAt  time.Time
+	ReturnDate *time.Time
+}
+
+// dryRunDB membuat koneksi GORM yang hanya menyusun SQL tanpa menghubungi database
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// parseTestList membaca ListQuery dan Pagination dari query string rawQuery
+func parseTestList(t *testing.T, rawQuery string) (*ListQuery, *Pagination, error) {
+	t.Helper()
+	var list *ListQuery
+	var p *Pagination
+	var err error
+	withQuery(t, rawQuery, func(c *fiber.Ctx) error {
+		if list, err = ParseListQuery(c, testListSpec); err == nil {
+			p, err = ParsePagination(c)
+		}
+		return nil
+	})
+	return list, p, err
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantMode  string
+		wantPage  int
+		wantLimit int
+		wantErr   bool
+	}{
+		{name: "offset defaults", wantMode: PaginationOffset, wantPage: 1, wantLimit: 10},
+		{name: "offset page", query: "page=3&limit=25", wantMode: PaginationOffset, wantPage: 3, wantLimit: 25},
+		{name: "limit capped", query: "limit=5000", wantMode: PaginationOffset, wantPage: 1, wantLimit: MaxPageLimit},
+		{name: "cursor first page", query: "pagination=cursor&limit=50", wantMode: PaginationCursor, wantLimit: 50},
+		{name: "zero limit", query: "limit=0", wantErr: true},
+		{name: "invalid page", query: "page=abc", wantErr: true},
+		{name: "invalid cursor", query: "cursor=not-a-cursor", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, p, err := parseTestList(t, tc.query)
+			if tc.wantErr {
+				var queryErr *QueryError
+				if !errors.As(err, &queryErr) {
+					t.Errorf("error = %v, want a QueryError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePagination() error = %v", err)
+			}
+			if p.Mode != tc.wantMode || p.Page != tc.wantPage || p.Limit != tc.wantLimit {
+				t.Errorf("got mode %q, page %d, limit %d", p.Mode, p.Page, p.Limit)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	db := dryRunDB(t)
+	list, _, err := parseTestList(t, "sort=title")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	rows := []testListRow{{ID: "a1", Title: "Dune", CreatedAt: createdAt}}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&rows); err != nil {
+		t.Fatal(err)
+	}
+	values, err := cursorValues(stmt, reflect.ValueOf(rows).Index(0), list.orderFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Dune", "a1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("cursorValues() = %v, want %v", values, want)
+	}
+
+	cursor := &pageCursor{Sort: list.sortKey(), Values: values, Prev: true}
+	decoded, err := decodeCursor(encodeCursor(cursor))
+	if err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, cursor) {
+		t.Errorf("decoded cursor = %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestPaginateCursorValidation(t *testing.T) {
+	titleCursor := encodeCursor(&pageCursor{Sort: "title,id", Values: []string{"Dune", "a1"}})
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "first page", query: "pagination=cursor&sort=title"},
+		{name: "cursor with the same sort", query: "sort=title&cursor=" + titleCursor},
+		{name: "cursor bound to another sort", query: "sort=-title&cursor=" + titleCursor, wantErr: true},
+		{name: "cursor bound to the default sort", query: "cursor=" + titleCursor, wantErr: true},
+		{name: "nullable sort column", query: "pagination=cursor&sort=return_date"},
+	}
+
+	db := dryRunDB(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			list, p, err := parseTestList(t, tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows []testListRow
+			_, err = Paginate(db.Model(&testListRow{}), list, p, &rows)
+			if tc.wantErr {
+				var queryErr *QueryError
+				if !errors.As(err, &queryErr) {
+					t.Errorf("error = %v, want a QueryError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Paginate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPaginateCursorWithNullSortKeys(t *testing.T) {
+	db := dryRunDB(t)
+	list, _, err := parseTestList(t, "sort=-return_date")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Baris dengan return_date NULL mendapat nilai cursor waktu nol, bukan error
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&[]testListRow{}); err != nil {
+		t.Fatal(err)
+	}
+	fields := list.orderFields()
+	empty, err := emptyCursorValue(stmt, fields[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields[0].empty = &empty
+	rows := []testListRow{{ID: "a1", Title: "Dune"}}
+	values, err := cursorValues(stmt, reflect.ValueOf(rows).Index(0), fields)
+	if err != nil {
+		t.Fatalf("cursorValues() error = %v", err)
+	}
+	if want := []string{"0001-01-01T00:00:00Z", "a1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("cursorValues() = %v, want %v", values, want)
+	}
+
+	// Halaman berikutnya membandingkan dan mengurutkan NULL sebagai waktu nol yang sama
+	token := encodeCursor(&pageCursor{Sort: list.sortKey(), Values: values})
+	list, p, err := parseTestList(t, "sort=-return_date&cursor="+token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotSQL string
+	if err := db.Callback().Query().After("gorm:query").Register("test:capture_sql", func(tx *gorm.DB) {
+		gotSQL = tx.Statement.SQL.String()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var page []testListRow
+	if _, err := Paginate(db.Model(&testListRow{}), list, p, &page); err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	wantSQL := `SELECT * FROM "test_list_rows" WHERE (COALESCE("return_date", $1) < $2 OR (COALESCE("return_date", $3) = $4 AND "id" > $5)) ` +
+		`ORDER BY COALESCE("return_date", $6) DESC, "id" LIMIT $7`
+	if gotSQL != wantSQL {
+		t.Errorf("SQL = %s, want %s", gotSQL, wantSQL)
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	byTitle := []SortField{{Name: "title", Column: "title"}, {Name: "id", Column: "id"}}
+	byTitleDesc := []SortField{{Name: "title", Column: "title", Desc: true}, {Name: "id", Column: "id"}}
+
+	tests := []struct {
+		name     string
+		fields   []SortField
+		backward bool
+		wantSQL  string
+	}{
+		{
+			// Baris dengan judul yang sama dengan cursor dilanjutkan lewat tie breaker id
+			name:    "ties on the sort column fall back to the tie breaker",
+			fields:  byTitle,
+			wantSQL: `("title" > $1 OR ("title" = $2 AND "id" > $3))`,
+		},
+		{
+			name:    "descending sort column",
+			fields:  byTitleDesc,
+			wantSQL: `("title" < $1 OR ("title" = $2 AND "id" > $3))`,
+		},
+		{
+			name:     "previous page reverses every comparison",
+			fields:   byTitleDesc,
+			backward: true,
+			wantSQL:  `("title" > $1 OR ("title" = $2 AND "id" < $3))`,
+		},
+		{
+			name:    "single column",
+			fields:  byTitle[1:],
+			wantSQL: `"id" > $1`,
+		},
+	}
+
+	db := dryRunDB(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values := []string{"Dune", "a1"}[2-len(tc.fields):]
+			var rows []testListRow
+			stmt := db.Model(&testListRow{}).Where(keysetCondition(tc.fields, values, tc.backward)).Find(&rows).Statement
+
+			wantSQL := `SELECT * FROM "test_list_rows" WHERE ` + tc.wantSQL
+			if got := stmt.SQL.String(); got != wantSQL {
+				t.Errorf("SQL = %s, want %s", got, wantSQL)
+			}
+			wantVars := []interface{}{"Dune", "Dune", "a1"}
+			if len(tc.fields) == 1 {
+				wantVars = []interface{}{"a1"}
+			}
+			if !reflect.DeepEqual(stmt.Vars, wantVars) {
+				t.Errorf("vars = %v, want %v", stmt.Vars, wantVars)
+			}
+		})
+	}
+}
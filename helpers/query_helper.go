@@ -0,0 +1,274 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operator filter yang didukung ListSpec
+const (
+	FilterEquals     = "eq"       // sama persis
+	FilterUUID       = "uuid"     // sama persis dengan kolom uuid, nilai harus UUID yang valid
+	FilterIEquals    = "ieq"      // sama tanpa membedakan huruf besar/kecil
+	FilterContains   = "contains" // mengandung teks, tanpa membedakan huruf besar/kecil
+	FilterDateFrom   = "date_from"
+	FilterDateTo     = "date_to"
+	filterDateLayout = "2006-01-02"
+)
+
+// FilterSpec memetakan parameter query ke kolom dan operator filternya
+type FilterSpec struct {
+	Column   string
+	Operator string
+}
+
+// ListSpec adalah daftar putih filter, urutan dan field yang boleh dipakai sebuah endpoint list.
+// Sortable dan Fields memakai nama field API sebagai kunci; Fields bernilai kunci JSON di respons.
+type ListSpec struct {
+	Filters     map[string]FilterSpec
+	Sortable    map[string]string
+	Fields      map[string]string
+	DefaultSort string
+	// TieBreaker adalah kolom unik yang selalu ditambahkan di akhir urutan supaya hasil stabil
+	TieBreaker string
+	// Params adalah parameter query lain yang dibaca endpoint sendiri, misalnya ?status=
+	Params []string
+	// Nullable berisi field sort yang kolomnya bisa NULL. Pagination cursor mengurutkan NULL
+	// sebagai nilai kosong tipe field-nya (teks kosong, waktu nol) supaya cursor tetap cocok.
+	Nullable map[string]bool
+}
+
+// SortField adalah satu kolom pengurutan hasil parsing ?sort=
+type SortField struct {
+	Name   string
+	Column string
+	Desc   bool
+	// empty diisi Paginate untuk kolom Nullable: nilai cursor pengganti NULL
+	empty *string
+}
+
+// key mengembalikan ekspresi kunci urut kolom, dengan NULL diganti empty untuk kolom Nullable
+func (f SortField) key() clause.Expression {
+	column := clause.Column{Name: f.Column}
+	if f.empty == nil {
+		return clause.Expr{SQL: "?", Vars: []interface{}{column}}
+	}
+	return clause.Expr{SQL: "COALESCE(?, ?)", Vars: []interface{}{column, *f.empty}}
+}
+
+// ListQuery adalah hasil parsing ?filter, ?sort= dan ?fields= yang sudah divalidasi
+type ListQuery struct {
+	conditions []clause.Expression
+	Sort       []SortField
+	fieldKeys  []string
+	spec       ListSpec
+}
+
+// listParams adalah parameter query yang diterima setiap endpoint list selain filter
+var listParams = []string{"sort", "fields", "page", "limit", "cursor", "pagination"}
+
+// ParseListQuery membaca filter, sort dan fields dari query string sesuai ListSpec.
+// Nama field atau parameter yang tidak ada di daftar putih menghasilkan error yang bisa
+// ditampilkan ke klien, supaya filter yang salah ketik tidak diam-diam diabaikan.
+func ParseListQuery(c *fiber.Ctx, spec ListSpec) (*ListQuery, error) {
+	q := &ListQuery{spec: spec}
+
+	params := make([]string, 0, len(spec.Filters))
+	for param := range spec.Filters {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	if err := checkQueryParams(c, spec, params); err != nil {
+		return nil, err
+	}
+	for _, param := range params {
+		value := strings.TrimSpace(c.Query(param))
+		if value == "" {
+			continue
+		}
+		condition, err := filterCondition(spec.Filters[param], param, value)
+		if err != nil {
+			return nil, err
+		}
+		q.conditions = append(q.conditions, condition)
+	}
+
+	sortParam := c.Query("sort", spec.DefaultSort)
+	for _, name := range splitList(sortParam) {
+		field := SortField{Name: name}
+		if strings.HasPrefix(name, "-") {
+			field.Desc = true
+			field.Name = strings.TrimPrefix(name, "-")
+		}
+		column, ok := spec.Sortable[field.Name]
+		if !ok {
+			return nil, &QueryError{fmt.Sprintf("cannot sort by %q, allowed fields: %s", field.Name, allowedNames(spec.Sortable))}
+		}
+		field.Column = column
+		q.Sort = append(q.Sort, field)
+	}
+
+	for _, name := range splitList(c.Query("fields")) {
+		key, ok := spec.Fields[name]
+		if !ok {
+			return nil, &QueryError{fmt.Sprintf("unknown field %q, allowed fields: %s", name, allowedNames(spec.Fields))}
+		}
+		q.fieldKeys = append(q.fieldKeys, key)
+	}
+
+	return q, nil
+}
+
+// Filter menerapkan kondisi filter ke query, dipakai juga untuk query Count
+func (q *ListQuery) Filter(db *gorm.DB) *gorm.DB {
+	if len(q.conditions) == 0 {
+		return db
+	}
+	return db.Clauses(clause.Where{Exprs: q.conditions})
+}
+
+// Order menerapkan urutan hasil ke query, diakhiri TieBreaker supaya urutan deterministik
+func (q *ListQuery) Order(db *gorm.DB) *gorm.DB {
+	fields := q.orderFields()
+	if len(fields) == 0 {
+		return db
+	}
+	columns := make([]clause.OrderByColumn, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc})
+	}
+	return db.Clauses(clause.OrderBy{Columns: columns})
+}
+
+// orderFields mengembalikan kolom urut yang diminta ditambah TieBreaker
+func (q *ListQuery) orderFields() []SortField {
+	fields := make([]SortField, 0, len(q.Sort)+1)
+	fields = append(fields, q.Sort...)
+	if q.spec.TieBreaker != "" {
+		fields = append(fields, SortField{Name: q.spec.TieBreaker, Column: q.spec.TieBreaker})
+	}
+	return fields
+}
+
+// sortKey adalah representasi teks urutan yang diikat ke token cursor
+func (q *ListQuery) sortKey() string {
+	return sortKeyOf(q.orderFields())
+}
+
+// Project membuang field yang tidak diminta lewat ?fields= dari data list.
+// Tanpa ?fields= data dikembalikan apa adanya.
+func (q *ListQuery) Project(data interface{}) (interface{}, error) {
+	if len(q.fieldKeys) == 0 {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+
+	projected := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]interface{}, len(q.fieldKeys))
+		for _, key := range q.fieldKeys {
+			if value, ok := item[key]; ok {
+				row[key] = value
+			}
+		}
+		projected = append(projected, row)
+	}
+	return projected, nil
+}
+
+// checkQueryParams menolak parameter query yang bukan filter spec, parameter list umum atau
+// spec.Params. filters adalah nama filter spec yang sudah diurutkan.
+func checkQueryParams(c *fiber.Ctx, spec ListSpec, filters []string) error {
+	allowed := map[string]bool{}
+	for _, names := range [][]string{filters, listParams, spec.Params} {
+		for _, name := range names {
+			allowed[name] = true
+		}
+	}
+	var unknown []string
+	for param := range c.Queries() {
+		if !allowed[param] {
+			unknown = append(unknown, param)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	accepted := append(append([]string{}, filters...), spec.Params...)
+	sort.Strings(accepted)
+	return &QueryError{fmt.Sprintf("unknown query parameter %q, allowed filters: %s", unknown[0], strings.Join(accepted, ", "))}
+}
+
+// filterCondition membuat kondisi WHERE untuk satu parameter filter
+func filterCondition(spec FilterSpec, param string, value string) (clause.Expression, error) {
+	column := clause.Column{Name: spec.Column}
+	switch spec.Operator {
+	case FilterEquals:
+		return clause.Eq{Column: column, Value: value}, nil
+	case FilterUUID:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, &QueryError{param + " must be a valid UUID"}
+		}
+		return clause.Eq{Column: column, Value: id}, nil
+	case FilterIEquals:
+		return clause.Expr{SQL: "LOWER(?) = LOWER(?)", Vars: []interface{}{column, value}}, nil
+	case FilterContains:
+		return clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{column, "%" + escapeLike(value) + "%"}}, nil
+	case FilterDateFrom, FilterDateTo:
+		date, err := time.ParseInLocation(filterDateLayout, value, time.Local)
+		if err != nil {
+			return nil, &QueryError{param + " must be a date in YYYY-MM-DD format"}
+		}
+		if spec.Operator == FilterDateFrom {
+			return clause.Gte{Column: column, Value: date}, nil
+		}
+		// Tanggal akhir bersifat inklusif, sehingga dibandingkan dengan awal hari berikutnya
+		return clause.Lt{Column: column, Value: date.AddDate(0, 0, 1)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter operator %q", spec.Operator)
+	}
+}
+
+// escapeLike meng-escape karakter wildcard LIKE supaya dicari sebagai teks biasa
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// splitList memecah nilai dipisah koma dan membuang elemen kosong
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// allowedNames mengurutkan nama yang diizinkan untuk pesan error
+func allowedNames(names map[string]string) string {
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testListSpec meniru daftar putih endpoint buku
+var testListSpec = ListSpec{
+	Filters: map[string]FilterSpec{
+		"title":      {Column: "title", Operator: FilterContains},
+		"created_to": {Column: "created_at", Operator: FilterDateTo},
+		"book_id":    {Column: "book_id", Operator: FilterUUID},
+	},
+	Sortable:    map[string]string{"title": "title", "created_at": "created_at", "return_date": "return_date"},
+	Fields:      map[string]string{"id": "id", "title": "title", "author": "author"},
+	DefaultSort: "-created_at",
+	TieBreaker:  "id",
+	Params:      []string{"status"},
+	Nullable:    map[string]bool{"return_date": true},
+}
+
+// withQuery menjalankan fn dengan fiber.Ctx dari request GET berisi query string rawQuery
+func withQuery(t *testing.T, rawQuery string, fn func(c *fiber.Ctx) error) {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/", fn)
+	res, err := app.Test(httptest.NewRequest("GET", "/?"+rawQuery, nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+}
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantSort   []SortField
+		wantFields []string
+		wantErr    bool
+	}{
+		{
+			name:     "default sort",
+			wantSort: []SortField{{Name: "created_at", Column: "created_at", Desc: true}},
+		},
+		{
+			name:     "ascending and descending sort",
+			query:    "sort=title,-created_at",
+			wantSort: []SortField{{Name: "title", Column: "title"}, {Name: "created_at", Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "field selection",
+			query:      "fields=title,%20id",
+			wantSort:   []SortField{{Name: "created_at", Column: "created_at", Desc: true}},
+			wantFields: []string{"title", "id"},
+		},
+		{name: "sort outside whitelist", query: "sort=password", wantErr: true},
+		{name: "descending sort outside whitelist", query: "sort=-password", wantErr: true},
+		{name: "field outside whitelist", query: "fields=title,password", wantErr: true},
+		{name: "invalid date filter", query: "created_to=31-12-2024", wantErr: true},
+		{name: "invalid uuid filter", query: "book_id=abc", wantErr: true},
+		{
+			name:     "list and endpoint params",
+			query:    "status=overdue&page=2&limit=5&pagination=cursor&cursor=&fields=",
+			wantSort: []SortField{{Name: "created_at", Column: "created_at", Desc: true}},
+		},
+		{name: "unknown param", query: "titel=dune", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			withQuery(t, tc.query, func(c *fiber.Ctx) error {
+				list, err := ParseListQuery(c, testListSpec)
+				if tc.wantErr {
+					var queryErr *QueryError
+					if !errors.As(err, &queryErr) {
+						t.Errorf("error = %v, want a QueryError", err)
+					}
+					return nil
+				}
+				if err != nil {
+					t.Fatalf("ParseListQuery() error = %v", err)
+				}
+				if !reflect.DeepEqual(list.Sort, tc.wantSort) {
+					t.Errorf("Sort = %+v, want %+v", list.Sort, tc.wantSort)
+				}
+				if !reflect.DeepEqual(list.fieldKeys, tc.wantFields) {
+					t.Errorf("fields = %v, want %v", list.fieldKeys, tc.wantFields)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestParseListQueryUnknownParam(t *testing.T) {
+	withQuery(t, "title=dune&categroy=fiksi", func(c *fiber.Ctx) error {
+		_, err := ParseListQuery(c, testListSpec)
+		want := `unknown query parameter "categroy", allowed filters: book_id, created_to, status, title`
+		if err == nil || err.Error() != want {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+		return nil
+	})
+}
+
+func TestListQueryProject(t *testing.T) {
+	rows := []struct {
+		ID     string `json:"id"`
+		Title  string `json:"title"`
+		Author string `json:"author"`
+	}{{ID: "1", Title: "Dune", Author: "Frank Herbert"}}
+
+	withQuery(t, "fields=title", func(c *fiber.Ctx) error {
+		list, err := ParseListQuery(c, testListSpec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		projected, err := list.Project(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{{"title": "Dune"}}
+		if !reflect.DeepEqual(projected, want) {
+			t.Errorf("Project() = %v, want %v", projected, want)
+		}
+		return nil
+	})
+}
@@ -4,10 +4,11 @@ import "github.com/gofiber/fiber/v2"
 
 // APIResponse adalah struktur untuk respons API yang konsisten
 type APIResponse struct {
-	Code    int         `json:"code"`
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int         `json:"code"`
+	Success   bool        `json:"success"`
+	Message   string      `json:"message"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
 }
 
 // SuccessResponse mengirimkan respons sukses
@@ -29,3 +30,27 @@ func ErrorResponse(c *fiber.Ctx, statusCode int, message string) error {
 		Data:    nil,
 	})
 }
+
+// ErrorResponseWithCode mengirimkan respons error beserta kode error yang bisa dibaca mesin,
+// sehingga frontend dapat membedakan jenis kegagalan tanpa mengurai pesan
+func ErrorResponseWithCode(c *fiber.Ctx, statusCode int, errorCode string, message string) error {
+	return c.Status(statusCode).JSON(APIResponse{
+		Code:      statusCode,
+		Success:   false,
+		Message:   message,
+		ErrorCode: errorCode,
+		Data:      nil,
+	})
+}
+
+// ErrorResponseWithData mengirimkan respons error beserta kode error dan data pendukung,
+// misalnya data yang sudah ada saat terjadi duplikasi
+func ErrorResponseWithData(c *fiber.Ctx, statusCode int, errorCode string, message string, data interface{}) error {
+	return c.Status(statusCode).JSON(APIResponse{
+		Code:      statusCode,
+		Success:   false,
+		Message:   message,
+		ErrorCode: errorCode,
+		Data:      data,
+	})
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// running berisi channel milik setiap job yang ditutup ketika job berhenti,
+// supaya Wait bisa menunggu semuanya
+var (
+	runningMu sync.Mutex
+	running   []chan struct{}
+)
+
+// Every menjalankan fn secara berkala di goroutine terpisah sampai ctx dibatalkan.
+// Error dari fn hanya dicatat ke log agar job tetap berjalan pada interval berikutnya.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	if interval <= 0 {
+		log.Printf("Job %s disabled, interval must be positive", name)
+		return
+	}
+
+	stopped := make(chan struct{})
+	runningMu.Lock()
+	running = append(running, stopped)
+	runningMu.Unlock()
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("Job %s stopped", name)
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil {
+					log.Printf("Job %s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Wait menunggu semua job yang dijalankan Every berhenti setelah ctx-nya dibatalkan,
+// termasuk menyelesaikan putaran yang sedang berjalan, paling lama selama timeout.
+// Mengembalikan false jika masih ada job yang berjalan saat timeout habis.
+func Wait(timeout time.Duration) bool {
+	runningMu.Lock()
+	pending := running
+	runningMu.Unlock()
+
+	deadline := time.After(timeout)
+	for _, stopped := range pending {
+		select {
+		case <-stopped:
+		case <-deadline:
+			return false
+		}
+	}
+
+	// Job yang sudah berhenti tidak perlu ditunggu lagi
+	runningMu.Lock()
+	defer runningMu.Unlock()
+	active := running[:0]
+	for _, stopped := range running {
+		select {
+		case <-stopped:
+		default:
+			active = append(active, stopped)
+		}
+	}
+	running = active
+	return true
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitFinishesRunningJob(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{}, 1)
+	var finished atomic.Bool
+
+	Every(ctx, "slow", time.Millisecond, func(context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		// Putaran yang sedang berjalan tetap diselesaikan walaupun ctx sudah dibatalkan
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+
+	<-started
+	cancel()
+	if !Wait(time.Second) {
+		t.Fatal("Wait() = false, want the job to stop before the timeout")
+	}
+	if !finished.Load() {
+		t.Error("Wait returned before the running round finished")
+	}
+}
+
+func TestWaitTimesOut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+
+	Every(ctx, "stuck", time.Millisecond, func(context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	<-started
+	cancel()
+	if Wait(10 * time.Millisecond) {
+		t.Error("Wait() = true while a job is still running")
+	}
+	close(release)
+	if !Wait(time.Second) {
+		t.Error("Wait() = false after the job was released")
+	}
+}
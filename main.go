@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
 	"library/config"   // SESUAIKAN DENGAN NAMA MODUL GO ANDA
 	"library/database" // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/jobs"     // SESUAIKAN DENGAN NAMA MODUL GO ANDA
 	"library/routes"   // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/services" // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/storage"  // SESUAIKAN DENGAN NAMA MODUL GO ANDA
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -13,14 +21,32 @@ import (
 )
 
 func main() {
-	// Muat konfigurasi aplikasi
-	cfg := config.LoadConfig()
+	// Muat konfigurasi aplikasi sekali, lalu diteruskan ke semua komponen
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Konfigurasi divalidasi sebelum server atau subcommand berjalan, kecuali subcommand
+	// config yang justru dipakai untuk memeriksa konfigurasi
+	args := os.Args[1:]
+	if len(args) == 0 || args[0] != "config" {
+		validateConfig(cfg)
+	}
+
+	// Subcommand CLI, misalnya: go run . import-books -file katalog.csv
+	if len(args) > 0 {
+		os.Exit(runCommand(cfg, args))
+	}
 
 	// Inisialisasi koneksi database
 	database.InitDatabase(cfg)
 
-	// Buat instance aplikasi Fiber
-	app := fiber.New()
+	// Inisialisasi penyimpanan file (cover buku)
+	store := storage.InitStorage(cfg)
+
+	// Buat instance aplikasi Fiber. Batas body dinaikkan untuk unggahan cover dan file impor.
+	app := fiber.New(fiber.Config{BodyLimit: 10 * 1024 * 1024})
 
 	// Middleware Global
 	app.Use(logger.New()) // Logging setiap permintaan ke konsol
@@ -28,8 +54,59 @@ func main() {
 	app.Use(helmet.New()) // Opsional: Menambahkan berbagai security HTTP headers
 
 	// Setup semua rute API
-	routes.SetupRoutes(app)
+	routes.SetupRoutes(app, cfg, store)
+
+	// Background job: hold yang tidak diambil sampai batas waktu diteruskan ke antrean berikutnya.
+	// Job berhenti lewat jobsCtx saat server dihentikan.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	circulation := services.NewCirculationService(database.DBClient, cfg)
+	jobs.Every(jobsCtx, "expire-holds", time.Duration(cfg.HoldSweepMinutes)*time.Minute, circulation.ExpireReadyHolds)
+
+	// Jalankan server Fiber di port yang ditentukan dalam konfigurasi sampai menerima SIGINT/SIGTERM
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.Port)
+	}()
+	select {
+	case err := <-listenErr:
+		log.Fatalf("Server stopped: %v", err)
+	case <-signals.Done():
+	}
+	// Sinyal kedua menghentikan proses langsung tanpa menunggu shutdown selesai
+	stopSignals()
+
+	shutdown(app, stopJobs, cfg.ShutdownTimeout)
+}
+
+// shutdown menghentikan server secara berurutan: menunggu request yang sedang berjalan selesai
+// (paling lama timeout), menghentikan background job, lalu menutup pool koneksi database
+func shutdown(app *fiber.App, stopJobs context.CancelFunc, timeout time.Duration) {
+	log.Printf("Shutting down, waiting up to %s for in-flight requests", timeout)
+	if err := app.ShutdownWithTimeout(timeout); err != nil {
+		log.Printf("Server did not shut down cleanly: %v", err)
+	}
+
+	stopJobs()
+	if !jobs.Wait(timeout) {
+		log.Println("Background jobs did not stop in time")
+	}
+
+	if err := database.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
+	log.Println("Server stopped")
+}
 
-	// Jalankan server Fiber di port yang ditentukan dalam konfigurasi
-	log.Fatal(app.Listen(":" + cfg.Port))
+// validateConfig menghentikan proses jika konfigurasi tidak valid, misalnya secret default
+// di production, dan mencatat peringatan untuk development
+func validateConfig(cfg *config.Config) {
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	for _, warning := range cfg.Warnings() {
+		log.Printf("Warning: %s", warning)
+	}
 }
@@ -1,19 +1,34 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
-	"library/config"  // Sesuaikan dengan nama modulmu
-	"library/helpers" // Sesuaikan dengan nama modulmu
+	"library/config"       // Sesuaikan dengan nama modulmu
+	"library/helpers"      // Sesuaikan dengan nama modulmu
+	"library/models"       // Sesuaikan dengan nama modulmu
+	"library/repositories" // Sesuaikan dengan nama modulmu
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
-// AuthRequired adalah middleware untuk memverifikasi token JWT (Access Token)
-func AuthRequired(c *fiber.Ctx) error {
+// AuthRequired membuat middleware untuk memverifikasi token JWT (Access Token)
+// dengan secret dari konfigurasi yang dimuat saat startup
+func AuthRequired(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return verifyAccessToken(c, cfg)
+	}
+}
+
+// verifyAccessToken memverifikasi Access Token di header Authorization lalu menyimpan
+// user ID dan role ke c.Locals
+func verifyAccessToken(c *fiber.Ctx, cfg *config.Config) error {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Authorization header required")
@@ -25,7 +40,6 @@ func AuthRequired(c *fiber.Ctx) error {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Bearer token not found")
 	}
 
-	cfg := config.LoadConfig()
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Pastikan metode penandatanganan adalah HMAC
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -54,8 +68,18 @@ func AuthRequired(c *fiber.Ctx) error {
 			return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format in token")
 		}
 
+		// Token lama belum membawa claim role, anggap sebagai member
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = models.RoleMember
+		}
+		if !models.IsValidRole(role) {
+			return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid role in token claims")
+		}
+
 		// Simpan ke context
 		c.Locals("userID", userIDStr)
+		c.Locals("role", role)
 		return c.Next()
 	} else {
 		return helpers.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid token claims")
@@ -63,10 +87,11 @@ func AuthRequired(c *fiber.Ctx) error {
 }
 
 // GenerateAccessToken menghasilkan Access Token JWT
-func GenerateAccessToken(userID uuid.UUID, cfg *config.Config) (string, error) {
+func GenerateAccessToken(userID uuid.UUID, role string, cfg *config.Config) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID.String(),                                      // Pastikan UUID dikonversi string
-		"exp":     jwt.NewNumericDate(time.Now().Add(time.Minute * 30)), // Expired 30 menit
+		"user_id": userID.String(),                                        // Pastikan UUID dikonversi string
+		"role":    role,                                                   // Role dipakai oleh RequireRoles
+		"exp":     jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)), // Masa berlaku ACCESS_TOKEN_TTL
 	})
 	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
 	if err != nil {
@@ -75,15 +100,69 @@ func GenerateAccessToken(userID uuid.UUID, cfg *config.Config) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateRefreshToken menghasilkan Refresh Token JWT
-func GenerateRefreshToken(userID uuid.UUID, cfg *config.Config) (string, error) {
+// GenerateRefreshToken menghasilkan Refresh Token JWT beserta waktu kedaluwarsanya (REFRESH_TOKEN_TTL).
+// Claim jti membuat setiap token unik sehingga hash-nya bisa disimpan di database.
+func GenerateRefreshToken(userID uuid.UUID, cfg *config.Config) (string, time.Time, error) {
+	expiresAt := time.Now().Add(cfg.RefreshTokenTTL)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID.String(),                                        // Pastikan UUID dikonversi string
-		"exp":     jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // Expired 7 hari
+		"user_id": userID.String(), // Pastikan UUID dikonversi string
+		"jti":     uuid.NewString(),
+		"exp":     jwt.NewNumericDate(expiresAt),
 	})
 	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
+	}
+	return tokenString, expiresAt, nil
+}
+
+// HashToken menghasilkan hash SHA-256 dari token, hanya hash ini yang disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BasicOrBearerAuth menerima Access Token seperti AuthRequired atau HTTP Basic berisi email dan
+// password, karena aplikasi e-reader umumnya hanya mendukung Basic. Tanpa kredensial, respons
+// 401 membawa WWW-Authenticate supaya aplikasi meminta login. Pengguna Basic dicari lewat users.
+func BasicOrBearerAuth(realm string, cfg *config.Config, users repositories.UserRepository) fiber.Handler {
+	challenge := fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, realm)
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			return verifyAccessToken(c, cfg)
+		}
+
+		unauthorized := func(message string) error {
+			c.Set(fiber.HeaderWWWAuthenticate, challenge)
+			return helpers.ErrorResponse(c, fiber.StatusUnauthorized, message)
+		}
+		if !strings.HasPrefix(authHeader, "Basic ") {
+			return unauthorized("Authorization header required")
+		}
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(authHeader, "Basic "))
+		if err != nil {
+			return unauthorized("Invalid basic credentials")
+		}
+		email, password, ok := strings.Cut(string(raw), ":")
+		if !ok {
+			return unauthorized("Invalid basic credentials")
+		}
+
+		user, err := users.FindByEmail(c.UserContext(), email)
+		if err != nil {
+			return unauthorized("Invalid credentials")
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+			return unauthorized("Invalid credentials")
+		}
+
+		role := user.Role
+		if role == "" {
+			role = models.RoleMember
+		}
+		c.Locals("userID", user.ID.String())
+		c.Locals("role", role)
+		return c.Next()
 	}
-	return tokenString, nil
 }
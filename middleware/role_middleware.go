@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"library/helpers"
+	"library/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRoles membatasi akses rute hanya untuk role yang diizinkan.
+// Harus dipasang setelah AuthRequired karena membaca role dari c.Locals.
+func RequireRoles(roles ...string) fiber.Handler {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !allowed[CurrentRole(c)] {
+			return helpers.ErrorResponse(c, fiber.StatusForbidden, "You do not have permission to access this resource")
+		}
+		return c.Next()
+	}
+}
+
+// StaffOnly mengizinkan admin dan librarian
+func StaffOnly() fiber.Handler {
+	return RequireRoles(models.RoleAdmin, models.RoleLibrarian)
+}
+
+// AdminOnly hanya mengizinkan admin
+func AdminOnly() fiber.Handler {
+	return RequireRoles(models.RoleAdmin)
+}
+
+// CurrentRole mengambil role pengguna yang sedang login dari context
+func CurrentRole(c *fiber.Ctx) string {
+	role, _ := c.Locals("role").(string)
+	return role
+}
+
+// IsStaff memeriksa apakah pengguna yang sedang login adalah petugas
+func IsStaff(c *fiber.Ctx) bool {
+	return models.IsStaffRole(CurrentRole(c))
+}
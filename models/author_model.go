@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Author adalah penulis yang bisa dihubungkan ke banyak buku. Nama penulis unik tanpa
+// membedakan huruf besar/kecil (index unik LOWER(name) dibuat saat migrasi).
+type Author struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name      string    `json:"name" gorm:"not null"`
+	Books     []Book    `json:"books,omitempty" gorm:"many2many:book_authors"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (a *Author) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status eksemplar fisik
+const (
+	CopyStatusAvailable = "available"
+	CopyStatusOnLoan    = "on_loan"
+	CopyStatusOnHold    = "on_hold"
+	CopyStatusLost      = "lost"
+	CopyStatusDamaged   = "damaged"
+	CopyStatusWithdrawn = "withdrawn"
+)
+
+// Kondisi fisik eksemplar
+const (
+	CopyConditionNew  = "new"
+	CopyConditionGood = "good"
+	CopyConditionFair = "fair"
+	CopyConditionPoor = "poor"
+)
+
+// BookCopy adalah satu eksemplar fisik sebuah buku yang diberi label barcode
+type BookCopy struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	BookID        uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index"`
+	Barcode       string     `json:"barcode" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ShelfLocation string     `json:"shelf_location"`
+	Condition     string     `json:"condition" gorm:"type:varchar(20);not null;default:good"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;default:available;index"`
+	AcquiredAt    *time.Time `json:"acquired_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Book *Book `json:"book,omitempty" gorm:"foreignKey:BookID;references:ID"`
+}
+
+func (b *BookCopy) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	if b.Status == "" {
+		b.Status = CopyStatusAvailable
+	}
+	if b.Condition == "" {
+		b.Condition = CopyConditionGood
+	}
+	return
+}
+
+// IsValidCopyCondition memeriksa apakah kondisi termasuk kondisi yang dikenali
+func IsValidCopyCondition(condition string) bool {
+	switch condition {
+	case CopyConditionNew, CopyConditionGood, CopyConditionFair, CopyConditionPoor:
+		return true
+	}
+	return false
+}
+
+// IsManualCopyStatus memeriksa status yang boleh diatur langsung oleh staff.
+// Status on_loan, on_hold dan lost hanya diatur oleh alur sirkulasi.
+func IsManualCopyStatus(status string) bool {
+	switch status {
+	case CopyStatusAvailable, CopyStatusDamaged, CopyStatusWithdrawn:
+		return true
+	}
+	return false
+}
+
+// CountsAsHolding memeriksa apakah eksemplar masih termasuk koleksi (dihitung dalam quantity buku)
+func (b *BookCopy) CountsAsHolding() bool {
+	return b.Status != CopyStatusLost && b.Status != CopyStatusWithdrawn
+}
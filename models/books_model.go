@@ -1,19 +1,52 @@
 package models
 
 import (
+	"fmt"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// CoverURLPath adalah prefix rute publik yang menyajikan gambar cover
+const CoverURLPath = "/api/v1/covers"
+
+// CoverSize adalah satu ukuran thumbnail cover, lebar dalam piksel
+type CoverSize struct {
+	Name  string
+	Width int
+}
+
+// CoverSizes adalah ukuran cover yang dibuat setiap kali cover diunggah
+var CoverSizes = []CoverSize{
+	{Name: "small", Width: 150},
+	{Name: "medium", Width: 300},
+	{Name: "large", Width: 600},
+}
+
 // User merepresentasikan model pengguna
 type Book struct {
 	gorm.Model
-	ID       uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Title    string    `json:"title"`
-	Author   string    `json:"author"`
-	Isbn     string    `json:"isbn" gorm:"unique"`
-	Quantity string    `json:"quantity"`
-	Category string    `json:"category"`
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Title           string    `json:"title"`
+	Author          string    `json:"author"`
+	Isbn            string    `json:"isbn" gorm:"unique"`
+	Quantity        int       `json:"quantity" gorm:"type:integer;not null;default:0;check:quantity >= 0"` // Jumlah eksemplar dalam koleksi, dihitung ulang dari book_copies
+	Category        string    `json:"category"`
+	ReplacementCost int64     `json:"replacement_cost" gorm:"not null;default:0"` // Biaya penggantian (Rupiah) jika buku hilang
+	// CoverVersion adalah hash gambar cover terakhir, kosong jika buku belum punya cover
+	CoverVersion string `json:"-" gorm:"type:varchar(32);not null;default:''"`
+
+	// Authors dan Categories adalah penulis dan kategori yang terhubung ke buku. Kolom Author
+	// (nama penulis dipisah "; ") dan Category (nama kategori pertama) diisi dari keduanya
+	// supaya pencarian, filter, export dan aturan per kategori tetap memakai teks.
+	Authors    []Author   `json:"authors,omitempty" gorm:"many2many:book_authors"`
+	Categories []Category `json:"categories,omitempty" gorm:"many2many:book_categories"`
+
+	// Covers berisi URL cover per ukuran, diisi dari CoverVersion setelah buku dibaca
+	Covers map[string]string `json:"covers,omitempty" gorm:"-"`
+
+	// AvailableCopies diisi dari status eksemplar saat dibutuhkan, tidak disimpan di tabel books
+	AvailableCopies *int `json:"available_copies,omitempty" gorm:"-"`
 }
 
 func (u *Book) BeforeCreate(tx *gorm.DB) (err error) {
@@ -23,3 +56,27 @@ func (u *Book) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// AfterFind mengisi URL cover untuk buku yang punya cover
+func (u *Book) AfterFind(tx *gorm.DB) (err error) {
+	u.SetCoverURLs()
+	return
+}
+
+// SetCoverURLs mengisi Covers dari CoverVersion. URL berubah setiap cover diganti,
+// sehingga gambarnya boleh di-cache tanpa batas waktu.
+func (u *Book) SetCoverURLs() {
+	if u.CoverVersion == "" {
+		u.Covers = nil
+		return
+	}
+	u.Covers = make(map[string]string, len(CoverSizes))
+	for _, size := range CoverSizes {
+		u.Covers[size.Name] = fmt.Sprintf("%s/%s/%s/%s.jpg", CoverURLPath, u.ID, u.CoverVersion, size.Name)
+	}
+}
+
+// CoverKey adalah key storage untuk cover sebuah buku pada versi dan ukuran tertentu
+func CoverKey(bookID uuid.UUID, version string, size string) string {
+	return fmt.Sprintf("covers/%s/%s/%s.jpg", bookID, version, size)
+}
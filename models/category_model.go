@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Category adalah kategori buku yang tersusun sebagai pohon: kategori tanpa ParentID adalah
+// kategori utama. Nama kategori unik tanpa membedakan huruf besar/kecil.
+type Category struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name      string     `json:"name" gorm:"not null"`
+	ParentID  *uuid.UUID `json:"parent_id" gorm:"type:uuid;index"`
+	Children  []Category `json:"children,omitempty" gorm:"foreignKey:ParentID;constraint:OnDelete:RESTRICT"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return
+}
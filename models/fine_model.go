@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis transaksi denda. Tagihan bernilai positif, pembayaran dan pembebasan bernilai negatif.
+const (
+	FineTypeOverdue    = "overdue"
+	FineTypeLostItem   = "lost_item"
+	FineTypePayment    = "payment"
+	FineTypeWaiver     = "waiver"
+	FineTypeAdjustment = "adjustment"
+)
+
+// FineTransaction adalah satu baris buku besar denda anggota.
+// Saldo anggota adalah jumlah seluruh Amount miliknya.
+type FineTransaction struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	RecordID  *uuid.UUID `json:"record_id,omitempty" gorm:"type:uuid;index"`
+	Type      string     `json:"type" gorm:"type:varchar(20);not null"`
+	Amount    int64      `json:"amount" gorm:"not null"`
+	Note      string     `json:"note"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (f *FineTransaction) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return
+}
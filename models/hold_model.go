@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status reservasi (hold)
+const (
+	HoldStatusWaiting   = "waiting"
+	HoldStatusReady     = "ready"
+	HoldStatusFulfilled = "fulfilled"
+	HoldStatusCancelled = "cancelled"
+	HoldStatusExpired   = "expired"
+)
+
+// Hold adalah antrean reservasi anggota untuk sebuah judul buku.
+// Antrean diproses FIFO berdasarkan CreatedAt; saat eksemplar tersedia, hold terdepan
+// berubah menjadi ready dan eksemplar (CopyID) disisihkan sampai ExpiresAt.
+type Hold struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	BookID    uuid.UUID  `json:"book_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Status    string     `json:"status" gorm:"type:varchar(20);not null;default:waiting;index"`
+	CopyID    *uuid.UUID `json:"copy_id,omitempty" gorm:"type:uuid"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	Book Book `json:"book,omitempty" gorm:"foreignKey:BookID;references:ID"`
+	User User `json:"-" gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (h *Hold) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	if h.Status == "" {
+		h.Status = HoldStatusWaiting
+	}
+	return
+}
+
+// IsActive memeriksa apakah hold masih berada di antrean atau menunggu diambil
+func (h *Hold) IsActive() bool {
+	return h.Status == HoldStatusWaiting || h.Status == HoldStatusReady
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kode tier keanggotaan bawaan
+const (
+	TierStudent = "student"
+	TierStaff   = "staff"
+	TierPublic  = "public"
+)
+
+// MembershipTier menentukan aturan peminjaman untuk sekelompok anggota
+type MembershipTier struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Code           string    `json:"code" gorm:"type:varchar(30);not null;uniqueIndex"`
+	Name           string    `json:"name"`
+	MaxLoans       int       `json:"max_loans" gorm:"not null"`        // Jumlah maksimal peminjaman berjalan, 0 berarti tanpa batas
+	LoanPeriodDays int       `json:"loan_period_days" gorm:"not null"` // Lama peminjaman dan perpanjangan (hari)
+	MaxRenewals    int       `json:"max_renewals" gorm:"not null"`     // Batas perpanjangan per peminjaman
+	FineDailyRate  int64     `json:"fine_daily_rate" gorm:"not null"`  // Denda keterlambatan per hari (Rupiah)
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (t *MembershipTier) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
+
+// DefaultMembershipTiers adalah tier yang dibuat otomatis jika belum ada di database
+func DefaultMembershipTiers() []MembershipTier {
+	return []MembershipTier{
+		{Code: TierStudent, Name: "Student", MaxLoans: 3, LoanPeriodDays: 14, MaxRenewals: 2, FineDailyRate: 500},
+		{Code: TierStaff, Name: "Staff", MaxLoans: 10, LoanPeriodDays: 30, MaxRenewals: 3, FineDailyRate: 1000},
+		{Code: TierPublic, Name: "Public", MaxLoans: 2, LoanPeriodDays: 7, MaxRenewals: 1, FineDailyRate: 1000},
+	}
+}
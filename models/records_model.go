@@ -7,16 +7,29 @@ import (
 	"gorm.io/gorm"
 )
 
+// Status peminjaman
+const (
+	RecordStatusBorrowed = "borrowed"
+	RecordStatusReturned = "returned"
+	RecordStatusOverdue  = "overdue"
+	RecordStatusLost     = "lost"
+)
+
 // User merepresentasikan model pengguna
 type Lending_records struct {
-	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Book_id     string     `json:"book_id"`
-	User_id     string     `json:"user_id"`
-	Borrow_date time.Time  `json:"borrow_date"`
-	ReturnDate  *time.Time `json:"return_date"`
-
-	Book Book `gorm:"foreignKey:Book_id;references:ID"`
-	User User `gorm:"foreignKey:User_id;references:ID"`
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Book_id      uuid.UUID  `json:"book_id" gorm:"type:uuid;index"`
+	CopyID       *uuid.UUID `json:"copy_id" gorm:"type:uuid;index"`
+	User_id      uuid.UUID  `json:"user_id" gorm:"type:uuid;index"`
+	Borrow_date  time.Time  `json:"borrow_date"`
+	DueDate      time.Time  `json:"due_date"`
+	ReturnDate   *time.Time `json:"return_date"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;default:borrowed;index"`
+	RenewalCount int        `json:"renewal_count" gorm:"not null;default:0"`
+
+	Book Book      `gorm:"foreignKey:Book_id;references:ID"`
+	Copy *BookCopy `json:"copy,omitempty" gorm:"foreignKey:CopyID;references:ID"`
+	User User      `gorm:"foreignKey:User_id;references:ID"`
 }
 
 func (u *Lending_records) BeforeCreate(tx *gorm.DB) (err error) {
@@ -24,5 +37,35 @@ func (u *Lending_records) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Status == "" {
+		u.Status = RecordStatusBorrowed
+	}
 	return
 }
+
+// AfterFind menyesuaikan status menjadi overdue untuk peminjaman yang sudah lewat jatuh tempo
+func (u *Lending_records) AfterFind(tx *gorm.DB) (err error) {
+	u.SyncOverdueStatus(time.Now())
+	return
+}
+
+// SyncOverdueStatus menandai peminjaman yang masih berjalan sebagai overdue jika jatuh tempo terlewati
+func (u *Lending_records) SyncOverdueStatus(now time.Time) {
+	if u.Status == RecordStatusBorrowed && !u.DueDate.IsZero() && now.After(u.DueDate) {
+		u.Status = RecordStatusOverdue
+	}
+}
+
+// IsOpen memeriksa apakah buku masih berada di tangan peminjam
+func (u *Lending_records) IsOpen() bool {
+	return u.Status == RecordStatusBorrowed || u.Status == RecordStatusOverdue
+}
+
+// IsValidRecordStatus memeriksa apakah status termasuk status yang dikenali
+func IsValidRecordStatus(status string) bool {
+	switch status {
+	case RecordStatusBorrowed, RecordStatusReturned, RecordStatusOverdue, RecordStatusLost:
+		return true
+	}
+	return false
+}
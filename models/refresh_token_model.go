@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken menyimpan refresh token (dalam bentuk hash) yang pernah diterbitkan.
+// Setiap login membuat family baru, dan setiap rotasi menerbitkan token baru dalam family yang sama.
+type RefreshToken struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID           uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	FamilyID         uuid.UUID  `json:"family_id" gorm:"type:uuid;not null;index"`
+	TokenHash        string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	UserAgent        string     `json:"user_agent"`
+	IPAddress        string     `json:"ip_address"`
+	SessionStartedAt time.Time  `json:"session_started_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID     *uuid.UUID `json:"-" gorm:"type:uuid"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
+
+// IsActive memeriksa apakah token belum dicabut dan belum kedaluwarsa
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Renewal mencatat riwayat perpanjangan sebuah peminjaman
+type Renewal struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	RecordID        uuid.UUID `json:"record_id" gorm:"type:uuid;not null;index"`
+	RenewedBy       uuid.UUID `json:"renewed_by" gorm:"type:uuid;not null"`
+	PreviousDueDate time.Time `json:"previous_due_date"`
+	NewDueDate      time.Time `json:"new_due_date"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (r *Renewal) BeforeCreate(tx *gorm.DB) (err error) {
+	// Ensure ID is unique if not already set (e.g., by DB default or manually)
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
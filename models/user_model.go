@@ -5,13 +5,24 @@ import (
 	"gorm.io/gorm"
 )
 
+// Daftar role yang dikenali sistem
+const (
+	RoleAdmin     = "admin"
+	RoleLibrarian = "librarian"
+	RoleMember    = "member"
+)
+
 // User merepresentasikan model pengguna
 type User struct {
 	gorm.Model
-	ID       uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name     string    `json:"name"`
-	Email    string    `json:"email" gorm:"unique"`
-	Password string    `json:"password"` // Jangan sertakan password saat marshal JSON
+	ID       uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name     string     `json:"name"`
+	Email    string     `json:"email" gorm:"unique"`
+	Password string     `json:"password"` // Jangan sertakan password saat marshal JSON
+	Role     string     `json:"role" gorm:"type:varchar(20);not null;default:member"`
+	TierID   *uuid.UUID `json:"tier_id" gorm:"type:uuid;index"`
+
+	Tier *MembershipTier `json:"tier,omitempty" gorm:"foreignKey:TierID;references:ID"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -19,5 +30,22 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Role == "" {
+		u.Role = RoleMember
+	}
 	return
 }
+
+// IsValidRole memeriksa apakah role termasuk role yang dikenali
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleLibrarian, RoleMember:
+		return true
+	}
+	return false
+}
+
+// IsStaffRole mengembalikan true untuk role petugas (admin dan librarian)
+func IsStaffRole(role string) bool {
+	return role == RoleAdmin || role == RoleLibrarian
+}
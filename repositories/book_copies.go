@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateGeneratedCopies menambah sejumlah eksemplar dengan barcode otomatis.
+// Nomor urut barcode melanjutkan jumlah eksemplar yang sudah ada supaya tidak bentrok.
+func CreateGeneratedCopies(tx *gorm.DB, book *models.Book, count int) error {
+	if count <= 0 {
+		return nil
+	}
+
+	var existing int64
+	if err := tx.Model(&models.BookCopy{}).Where("book_id = ?", book.ID).Count(&existing).Error; err != nil {
+		return err
+	}
+
+	copies := make([]models.BookCopy, 0, count)
+	for i := 1; i <= count; i++ {
+		copies = append(copies, models.BookCopy{
+			BookID:  book.ID,
+			Barcode: helpers.GenerateCopyBarcode(book.ID, int(existing)+i),
+			Status:  models.CopyStatusAvailable,
+		})
+	}
+	if err := tx.Create(&copies).Error; err != nil {
+		return err
+	}
+	return SyncBookQuantity(tx, book.ID)
+}
+
+// SyncBookQuantity menghitung ulang books.quantity dari eksemplar yang masih termasuk koleksi
+func SyncBookQuantity(tx *gorm.DB, bookID uuid.UUID) error {
+	return tx.Exec(`UPDATE books SET quantity = (
+		SELECT COUNT(*) FROM book_copies
+		WHERE book_copies.book_id = books.id AND book_copies.status NOT IN (?, ?)
+	) WHERE id = ?`, models.CopyStatusLost, models.CopyStatusWithdrawn, bookID).Error
+}
+
+// CountAvailableCopies menghitung eksemplar buku yang berstatus available.
+// Eksemplar yang disisihkan untuk hold berstatus on_hold sehingga tidak ikut terhitung.
+func CountAvailableCopies(db *gorm.DB, bookID uuid.UUID) (int, error) {
+	var available int64
+	err := db.Model(&models.BookCopy{}).
+		Where("book_id = ? AND status = ?", bookID, models.CopyStatusAvailable).
+		Count(&available).Error
+	return int(available), err
+}
+
+// AvailableCopiesByBook menghitung eksemplar available untuk banyak buku sekaligus.
+// Buku tanpa eksemplar available tidak muncul di map.
+func AvailableCopiesByBook(db *gorm.DB, bookIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	var counts []struct {
+		BookID    uuid.UUID
+		Available int
+	}
+	err := db.Model(&models.BookCopy{}).
+		Select("book_id, COUNT(*) AS available").
+		Where("book_id IN ? AND status = ?", bookIDs, models.CopyStatusAvailable).
+		Group("book_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[uuid.UUID]int, len(counts))
+	for _, count := range counts {
+		available[count.BookID] = count.Available
+	}
+	return available, nil
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"errors"
+	"library/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuthorSeparator memisahkan nama penulis pada kolom books.author. Koma tidak dipakai
+// karena nama penulis sering berformat "Nama keluarga, nama depan".
+const AuthorSeparator = "; "
+
+// NormalizeEntityName merapikan spasi berlebih pada nama penulis atau kategori
+func NormalizeEntityName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// BookAuthorsFor menentukan penulis buku dari ID jika dikirim, atau dari teks author.
+// changed bernilai false jika keduanya kosong sehingga penulis buku tidak perlu diubah.
+func BookAuthorsFor(tx *gorm.DB, ids []uuid.UUID, text string) (authors []models.Author, changed bool, err error) {
+	if ids != nil {
+		authors, err = loadAuthors(tx, ids)
+		return authors, true, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, false, nil
+	}
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(text, ";") {
+		name = NormalizeEntityName(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		author, err := findOrCreateAuthor(tx, name)
+		if err != nil {
+			return nil, true, err
+		}
+		authors = append(authors, *author)
+	}
+	return authors, true, nil
+}
+
+// BookCategoriesFor menentukan kategori buku dari ID jika dikirim, atau dari teks category
+func BookCategoriesFor(tx *gorm.DB, ids []uuid.UUID, text string) (categories []models.Category, changed bool, err error) {
+	if ids != nil {
+		categories, err = loadCategories(tx, ids)
+		return categories, true, err
+	}
+	name := NormalizeEntityName(text)
+	if name == "" {
+		return nil, false, nil
+	}
+	category, err := findOrCreateCategory(tx, name)
+	if err != nil {
+		return nil, true, err
+	}
+	return []models.Category{*category}, true, nil
+}
+
+// loadAuthors mengambil penulis sesuai urutan ids, ID yang berulang hanya dipakai sekali
+func loadAuthors(tx *gorm.DB, ids []uuid.UUID) ([]models.Author, error) {
+	var found []models.Author
+	if len(ids) > 0 {
+		if err := tx.Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uuid.UUID]models.Author, len(found))
+	for _, author := range found {
+		byID[author.ID] = author
+	}
+	authors := make([]models.Author, 0, len(ids))
+	for _, id := range ids {
+		author, ok := byID[id]
+		if !ok {
+			return nil, &NotFoundError{Entity: EntityAuthor, ID: id}
+		}
+		authors = append(authors, author)
+		delete(byID, id)
+	}
+	return authors, nil
+}
+
+// loadCategories mengambil kategori sesuai urutan ids, ID yang berulang hanya dipakai sekali
+func loadCategories(tx *gorm.DB, ids []uuid.UUID) ([]models.Category, error) {
+	var found []models.Category
+	if len(ids) > 0 {
+		if err := tx.Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uuid.UUID]models.Category, len(found))
+	for _, category := range found {
+		byID[category.ID] = category
+	}
+	categories := make([]models.Category, 0, len(ids))
+	for _, id := range ids {
+		category, ok := byID[id]
+		if !ok {
+			return nil, &NotFoundError{Entity: EntityCategory, ID: id}
+		}
+		categories = append(categories, category)
+		delete(byID, id)
+	}
+	return categories, nil
+}
+
+// findOrCreateAuthor mencari penulis berdasarkan nama tanpa membedakan huruf besar/kecil
+// dan membuatnya jika belum ada
+func findOrCreateAuthor(tx *gorm.DB, name string) (*models.Author, error) {
+	author := new(models.Author)
+	err := tx.Where("LOWER(name) = LOWER(?)", name).Take(author).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return author, err
+	}
+	author = &models.Author{Name: name}
+	// Penulis yang sama bisa dibuat bersamaan, index unik membuat salah satunya tidak tersimpan
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(author)
+	if result.Error != nil || result.RowsAffected == 1 {
+		return author, result.Error
+	}
+	author = new(models.Author)
+	return author, tx.Where("LOWER(name) = LOWER(?)", name).Take(author).Error
+}
+
+// findOrCreateCategory mencari kategori berdasarkan nama tanpa membedakan huruf besar/kecil
+// dan membuatnya sebagai kategori utama jika belum ada
+func findOrCreateCategory(tx *gorm.DB, name string) (*models.Category, error) {
+	category := new(models.Category)
+	err := tx.Where("LOWER(name) = LOWER(?)", name).Take(category).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return category, err
+	}
+	category = &models.Category{Name: name}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(category)
+	if result.Error != nil || result.RowsAffected == 1 {
+		return category, result.Error
+	}
+	category = new(models.Category)
+	return category, tx.Where("LOWER(name) = LOWER(?)", name).Take(category).Error
+}
+
+// ApplyBookAuthors memasang penulis pada buku dan mengisi ulang kolom author
+func ApplyBookAuthors(book *models.Book, authors []models.Author) {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	book.Authors = authors
+	book.Author = strings.Join(names, AuthorSeparator)
+}
+
+// ApplyBookCategories memasang kategori pada buku; kategori pertama menjadi kolom category
+func ApplyBookCategories(book *models.Book, categories []models.Category) {
+	book.Categories = categories
+	book.Category = ""
+	if len(categories) > 0 {
+		book.Category = categories[0].Name
+	}
+}
+
+// ReplaceBookAuthors mengganti hubungan buku dengan penulis. Kolom author harus sudah
+// disimpan lewat ApplyBookAuthors.
+func ReplaceBookAuthors(tx *gorm.DB, book *models.Book, authors []models.Author) error {
+	if len(authors) == 0 {
+		return tx.Model(book).Association("Authors").Clear()
+	}
+	return tx.Model(book).Association("Authors").Replace(authors)
+}
+
+// ReplaceBookCategories mengganti hubungan buku dengan kategori
+func ReplaceBookCategories(tx *gorm.DB, book *models.Book, categories []models.Category) error {
+	if len(categories) == 0 {
+		return tx.Model(book).Association("Categories").Clear()
+	}
+	return tx.Model(book).Association("Categories").Replace(categories)
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormBookRepository adalah BookRepository yang disimpan di PostgreSQL lewat GORM
+type GormBookRepository struct {
+	db *gorm.DB
+}
+
+// NewGormBookRepository membuat GormBookRepository
+func NewGormBookRepository(db *gorm.DB) *GormBookRepository {
+	return &GormBookRepository{db: db}
+}
+
+func (r *GormBookRepository) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Book, *helpers.Page, error) {
+	var books []models.Book
+	page, err := helpers.Paginate(query.Filter(r.db.WithContext(ctx).Model(&models.Book{})), query, p, &books, "Authors", "Categories")
+	return books, page, err
+}
+
+func (r *GormBookRepository) All(ctx context.Context) ([]models.Book, error) {
+	var books []models.Book
+	return books, r.db.WithContext(ctx).Find(&books).Error
+}
+
+func (r *GormBookRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Book, error) {
+	book := new(models.Book)
+	err := r.db.WithContext(ctx).Preload("Authors").Preload("Categories").First(book, "id = ?", id).Error
+	return book, notFound(err)
+}
+
+func (r *GormBookRepository) FindByISBN(ctx context.Context, isbn string, withDeleted bool) (*models.Book, error) {
+	query := r.db.WithContext(ctx)
+	if withDeleted {
+		query = query.Unscoped()
+	} else {
+		query = query.Preload("Authors").Preload("Categories")
+	}
+	book := new(models.Book)
+	err := query.Where("isbn = ?", isbn).First(book).Error
+	return book, notFound(err)
+}
+
+func (r *GormBookRepository) AvailableCopies(ctx context.Context, bookID uuid.UUID) (int, error) {
+	return CountAvailableCopies(r.db.WithContext(ctx), bookID)
+}
+
+// Create menyimpan buku, penulis dan kategorinya, serta eksemplar awalnya dalam satu transaksi
+func (r *GormBookRepository) Create(ctx context.Context, book *models.Book, links BookLinks) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		authors, _, err := BookAuthorsFor(tx, links.AuthorIDs, links.Author)
+		if err != nil {
+			return err
+		}
+		categories, _, err := BookCategoriesFor(tx, links.CategoryIDs, links.Category)
+		if err != nil {
+			return err
+		}
+		ApplyBookAuthors(book, authors)
+		ApplyBookCategories(book, categories)
+
+		if err := tx.Create(book).Error; err != nil {
+			return err
+		}
+		return CreateGeneratedCopies(tx, book, book.Quantity)
+	})
+}
+
+// Update menyimpan perubahan buku dan mengganti penulis atau kategorinya jika links mengubahnya,
+// lalu memuat ulang penulis dan kategori buku
+func (r *GormBookRepository) Update(ctx context.Context, book *models.Book, links BookLinks) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		authors, authorsChanged, err := BookAuthorsFor(tx, links.AuthorIDs, links.Author)
+		if err != nil {
+			return err
+		}
+		categories, categoriesChanged, err := BookCategoriesFor(tx, links.CategoryIDs, links.Category)
+		if err != nil {
+			return err
+		}
+		if authorsChanged {
+			ApplyBookAuthors(book, authors)
+		}
+		if categoriesChanged {
+			ApplyBookCategories(book, categories)
+		}
+
+		if err := tx.Omit(clause.Associations).Save(book).Error; err != nil {
+			return err
+		}
+		if authorsChanged {
+			if err := ReplaceBookAuthors(tx, book, authors); err != nil {
+				return err
+			}
+		}
+		if categoriesChanged {
+			return ReplaceBookCategories(tx, book, categories)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Preload("Authors").Preload("Categories").First(book, "id = ?", book.ID).Error
+}
+
+func (r *GormBookRepository) SetCoverVersion(ctx context.Context, book *models.Book, version string) error {
+	return r.db.WithContext(ctx).Model(book).Update("cover_version", version).Error
+}
+
+func (r *GormBookRepository) Delete(ctx context.Context, book *models.Book) error {
+	return r.db.WithContext(ctx).Delete(book).Error
+}
+
+// notFound mengganti gorm.ErrRecordNotFound dengan ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
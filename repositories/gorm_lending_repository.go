@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GormLendingRepository adalah LendingRepository yang disimpan di PostgreSQL lewat GORM
+type GormLendingRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLendingRepository membuat GormLendingRepository
+func NewGormLendingRepository(db *gorm.DB) *GormLendingRepository {
+	return &GormLendingRepository{db: db}
+}
+
+func (r *GormLendingRepository) List(ctx context.Context, filter RecordFilter, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Lending_records, *helpers.Page, error) {
+	db := query.Filter(r.db.WithContext(ctx).Model(&models.Lending_records{}))
+	if filter.UserID != nil {
+		db = db.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		db = FilterRecordsByStatus(db, filter.Status, filter.Now)
+	}
+
+	var records []models.Lending_records
+	page, err := helpers.Paginate(db, query, p, &records, "Book", "User")
+	return records, page, err
+}
+
+func (r *GormLendingRepository) FindByID(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) (*models.Lending_records, error) {
+	query := r.db.WithContext(ctx)
+	if ownerID != nil {
+		query = query.Where("user_id = ?", *ownerID)
+	}
+	record := new(models.Lending_records)
+	err := query.Preload("Book").Preload("Copy").First(record, "id = ?", id).Error
+	return record, notFound(err)
+}
+
+func (r *GormLendingRepository) CurrentLoans(ctx context.Context, userID uuid.UUID) ([]models.Lending_records, error) {
+	records := []models.Lending_records{}
+	err := r.db.WithContext(ctx).Preload("Book").Preload("Copy").
+		Where("user_id = ? AND return_date IS NULL AND status <> ?", userID, models.RecordStatusLost).
+		Order("due_date ASC").Find(&records).Error
+	return records, err
+}
+
+func (r *GormLendingRepository) PastLoans(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Lending_records, int64, error) {
+	past := r.db.WithContext(ctx).Model(&models.Lending_records{}).
+		Where("user_id = ? AND return_date IS NOT NULL", userID)
+
+	var total int64
+	if err := past.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	records := []models.Lending_records{}
+	err := past.Preload("Book").Order("return_date DESC").Limit(limit).Offset(offset).Find(&records).Error
+	return records, total, err
+}
+
+func (r *GormLendingRepository) Save(ctx context.Context, record *models.Lending_records) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Save(record).Error; err != nil {
+		return err
+	}
+	if err := db.Model(record).Association("Book").Find(&record.Book); err != nil {
+		return err
+	}
+	return db.Model(record).Association("User").Find(&record.User)
+}
+
+func (r *GormLendingRepository) Delete(ctx context.Context, record *models.Lending_records) error {
+	return r.db.WithContext(ctx).Delete(record).Error
+}
+
+// FilterRecordsByStatus menerapkan filter status. Overdue dihitung dari jatuh tempo,
+// sehingga "borrowed" hanya berisi peminjaman yang belum lewat jatuh tempo.
+func FilterRecordsByStatus(query *gorm.DB, status string, now time.Time) *gorm.DB {
+	switch status {
+	case models.RecordStatusOverdue:
+		return query.Where("(status = ? OR (status = ? AND due_date < ?))", models.RecordStatusOverdue, models.RecordStatusBorrowed, now)
+	case models.RecordStatusBorrowed:
+		return query.Where("status = ? AND (due_date IS NULL OR due_date >= ?)", models.RecordStatusBorrowed, now)
+	default:
+		return query.Where("status = ?", status)
+	}
+}
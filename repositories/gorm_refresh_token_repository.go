@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"library/models" // Sesuaikan dengan nama proyekmu
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormRefreshTokenRepository adalah RefreshTokenRepository yang disimpan di PostgreSQL lewat GORM
+type GormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRefreshTokenRepository membuat GormRefreshTokenRepository
+func NewGormRefreshTokenRepository(db *gorm.DB) *GormRefreshTokenRepository {
+	return &GormRefreshTokenRepository{db: db}
+}
+
+func (r *GormRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *GormRefreshTokenRepository) Rotate(ctx context.Context, tokenHash string, userID uuid.UUID, next *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current := new(models.RefreshToken)
+		// Kunci baris token agar dua permintaan refresh bersamaan tidak sama-sama berhasil
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			First(current).Error; err != nil {
+			return notFound(err)
+		}
+		if current.UserID != userID {
+			return ErrNotFound
+		}
+		if current.RevokedAt != nil {
+			return ErrTokenReused
+		}
+
+		next.UserID = userID
+		next.FamilyID = current.FamilyID
+		next.SessionStartedAt = current.SessionStartedAt
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(current).Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": next.ID,
+		}).Error
+	})
+}
+
+func (r *GormRefreshTokenRepository) RevokeFamilyOf(ctx context.Context, tokenHash string) error {
+	stored := new(models.RefreshToken)
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", stored.FamilyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *GormRefreshTokenRepository) RevokeFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormRefreshTokenRepository) RevokeAll(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormRefreshTokenRepository) Active(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
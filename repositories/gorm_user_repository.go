@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormUserRepository adalah UserRepository yang disimpan di PostgreSQL lewat GORM
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository membuat GormUserRepository
+func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
+}
+
+func (r *GormUserRepository) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.User, *helpers.Page, error) {
+	var users []models.User
+	page, err := helpers.Paginate(query.Filter(r.db.WithContext(ctx).Model(&models.User{})), query, p, &users)
+	return users, page, err
+}
+
+func (r *GormUserRepository) All(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	return users, r.db.WithContext(ctx).Find(&users).Error
+}
+
+func (r *GormUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user := new(models.User)
+	err := r.db.WithContext(ctx).Preload("Tier").First(user, "id = ?", id).Error
+	return user, notFound(err)
+}
+
+func (r *GormUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	user := new(models.User)
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(user).Error
+	return user, notFound(err)
+}
+
+func (r *GormUserRepository) FindTierByCode(ctx context.Context, code string) (*models.MembershipTier, error) {
+	tier := new(models.MembershipTier)
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(tier).Error
+	return tier, notFound(err)
+}
+
+func (r *GormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(user).Error
+}
+
+// Save menyimpan perubahan pengguna. Tier yang ter-preload tidak ikut disimpan,
+// perpindahan tier dilakukan lewat TierID.
+func (r *GormUserRepository) Save(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(user).Error
+}
+
+func (r *GormUserRepository) Delete(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Delete(user).Error
+}
@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MemoryBookRepository adalah BookRepository di memori untuk pengujian handler tanpa database.
+// Filter dan urutan dari ListQuery tidak diterapkan, List mengembalikan buku sesuai urutan disimpan.
+type MemoryBookRepository struct {
+	mu         sync.Mutex
+	books      []models.Book
+	authors    []models.Author
+	categories []models.Category
+	available  map[uuid.UUID]int
+}
+
+// NewMemoryBookRepository membuat MemoryBookRepository berisi books.
+// Eksemplar available setiap buku awal sama dengan Quantity-nya.
+func NewMemoryBookRepository(books ...models.Book) *MemoryBookRepository {
+	r := &MemoryBookRepository{available: make(map[uuid.UUID]int)}
+	for _, book := range books {
+		if book.ID == uuid.Nil {
+			book.ID = uuid.New()
+		}
+		r.books = append(r.books, book)
+		r.available[book.ID] = book.Quantity
+	}
+	return r
+}
+
+// AddAuthors menambahkan penulis yang bisa dirujuk lewat BookLinks.AuthorIDs
+func (r *MemoryBookRepository) AddAuthors(authors ...models.Author) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.authors = append(r.authors, authors...)
+}
+
+// AddCategories menambahkan kategori yang bisa dirujuk lewat BookLinks.CategoryIDs
+func (r *MemoryBookRepository) AddCategories(categories ...models.Category) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = append(r.categories, categories...)
+}
+
+// SetAvailableCopies mengatur jumlah eksemplar available sebuah buku
+func (r *MemoryBookRepository) SetAvailableCopies(bookID uuid.UUID, available int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.available[bookID] = available
+}
+
+func (r *MemoryBookRepository) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Book, *helpers.Page, error) {
+	books, _ := r.All(ctx)
+	return memoryPage(books, p)
+}
+
+func (r *MemoryBookRepository) All(ctx context.Context) ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	books := []models.Book{}
+	for _, book := range r.books {
+		if !book.DeletedAt.Valid {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+func (r *MemoryBookRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, book := range r.books {
+		if book.ID == id && !book.DeletedAt.Valid {
+			return &book, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryBookRepository) FindByISBN(ctx context.Context, isbn string, withDeleted bool) (*models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, book := range r.books {
+		if book.Isbn == isbn && (withDeleted || !book.DeletedAt.Valid) {
+			return &book, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryBookRepository) AvailableCopies(ctx context.Context, bookID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.available[bookID], nil
+}
+
+func (r *MemoryBookRepository) Create(ctx context.Context, book *models.Book, links BookLinks) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.books {
+		if existing.Isbn == book.Isbn {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	if err := r.link(book, links); err != nil {
+		return err
+	}
+	if book.ID == uuid.Nil {
+		book.ID = uuid.New()
+	}
+	book.CreatedAt = time.Now()
+	book.UpdatedAt = book.CreatedAt
+	r.books = append(r.books, *book)
+	r.available[book.ID] = book.Quantity
+	return nil
+}
+
+func (r *MemoryBookRepository) Update(ctx context.Context, book *models.Book, links BookLinks) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.books {
+		if existing.ID != book.ID {
+			continue
+		}
+		if err := r.link(book, links); err != nil {
+			return err
+		}
+		book.UpdatedAt = time.Now()
+		r.books[i] = *book
+		return nil
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryBookRepository) SetCoverVersion(ctx context.Context, book *models.Book, version string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.books {
+		if existing.ID == book.ID {
+			book.CoverVersion = version
+			book.UpdatedAt = time.Now()
+			r.books[i].CoverVersion = version
+			r.books[i].UpdatedAt = book.UpdatedAt
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryBookRepository) Delete(ctx context.Context, book *models.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.books {
+		if existing.ID == book.ID {
+			r.books[i].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// link memasang penulis dan kategori seperti BookAuthorsFor dan BookCategoriesFor,
+// nama yang belum dikenal ditambahkan sebagai entitas baru
+func (r *MemoryBookRepository) link(book *models.Book, links BookLinks) error {
+	if links.AuthorIDs != nil || strings.TrimSpace(links.Author) != "" {
+		var authors []models.Author
+		if links.AuthorIDs != nil {
+			for _, id := range links.AuthorIDs {
+				author, ok := r.authorByID(id)
+				if !ok {
+					return &NotFoundError{Entity: EntityAuthor, ID: id}
+				}
+				authors = append(authors, author)
+			}
+		} else {
+			seen := make(map[string]bool)
+			for _, name := range strings.Split(links.Author, ";") {
+				name = NormalizeEntityName(name)
+				if name == "" || seen[strings.ToLower(name)] {
+					continue
+				}
+				seen[strings.ToLower(name)] = true
+				authors = append(authors, r.authorByName(name))
+			}
+		}
+		ApplyBookAuthors(book, authors)
+	}
+
+	if links.CategoryIDs != nil || NormalizeEntityName(links.Category) != "" {
+		var categories []models.Category
+		if links.CategoryIDs != nil {
+			for _, id := range links.CategoryIDs {
+				category, ok := r.categoryByID(id)
+				if !ok {
+					return &NotFoundError{Entity: EntityCategory, ID: id}
+				}
+				categories = append(categories, category)
+			}
+		} else {
+			categories = append(categories, r.categoryByName(NormalizeEntityName(links.Category)))
+		}
+		ApplyBookCategories(book, categories)
+	}
+	return nil
+}
+
+func (r *MemoryBookRepository) authorByID(id uuid.UUID) (models.Author, bool) {
+	for _, author := range r.authors {
+		if author.ID == id {
+			return author, true
+		}
+	}
+	return models.Author{}, false
+}
+
+func (r *MemoryBookRepository) authorByName(name string) models.Author {
+	for _, author := range r.authors {
+		if strings.EqualFold(author.Name, name) {
+			return author
+		}
+	}
+	author := models.Author{ID: uuid.New(), Name: name}
+	r.authors = append(r.authors, author)
+	return author
+}
+
+func (r *MemoryBookRepository) categoryByID(id uuid.UUID) (models.Category, bool) {
+	for _, category := range r.categories {
+		if category.ID == id {
+			return category, true
+		}
+	}
+	return models.Category{}, false
+}
+
+func (r *MemoryBookRepository) categoryByName(name string) models.Category {
+	for _, category := range r.categories {
+		if strings.EqualFold(category.Name, name) {
+			return category
+		}
+	}
+	category := models.Category{ID: uuid.New(), Name: name}
+	r.categories = append(r.categories, category)
+	return category
+}
+
+// memoryPage memotong items menjadi satu halaman pagination offset untuk repository memori
+func memoryPage[T any](items []T, p *helpers.Pagination) ([]T, *helpers.Page, error) {
+	if p.Mode != helpers.PaginationOffset {
+		return nil, nil, &helpers.QueryError{Message: "cursor pagination is not supported by the in-memory repository"}
+	}
+	total := int64(len(items))
+	start := (p.Page - 1) * p.Limit
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + p.Limit
+	if end > len(items) {
+		end = len(items)
+	}
+	pageItems := items[start:end]
+	totalPages := (total + int64(p.Limit) - 1) / int64(p.Limit)
+	return pageItems, &helpers.Page{
+		Data:        pageItems,
+		PerPage:     p.Limit,
+		TotalItems:  &total,
+		CurrentPage: p.Page,
+		TotalPages:  &totalPages,
+	}, nil
+}
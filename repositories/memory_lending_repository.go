@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryLendingRepository adalah LendingRepository di memori untuk pengujian handler tanpa database.
+// RecordFilter diterapkan, sedangkan filter dan urutan dari ListQuery tidak. Seperti AfterFind,
+// status peminjaman yang lewat jatuh tempo dibaca sebagai overdue.
+type MemoryLendingRepository struct {
+	mu      sync.Mutex
+	records []models.Lending_records
+}
+
+// NewMemoryLendingRepository membuat MemoryLendingRepository berisi records
+func NewMemoryLendingRepository(records ...models.Lending_records) *MemoryLendingRepository {
+	r := &MemoryLendingRepository{}
+	for _, record := range records {
+		if record.ID == uuid.Nil {
+			record.ID = uuid.New()
+		}
+		r.records = append(r.records, record)
+	}
+	return r
+}
+
+func (r *MemoryLendingRepository) List(ctx context.Context, filter RecordFilter, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Lending_records, *helpers.Page, error) {
+	r.mu.Lock()
+	records := []models.Lending_records{}
+	for _, record := range r.records {
+		if filter.UserID != nil && record.User_id != *filter.UserID {
+			continue
+		}
+		if filter.Status != "" && !recordHasStatus(&record, filter) {
+			continue
+		}
+		record.SyncOverdueStatus(time.Now())
+		records = append(records, record)
+	}
+	r.mu.Unlock()
+	return memoryPage(records, p)
+}
+
+func (r *MemoryLendingRepository) FindByID(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) (*models.Lending_records, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, record := range r.records {
+		if record.ID == id && (ownerID == nil || record.User_id == *ownerID) {
+			record.SyncOverdueStatus(time.Now())
+			return &record, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryLendingRepository) CurrentLoans(ctx context.Context, userID uuid.UUID) ([]models.Lending_records, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := []models.Lending_records{}
+	for _, record := range r.records {
+		if record.User_id == userID && record.ReturnDate == nil && record.Status != models.RecordStatusLost {
+			record.SyncOverdueStatus(time.Now())
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].DueDate.Before(records[j].DueDate)
+	})
+	return records, nil
+}
+
+func (r *MemoryLendingRepository) PastLoans(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Lending_records, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := []models.Lending_records{}
+	for _, record := range r.records {
+		if record.User_id == userID && record.ReturnDate != nil {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].ReturnDate.After(*records[j].ReturnDate)
+	})
+	total := int64(len(records))
+	if offset > len(records) {
+		offset = len(records)
+	}
+	if end := offset + limit; end < len(records) {
+		records = records[:end]
+	}
+	return records[offset:], total, nil
+}
+
+func (r *MemoryLendingRepository) Save(ctx context.Context, record *models.Lending_records) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.records {
+		if existing.ID == record.ID {
+			r.records[i] = *record
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryLendingRepository) Delete(ctx context.Context, record *models.Lending_records) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.records {
+		if existing.ID == record.ID {
+			r.records = append(r.records[:i], r.records[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// recordHasStatus mencocokkan status seperti FilterRecordsByStatus
+func recordHasStatus(record *models.Lending_records, filter RecordFilter) bool {
+	switch filter.Status {
+	case models.RecordStatusOverdue:
+		return record.Status == models.RecordStatusOverdue ||
+			(record.Status == models.RecordStatusBorrowed && record.DueDate.Before(filter.Now))
+	case models.RecordStatusBorrowed:
+		return record.Status == models.RecordStatusBorrowed && !record.DueDate.Before(filter.Now)
+	default:
+		return record.Status == filter.Status
+	}
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MemoryUserRepository adalah UserRepository di memori untuk pengujian handler tanpa database.
+// Filter dan urutan dari ListQuery tidak diterapkan, List mengembalikan pengguna sesuai urutan disimpan.
+type MemoryUserRepository struct {
+	mu    sync.Mutex
+	users []models.User
+	tiers []models.MembershipTier
+}
+
+// NewMemoryUserRepository membuat MemoryUserRepository berisi users
+func NewMemoryUserRepository(users ...models.User) *MemoryUserRepository {
+	r := &MemoryUserRepository{}
+	for _, user := range users {
+		if user.ID == uuid.Nil {
+			user.ID = uuid.New()
+		}
+		r.users = append(r.users, user)
+	}
+	return r
+}
+
+// AddTiers menambahkan tier keanggotaan yang bisa dicari lewat FindTierByCode
+func (r *MemoryUserRepository) AddTiers(tiers ...models.MembershipTier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tiers = append(r.tiers, tiers...)
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.User, *helpers.Page, error) {
+	users, _ := r.All(ctx)
+	return memoryPage(users, p)
+}
+
+func (r *MemoryUserRepository) All(ctx context.Context) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := []models.User{}
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.ID == id && !user.DeletedAt.Valid {
+			user.Tier = r.tierByID(user.TierID)
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) FindTierByCode(ctx context.Context, code string) (*models.MembershipTier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tier := range r.tiers {
+		if tier.Code == code {
+			return &tier, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Role == "" {
+		user.Role = models.RoleMember
+	}
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	r.users = append(r.users, *user)
+	return nil
+}
+
+func (r *MemoryUserRepository) Save(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.users {
+		if existing.ID == user.ID {
+			user.UpdatedAt = time.Now()
+			r.users[i] = *user
+			r.users[i].Tier = nil
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.users {
+		if existing.ID == user.ID {
+			r.users[i].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryUserRepository) tierByID(id *uuid.UUID) *models.MembershipTier {
+	if id == nil {
+		return nil
+	}
+	for _, tier := range r.tiers {
+		if tier.ID == *id {
+			return &tier
+		}
+	}
+	return nil
+}
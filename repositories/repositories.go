@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound dikembalikan jika data yang dicari tidak ada
+var ErrNotFound = errors.New("record not found")
+
+// ErrTokenReused dikembalikan saat refresh token yang sudah dicabut (dirotasi) dipakai lagi
+var ErrTokenReused = errors.New("refresh token reuse detected")
+
+// Nama entitas pada NotFoundError
+const (
+	EntityAuthor   = "Author"
+	EntityCategory = "Category"
+)
+
+// NotFoundError dikembalikan jika penulis atau kategori yang dirujuk lewat ID tidak ada.
+// errors.Is(err, ErrNotFound) tetap bernilai true untuk error ini.
+type NotFoundError struct {
+	Entity string
+	ID     uuid.UUID
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Entity, e.ID)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// BookLinks menentukan penulis dan kategori buku saat dibuat atau diperbarui.
+// ID yang dikirim (termasuk daftar kosong) dipakai apa adanya; jika nil, nama pada Author
+// (dipisah titik koma) dan Category dicari atau dibuat. ID nil dan nama kosong berarti tidak diubah.
+type BookLinks struct {
+	AuthorIDs   []uuid.UUID
+	Author      string
+	CategoryIDs []uuid.UUID
+	Category    string
+}
+
+// BookRepository menyimpan dan membaca buku beserta penulis, kategori dan eksemplarnya
+type BookRepository interface {
+	// List mengambil satu halaman buku sesuai filter, urutan dan pagination, dengan penulis dan kategorinya
+	List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Book, *helpers.Page, error)
+	All(ctx context.Context) ([]models.Book, error)
+	// FindByID dan FindByISBN memuat penulis dan kategori buku. withDeleted ikut mencari buku
+	// yang sudah dihapus, karena buku tersebut tetap memegang ISBN-nya.
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Book, error)
+	FindByISBN(ctx context.Context, isbn string, withDeleted bool) (*models.Book, error)
+	AvailableCopies(ctx context.Context, bookID uuid.UUID) (int, error)
+	// Create menyimpan buku baru beserta eksemplar sebanyak Quantity dengan barcode otomatis
+	Create(ctx context.Context, book *models.Book, links BookLinks) error
+	Update(ctx context.Context, book *models.Book, links BookLinks) error
+	// SetCoverVersion hanya mengganti versi cover buku; "" berarti buku tidak punya cover
+	SetCoverVersion(ctx context.Context, book *models.Book, version string) error
+	Delete(ctx context.Context, book *models.Book) error
+}
+
+// UserRepository menyimpan dan membaca pengguna
+type UserRepository interface {
+	List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.User, *helpers.Page, error)
+	All(ctx context.Context) ([]models.User, error)
+	// FindByID memuat tier keanggotaan pengguna
+	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindTierByCode(ctx context.Context, code string) (*models.MembershipTier, error)
+	Create(ctx context.Context, user *models.User) error
+	Save(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, user *models.User) error
+}
+
+// RecordFilter membatasi peminjaman yang dibaca LendingRepository
+type RecordFilter struct {
+	// UserID membatasi ke peminjaman milik satu anggota, nil berarti semua anggota
+	UserID *uuid.UUID
+	// Status adalah salah satu models.RecordStatus*, overdue dihitung dari jatuh tempo terhadap Now
+	Status string
+	Now    time.Time
+}
+
+// LendingRepository menyimpan dan membaca record peminjaman
+type LendingRepository interface {
+	// List mengambil satu halaman peminjaman dengan buku dan peminjamnya
+	List(ctx context.Context, filter RecordFilter, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Lending_records, *helpers.Page, error)
+	// FindByID memuat buku dan eksemplar; ownerID tidak nil membatasi ke peminjaman milik anggota tersebut
+	FindByID(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) (*models.Lending_records, error)
+	// CurrentLoans mengambil peminjaman anggota yang belum dikembalikan dan tidak hilang, urut jatuh tempo
+	CurrentLoans(ctx context.Context, userID uuid.UUID) ([]models.Lending_records, error)
+	// PastLoans mengambil riwayat peminjaman yang sudah dikembalikan (terbaru dulu) beserta jumlah seluruhnya
+	PastLoans(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Lending_records, int64, error)
+	// Save menyimpan perubahan record lalu memuat ulang buku dan peminjamnya
+	Save(ctx context.Context, record *models.Lending_records) error
+	Delete(ctx context.Context, record *models.Lending_records) error
+}
+
+// RefreshTokenRepository menyimpan refresh token (sesi login) dalam bentuk hash. Token dalam
+// satu family mewakili satu sesi, dan hanya token terbaru di family yang aktif.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// Rotate mencabut token dengan hash tokenHash dan menyimpan next sebagai penggantinya di
+	// family yang sama, dalam satu transaksi. ErrNotFound jika token tidak ada atau bukan
+	// milik userID, ErrTokenReused jika token sudah dicabut.
+	Rotate(ctx context.Context, tokenHash string, userID uuid.UUID, next *models.RefreshToken) error
+	// RevokeFamilyOf mencabut seluruh family milik token dengan hash tokenHash; token yang
+	// tidak dikenal diabaikan
+	RevokeFamilyOf(ctx context.Context, tokenHash string) error
+	// RevokeFamily dan RevokeAll mengembalikan jumlah sesi yang dicabut
+	RevokeFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (int64, error)
+	RevokeAll(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Active mengambil token aktif pengguna (satu per sesi), terbaru dulu
+	Active(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.RefreshToken, error)
+}
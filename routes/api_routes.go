@@ -1,49 +1,151 @@
 package routes
 
 import (
-	"library/controllers" // SESUAIKAN DENGAN NAMA MODUL GO ANDA
-	"library/middleware"  // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/config"       // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/controllers"  // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/database"     // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/middleware"   // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/repositories" // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/services"     // SESUAIKAN DENGAN NAMA MODUL GO ANDA
+	"library/storage"      // SESUAIKAN DENGAN NAMA MODUL GO ANDA
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// SetupRoutes mengatur semua rute API
-func SetupRoutes(app *fiber.App) {
+// SetupRoutes mengatur semua rute API. store adalah storage file cover dari storage.InitStorage.
+func SetupRoutes(app *fiber.App, cfg *config.Config, store storage.Storage) {
 	api := app.Group("/api/v1")
 
+	// Aturan domain ada di services, handler hanya menerjemahkan HTTP
+	bookRepository := repositories.NewGormBookRepository(database.DBClient)
+	catalog := services.NewCatalogService(bookRepository)
+	userRepository := repositories.NewGormUserRepository(database.DBClient)
+	tokenRepository := repositories.NewGormRefreshTokenRepository(database.DBClient)
+	accounts := services.NewAccountService(userRepository, cfg)
+	lending := services.NewCirculationService(database.DBClient, cfg)
+	memberships := services.NewMembershipService(database.DBClient)
+	// Pencarian dan OPDS memakai query PostgreSQL (full-text, trigram), export memakai cursor
+	catalogSearch := services.NewSearchService(database.DBClient)
+
+	books := controllers.NewBookHandler(catalog)
+	users := controllers.NewUserHandler(accounts)
+	records := controllers.NewRecordHandler(repositories.NewGormLendingRepository(database.DBClient), lending)
+	circulation := controllers.NewCirculationHandler(lending)
+	auth := controllers.NewAuthHandler(cfg, userRepository, tokenRepository)
+	sessions := controllers.NewSessionHandler(tokenRepository)
+	copies := controllers.NewCopyHandler(lending)
+	imports := controllers.NewImportHandler(services.NewImportService(database.DBClient, cfg))
+	tiers := controllers.NewMembershipHandler(memberships)
+	authors := controllers.NewAuthorHandler(services.NewAuthorService(database.DBClient))
+	categories := controllers.NewCategoryHandler(services.NewCategoryService(database.DBClient))
+	covers := controllers.NewCoverHandler(bookRepository, store)
+	search := controllers.NewSearchHandler(catalogSearch)
+	feeds := controllers.NewOPDSHandler(catalogSearch, bookRepository)
+	exports := controllers.NewExportHandler(services.NewExportService(database.DBClient))
+	dashboard := controllers.NewDashboardHandler(services.NewDashboardService(database.DBClient))
+
 	// Rute Autentikasi (Publik)
-	api.Post("/auth/login", controllers.Login)
-	api.Post("/auth/refresh", controllers.RefreshAccessToken)
+	api.Post("/auth/login", auth.Login)
+	api.Post("/auth/refresh", auth.RefreshAccessToken)
+	api.Post("/auth/logout", auth.Logout)
 
 	// Rute Publik lainnya
-	api.Post("/users", controllers.CreateUser)
+	api.Post("/users", users.CreateUser)
+	api.Get("/covers/:id/:version/:file", covers.GetCoverImage)
+
+	// Katalog OPDS untuk aplikasi e-reader, login dengan Access Token atau HTTP Basic (email & password)
+	opds := api.Group("/opds", middleware.BasicOrBearerAuth("Library OPDS", cfg, userRepository))
+	opds.Get("/", feeds.OPDSRoot)
+	opds.Get("/opensearch.xml", feeds.OPDSOpenSearch)
+	opds.Get("/new", feeds.OPDSNewArrivals)
+	opds.Get("/books", feeds.OPDSAllBooks)
+	opds.Get("/books/:id", feeds.OPDSBook)
+	opds.Get("/categories", feeds.OPDSCategories)
+	opds.Get("/categories/:category", feeds.OPDSCategoryBooks)
+	opds.Get("/search", feeds.OPDSSearch)
+
+	// Hak akses: admin & librarian (staff) mengelola katalog dan pengguna,
+	// member hanya membaca katalog dan mengelola peminjamannya sendiri
+	staff := middleware.StaffOnly()
+	admin := middleware.AdminOnly()
 
 	authenticated := api.Group("/protected")
-	authenticated.Use(middleware.AuthRequired)
-	authenticated.Get("/users", controllers.GetAllUsers)
-	authenticated.Get("/users/all", controllers.GetAllUsersNoPagination)
-	authenticated.Get("/users/me", controllers.GetCurrentUser)
-	authenticated.Get("/users/:id", controllers.GetUserByID)
-	authenticated.Put("/users/:id", controllers.UpdateUser)
-	authenticated.Delete("/users/:id", controllers.DeleteUser)
+	authenticated.Use(middleware.AuthRequired(cfg))
+	authenticated.Post("/auth/logout-all", auth.LogoutAll)
+	authenticated.Get("/users", staff, users.GetAllUsers)
+	authenticated.Get("/users/all", staff, users.GetAllUsersNoPagination)
+	authenticated.Get("/users/export", staff, exports.ExportUsers)
+	authenticated.Get("/users/me", users.GetCurrentUser)
+	authenticated.Get("/users/me/sessions", sessions.GetMySessions)
+	authenticated.Delete("/users/me/sessions/:id", sessions.RevokeMySession)
+	authenticated.Get("/users/me/fines", circulation.GetMyFines)
+	authenticated.Get("/users/me/holds", circulation.GetMyHolds)
+	authenticated.Get("/users/me/loans", records.GetMyLoans)
+	authenticated.Get("/users/:id", staff, users.GetUserByID)
+	authenticated.Put("/users/:id", staff, users.UpdateUser)
+	authenticated.Delete("/users/:id", admin, users.DeleteUser)
+	authenticated.Put("/users/:id/tier", staff, tiers.AssignUserTier)
+	authenticated.Get("/users/:id/fines", staff, circulation.GetUserFines)
+	authenticated.Post("/users/:id/fines/pay", staff, circulation.PayFine)
+	authenticated.Post("/users/:id/fines/waive", staff, circulation.WaiveFine)
+	authenticated.Post("/users/:id/fines/adjust", staff, circulation.AdjustFine)
+
+	// Tier keanggotaan
+	authenticated.Get("/membership-tiers", tiers.GetMembershipTiers)
+	authenticated.Post("/membership-tiers", admin, tiers.CreateMembershipTier)
+	authenticated.Put("/membership-tiers/:id", admin, tiers.UpdateMembershipTier)
 
 	//books
-	authenticated.Post("/books", controllers.CreateBook)
-	authenticated.Get("/books", controllers.GetAllBooks)
-	authenticated.Get("/books/all", controllers.GetAllBooksNoPagination)
-	authenticated.Get("/books/:id", controllers.GetBooksByID)
-	authenticated.Put("/books/:id", controllers.UpdateBooks)
-	authenticated.Delete("/books/:id", controllers.DeleteBooks)
+	authenticated.Post("/books", staff, books.CreateBook)
+	authenticated.Post("/books/import", staff, imports.ImportBooksFile)
+	authenticated.Get("/books", books.GetAllBooks)
+	authenticated.Get("/books/all", books.GetAllBooksNoPagination)
+	authenticated.Get("/books/export", staff, exports.ExportBooks)
+	authenticated.Get("/books/search", search.SearchBooks)
+	authenticated.Get("/books/isbn/:isbn", books.GetBookByIsbn)
+	authenticated.Get("/books/:id", books.GetBooksByID)
+	authenticated.Put("/books/:id", staff, books.UpdateBooks)
+	authenticated.Delete("/books/:id", staff, books.DeleteBooks)
+	authenticated.Put("/books/:id/cover", staff, covers.UploadBookCover)
+	authenticated.Delete("/books/:id/cover", staff, covers.DeleteBookCover)
+	authenticated.Post("/books/:id/holds", circulation.PlaceHold)
+	authenticated.Get("/books/:id/holds", staff, circulation.GetBookHolds)
+	authenticated.Get("/books/:id/copies", staff, copies.GetBookCopies)
+	authenticated.Post("/books/:id/copies", staff, copies.CreateBookCopy)
+	//authors & categories
+	authenticated.Get("/authors", authors.GetAllAuthors)
+	authenticated.Post("/authors", staff, authors.CreateAuthor)
+	authenticated.Get("/authors/:id", authors.GetAuthorByID)
+	authenticated.Put("/authors/:id", staff, authors.UpdateAuthor)
+	authenticated.Delete("/authors/:id", staff, authors.DeleteAuthor)
+	authenticated.Get("/categories", categories.GetCategories)
+	authenticated.Post("/categories", staff, categories.CreateCategory)
+	authenticated.Get("/categories/:id", categories.GetCategoryByID)
+	authenticated.Put("/categories/:id", staff, categories.UpdateCategory)
+	authenticated.Delete("/categories/:id", staff, categories.DeleteCategory)
+	//copies
+	authenticated.Get("/copies/barcode/:barcode", staff, copies.GetCopyByBarcode)
+	authenticated.Put("/copies/:id", staff, copies.UpdateBookCopy)
+	//circulation desk
+	authenticated.Post("/circulation/checkout", staff, circulation.CheckoutByBarcode)
+	authenticated.Post("/circulation/return", staff, circulation.ReturnByBarcode)
+	//holds
+	authenticated.Delete("/holds/:id", circulation.CancelHold)
 	//borrow
-	authenticated.Post("/record", controllers.CreateRecord)
-	authenticated.Get("/record", controllers.GetAllRecord)
-	authenticated.Get("/record/:id", controllers.GetRecordByID)
-	authenticated.Put("/record/:id", controllers.UpdateRecords)
-	authenticated.Delete("/record/:id", controllers.DeleteRecords)
+	authenticated.Post("/record", records.CreateRecord)
+	authenticated.Get("/record", records.GetAllRecord)
+	authenticated.Get("/record/export", exports.ExportRecords)
+	authenticated.Get("/record/:id", records.GetRecordByID)
+	authenticated.Put("/record/:id", staff, records.UpdateRecords)
+	authenticated.Post("/record/:id/return", staff, records.ReturnRecord)
+	authenticated.Post("/record/:id/renew", circulation.RenewRecord)
+	authenticated.Post("/record/:id/lost", staff, circulation.MarkRecordLost)
+	authenticated.Get("/record/:id/renewals", circulation.GetRecordRenewals)
+	authenticated.Delete("/record/:id", staff, records.DeleteRecords)
 	//dashboard
-	authenticated.Get("/dashboard/summary", controllers.GetDashboardSummary)
-	authenticated.Get("/dashboard/monthly-trend", controllers.GetMonthlyBorrowingTrend)
-	authenticated.Get("/dashboard/latest-activity", controllers.GetLatestActivity)
-	authenticated.Get("/dashboard/top-borrowed-books", controllers.GetTopBorrowedBooks)
-	authenticated.Get("/dashboard/categories-distribution", controllers.GetBookCategoriesDistribution)
+	authenticated.Get("/dashboard/summary", staff, dashboard.GetDashboardSummary)
+	authenticated.Get("/dashboard/monthly-trend", staff, dashboard.GetMonthlyBorrowingTrend)
+	authenticated.Get("/dashboard/latest-activity", staff, dashboard.GetLatestActivity)
+	authenticated.Get("/dashboard/top-borrowed-books", staff, dashboard.GetTopBorrowedBooks)
+	authenticated.Get("/dashboard/categories-distribution", staff, dashboard.GetBookCategoriesDistribution)
 }
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/config"       // Sesuaikan dengan nama proyekmu
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountService memegang aturan akun pengguna: pendaftaran, hashing password dan perubahan role
+type AccountService struct {
+	users repositories.UserRepository
+	cfg   *config.Config
+}
+
+// NewAccountService membuat AccountService
+func NewAccountService(users repositories.UserRepository, cfg *config.Config) *AccountService {
+	return &AccountService{users: users, cfg: cfg}
+}
+
+// UserUpdate berisi field pengguna yang diubah. String kosong berarti field tidak diubah.
+type UserUpdate struct {
+	Name     string
+	Email    string
+	Password string
+	Role     string
+}
+
+// List mengambil satu halaman pengguna
+func (s *AccountService) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.User, *helpers.Page, error) {
+	return s.users.List(ctx, query, p)
+}
+
+// All mengambil semua pengguna
+func (s *AccountService) All(ctx context.Context) ([]models.User, error) {
+	return s.users.All(ctx)
+}
+
+// Get mengambil pengguna beserta tier-nya
+func (s *AccountService) Get(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, newError(KindNotFound, "", "User not found")
+	}
+	return user, err
+}
+
+// Register mendaftarkan anggota baru dengan password yang di-hash. Pendaftaran selalu menjadi
+// member dengan tier default; role dan tier lain hanya bisa diberikan oleh staff setelahnya.
+func (s *AccountService) Register(ctx context.Context, user *models.User) error {
+	return s.create(ctx, user, models.RoleMember)
+}
+
+// CreateAdmin membuat akun admin baru untuk operator (subcommand create-admin). Email yang sudah
+// terdaftar ditolak: pendaftaran publik tidak memverifikasi email, jadi pemilik akun tersebut
+// belum tentu operator.
+func (s *AccountService) CreateAdmin(ctx context.Context, user *models.User) error {
+	if user.Email == "" || user.Password == "" {
+		return newError(KindInvalid, "", "Email and password are required")
+	}
+	if _, err := s.users.FindByEmail(ctx, user.Email); err == nil {
+		return newError(KindConflict, "", "A user with this email is already registered")
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+	return s.create(ctx, user, models.RoleAdmin)
+}
+
+// create menyimpan pengguna baru dengan password yang di-hash, role yang diberikan dan tier default
+func (s *AccountService) create(ctx context.Context, user *models.User, role string) error {
+	hashedPassword, err := hashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+
+	user.Role = role
+	user.TierID = nil
+	user.Tier = nil
+	if defaultTier, err := s.users.FindTierByCode(ctx, s.cfg.DefaultTierCode); err == nil {
+		user.TierID = &defaultTier.ID
+	}
+
+	return s.users.Create(ctx, user)
+}
+
+// Update memperbarui pengguna. Perubahan role hanya boleh dilakukan oleh admin. Selain admin,
+// password hanya boleh diganti oleh pemilik akun, dan email atau password akun staff lain
+// (admin dan librarian) tidak boleh diubah supaya librarian tidak bisa mengambil alih akun admin.
+func (s *AccountService) Update(ctx context.Context, id uuid.UUID, update UserUpdate, actor Actor) (*models.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCredentialChange(user, update, actor); err != nil {
+		return nil, err
+	}
+
+	if update.Name != "" {
+		user.Name = update.Name
+	}
+	if update.Email != "" {
+		user.Email = update.Email
+	}
+	if update.Role != "" && update.Role != user.Role {
+		if actor.Role != models.RoleAdmin {
+			return nil, newError(KindForbidden, "", "Only admin can change user role")
+		}
+		if !models.IsValidRole(update.Role) {
+			return nil, newError(KindInvalid, "", "Invalid role")
+		}
+		user.Role = update.Role
+	}
+	if update.Password != "" {
+		hashedPassword, err := hashPassword(update.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hashedPassword
+	}
+
+	if err := s.users.Save(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Delete menghapus pengguna
+func (s *AccountService) Delete(ctx context.Context, id uuid.UUID) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.users.Delete(ctx, user)
+}
+
+// checkCredentialChange menolak perubahan email atau password yang tidak boleh dilakukan actor
+func checkCredentialChange(user *models.User, update UserUpdate, actor Actor) error {
+	if actor.Role == models.RoleAdmin || user.ID == actor.UserID {
+		return nil
+	}
+	changesEmail := update.Email != "" && update.Email != user.Email
+	if update.Password != "" {
+		return newError(KindForbidden, "", "Only admin can change another user's password")
+	}
+	if changesEmail && (user.Role == models.RoleAdmin || user.Role == models.RoleLibrarian) {
+		return newError(KindForbidden, "", "Only admin can change the email of a staff account")
+	}
+	return nil
+}
+
+// hashPassword membuat hash bcrypt dari password
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("Could not hash password: %w", err)
+	}
+	return string(hashed), nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthorService memegang aturan data penulis: nama unik tanpa membedakan huruf besar/kecil,
+// kolom author buku yang ikut diganti saat penulis berganti nama, dan penulis yang masih
+// punya buku tidak boleh dihapus
+type AuthorService struct {
+	db *gorm.DB
+}
+
+// NewAuthorService membuat AuthorService
+func NewAuthorService(db *gorm.DB) *AuthorService {
+	return &AuthorService{db: db}
+}
+
+// List mengambil satu halaman penulis
+func (s *AuthorService) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Author, *helpers.Page, error) {
+	var authors []models.Author
+	page, err := helpers.Paginate(query.Filter(s.db.WithContext(ctx).Model(&models.Author{})), query, p, &authors)
+	return authors, page, err
+}
+
+// Get mengambil penulis beserta buku-bukunya, diurutkan berdasarkan judul
+func (s *AuthorService) Get(ctx context.Context, id uuid.UUID) (*models.Author, error) {
+	author := new(models.Author)
+	err := s.db.WithContext(ctx).Preload("Books", func(db *gorm.DB) *gorm.DB {
+		return db.Order("title ASC")
+	}).First(author, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, newError(KindNotFound, helpers.ErrCodeAuthorNotFound, "Author not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// Create menambahkan penulis baru
+func (s *AuthorService) Create(ctx context.Context, name string) (*models.Author, error) {
+	name, err := authorName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	author := &models.Author{Name: name}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureAuthorNameFree(tx, name, uuid.Nil); err != nil {
+			return err
+		}
+		return tx.Create(author).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// Rename mengganti nama penulis. Nama lama pada kolom author buku-bukunya ikut diganti.
+func (s *AuthorService) Rename(ctx context.Context, id uuid.UUID, name string) (*models.Author, error) {
+	name, err := authorName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	author := new(models.Author)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findAuthor(tx, author, id); err != nil {
+			return err
+		}
+		if err := ensureAuthorNameFree(tx, name, author.ID); err != nil {
+			return err
+		}
+
+		oldName := author.Name
+		author.Name = name
+		if err := tx.Save(author).Error; err != nil {
+			return err
+		}
+		// Nama lama diganti pada daftar penulis tiap buku tanpa mengubah urutannya
+		return tx.Exec(`UPDATE books SET author = array_to_string(ARRAY(
+				SELECT CASE WHEN LOWER(TRIM(part)) = LOWER(?) THEN ? ELSE TRIM(part) END
+				FROM unnest(string_to_array(books.author, ';')) WITH ORDINALITY AS names(part, n)
+				ORDER BY n
+			), ?), updated_at = NOW()
+			WHERE id IN (SELECT book_id FROM book_authors WHERE author_id = ?)`,
+			oldName, name, repositories.AuthorSeparator, author.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// Delete menghapus penulis yang tidak lagi dihubungkan ke buku mana pun
+func (s *AuthorService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		author := new(models.Author)
+		if err := findAuthor(tx, author, id); err != nil {
+			return err
+		}
+
+		var books int64
+		if err := tx.Model(&models.Book{}).
+			Where("id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", author.ID).
+			Count(&books).Error; err != nil {
+			return err
+		}
+		if books > 0 {
+			return newError(KindConflict, helpers.ErrCodeAuthorInUse, "Author still has books, reassign them first")
+		}
+
+		// Hubungan dengan buku yang sudah dihapus ikut dilepas
+		if err := tx.Exec("DELETE FROM book_authors WHERE author_id = ?", author.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(author).Error
+	})
+}
+
+// authorName merapikan nama penulis dan menolak nama kosong
+func authorName(name string) (string, error) {
+	name = repositories.NormalizeEntityName(name)
+	if name == "" {
+		return "", newError(KindInvalid, "", "Name is required")
+	}
+	return name, nil
+}
+
+// findAuthor mengambil penulis berdasarkan ID
+func findAuthor(tx *gorm.DB, author *models.Author, id uuid.UUID) error {
+	if err := tx.First(author, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newError(KindNotFound, helpers.ErrCodeAuthorNotFound, "Author not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureAuthorNameFree menolak nama yang sudah dipakai penulis lain (tanpa membedakan huruf besar/kecil)
+func ensureAuthorNameFree(tx *gorm.DB, name string, exceptID uuid.UUID) error {
+	var duplicates int64
+	if err := tx.Model(&models.Author{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).
+		Count(&duplicates).Error; err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return newError(KindConflict, helpers.ErrCodeAuthorExists, "An author with this name already exists")
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+)
+
+// CatalogService memegang aturan katalog buku: validasi ISBN, keunikan ISBN dan
+// penulis/kategori yang dihubungkan ke buku
+type CatalogService struct {
+	books repositories.BookRepository
+}
+
+// NewCatalogService membuat CatalogService
+func NewCatalogService(books repositories.BookRepository) *CatalogService {
+	return &CatalogService{books: books}
+}
+
+// BookUpdate berisi field buku yang diubah. String kosong dan pointer nil berarti field
+// tidak diubah; Links mengikuti aturan repositories.BookLinks.
+type BookUpdate struct {
+	Title           string
+	Isbn            string
+	Quantity        *int
+	ReplacementCost *int64
+	Links           repositories.BookLinks
+}
+
+// linkNotFoundCodes memetakan entitas pada repositories.NotFoundError ke kode error API
+var linkNotFoundCodes = map[string]string{
+	repositories.EntityAuthor:   helpers.ErrCodeAuthorNotFound,
+	repositories.EntityCategory: helpers.ErrCodeCategoryNotFound,
+}
+
+// List mengambil satu halaman buku
+func (s *CatalogService) List(ctx context.Context, query *helpers.ListQuery, p *helpers.Pagination) ([]models.Book, *helpers.Page, error) {
+	return s.books.List(ctx, query, p)
+}
+
+// All mengambil semua buku
+func (s *CatalogService) All(ctx context.Context) ([]models.Book, error) {
+	return s.books.All(ctx)
+}
+
+// Get mengambil buku beserta jumlah eksemplar yang tersedia
+func (s *CatalogService) Get(ctx context.Context, id uuid.UUID) (*models.Book, error) {
+	book, err := s.books.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, newError(KindNotFound, "", "Books not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return book, s.withAvailableCopies(ctx, book)
+}
+
+// GetByISBN mencari buku berdasarkan ISBN-10 atau ISBN-13, dengan atau tanpa tanda hubung
+func (s *CatalogService) GetByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	normalized, err := normalizeISBN(isbn)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.books.FindByISBN(ctx, normalized, false)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, newError(KindNotFound, helpers.ErrCodeBookNotFound, "Books not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return book, s.withAvailableCopies(ctx, book)
+}
+
+// Create menambahkan buku ke katalog. ISBN disimpan sebagai ISBN-13 tanpa tanda hubung dan
+// quantity dipakai untuk membuat eksemplar dengan barcode otomatis.
+func (s *CatalogService) Create(ctx context.Context, book *models.Book, links repositories.BookLinks) error {
+	if book.Quantity < 0 {
+		return newError(KindInvalid, "", "Quantity must not be negative")
+	}
+	if book.ReplacementCost < 0 {
+		return newError(KindInvalid, "", "Replacement cost must not be negative")
+	}
+	isbn, err := normalizeISBN(book.Isbn)
+	if err != nil {
+		return err
+	}
+	book.Isbn = isbn
+	if err := s.ensureISBNFree(ctx, isbn); err != nil {
+		return err
+	}
+
+	if err := s.books.Create(ctx, book, links); err != nil {
+		if linkErr := linkError(err); linkErr != err {
+			return linkErr
+		}
+		// Buku dengan ISBN yang sama bisa saja dibuat bersamaan setelah pengecekan di atas
+		if conflict := s.ensureISBNFree(ctx, isbn); conflict != nil {
+			return conflict
+		}
+		return err
+	}
+	return nil
+}
+
+// Update memperbarui buku. Quantity dihitung dari eksemplar sehingga tidak bisa diubah langsung.
+func (s *CatalogService) Update(ctx context.Context, id uuid.UUID, update BookUpdate) (*models.Book, error) {
+	book, err := s.books.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, newError(KindNotFound, "", "Books not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Title != "" {
+		book.Title = update.Title
+	}
+	if update.Isbn != "" {
+		isbn, err := normalizeISBN(update.Isbn)
+		if err != nil {
+			return nil, err
+		}
+		if isbn != book.Isbn {
+			if err := s.ensureISBNFree(ctx, isbn); err != nil {
+				return nil, err
+			}
+		}
+		book.Isbn = isbn
+	}
+	if update.Quantity != nil && *update.Quantity != book.Quantity {
+		return nil, newError(KindInvalid, "", "Quantity is derived from book copies, add or withdraw copies via /books/:id/copies")
+	}
+	if update.ReplacementCost != nil {
+		if *update.ReplacementCost < 0 {
+			return nil, newError(KindInvalid, "", "Replacement cost must not be negative")
+		}
+		book.ReplacementCost = *update.ReplacementCost
+	}
+
+	if err := s.books.Update(ctx, book, update.Links); err != nil {
+		return nil, linkError(err)
+	}
+	return book, nil
+}
+
+// Delete menghapus buku dari katalog
+func (s *CatalogService) Delete(ctx context.Context, id uuid.UUID) error {
+	book, err := s.books.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return newError(KindNotFound, "", "Books not found")
+	}
+	if err != nil {
+		return err
+	}
+	return s.books.Delete(ctx, book)
+}
+
+// ensureISBNFree menolak ISBN yang sudah dipakai buku lain, termasuk buku yang sudah dihapus.
+// Buku tersebut dikembalikan di Data.
+func (s *CatalogService) ensureISBNFree(ctx context.Context, isbn string) error {
+	existing, err := s.books.FindByISBN(ctx, isbn, true)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	conflict := newError(KindConflict, helpers.ErrCodeISBNExists, "A book with this ISBN already exists")
+	conflict.Data = existing
+	return conflict
+}
+
+// withAvailableCopies mengisi jumlah eksemplar buku yang tersedia
+func (s *CatalogService) withAvailableCopies(ctx context.Context, book *models.Book) error {
+	available, err := s.books.AvailableCopies(ctx, book.ID)
+	if err != nil {
+		return err
+	}
+	book.AvailableCopies = &available
+	return nil
+}
+
+// normalizeISBN memvalidasi ISBN dan mengubahnya menjadi ISBN-13 tanpa tanda hubung
+func normalizeISBN(isbn string) (string, error) {
+	normalized, err := helpers.NormalizeISBN(isbn)
+	if err != nil {
+		return "", newError(KindInvalid, helpers.ErrCodeInvalidISBN, "ISBN must be a valid ISBN-10 or ISBN-13")
+	}
+	return normalized, nil
+}
+
+// linkError mengubah penulis atau kategori yang dirujuk tetapi tidak ditemukan menjadi Error
+func linkError(err error) error {
+	var notFoundErr *repositories.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return newError(KindNotFound, linkNotFoundCodes[notFoundErr.Entity], notFoundErr.Error())
+	}
+	return err
+}
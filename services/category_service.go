@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CategoryService memegang aturan pohon kategori: nama unik tanpa membedakan huruf besar/kecil,
+// pohon tanpa siklus, dan kategori yang masih punya subkategori atau buku tidak boleh dihapus
+type CategoryService struct {
+	db *gorm.DB
+}
+
+// NewCategoryService membuat CategoryService
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	return &CategoryService{db: db}
+}
+
+// CategoryUpdate berisi perubahan kategori. Name kosong berarti nama tidak diubah, dan induk
+// hanya diubah jika Move bernilai true (ParentID nil menjadikannya kategori utama).
+type CategoryUpdate struct {
+	Name     string
+	Move     bool
+	ParentID *uuid.UUID
+}
+
+// All mengambil semua kategori, diurutkan berdasarkan nama
+func (s *CategoryService) All(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	err := s.db.WithContext(ctx).Order("name ASC").Find(&categories).Error
+	return categories, err
+}
+
+// Tree mengambil semua kategori sebagai pohon, setiap tingkat diurutkan berdasarkan nama
+func (s *CategoryService) Tree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// Get mengambil kategori beserta subkategori langsungnya
+func (s *CategoryService) Get(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	category := new(models.Category)
+	err := s.db.WithContext(ctx).Preload("Children", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).First(category, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, newError(KindNotFound, helpers.ErrCodeCategoryNotFound, "Category not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Create membuat kategori baru, sebagai subkategori jika parentID diisi
+func (s *CategoryService) Create(ctx context.Context, name string, parentID *uuid.UUID) (*models.Category, error) {
+	name = repositories.NormalizeEntityName(name)
+	if name == "" {
+		return nil, newError(KindInvalid, "", "Name is required")
+	}
+
+	category := &models.Category{Name: name, ParentID: parentID}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureCategoryNameFree(tx, name, uuid.Nil); err != nil {
+			return err
+		}
+		if err := ensureCategoryParent(tx, uuid.Nil, parentID); err != nil {
+			return err
+		}
+		return tx.Create(category).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Update mengganti nama atau memindahkan kategori ke induk lain. Kategori tidak bisa dipindahkan
+// ke bawah dirinya sendiri atau subkategorinya. Kolom category buku ikut diganti saat berganti nama.
+func (s *CategoryService) Update(ctx context.Context, id uuid.UUID, update CategoryUpdate) (*models.Category, error) {
+	name := repositories.NormalizeEntityName(update.Name)
+
+	category := new(models.Category)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findCategory(tx, category, id); err != nil {
+			return err
+		}
+
+		if update.Move {
+			if err := ensureCategoryParent(tx, category.ID, update.ParentID); err != nil {
+				return err
+			}
+			category.ParentID = update.ParentID
+		}
+		oldName := category.Name
+		if name != "" && name != oldName {
+			if err := ensureCategoryNameFree(tx, name, category.ID); err != nil {
+				return err
+			}
+			category.Name = name
+		}
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		if category.Name == oldName {
+			return nil
+		}
+		// Kolom category pada buku berisi nama kategori utamanya, jadi ikut diganti
+		return tx.Exec(`UPDATE books SET category = ?, updated_at = NOW()
+			WHERE LOWER(category) = LOWER(?) AND id IN (SELECT book_id FROM book_categories WHERE category_id = ?)`,
+			category.Name, oldName, category.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Delete menghapus kategori yang tidak punya subkategori maupun buku
+func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		category := new(models.Category)
+		if err := findCategory(tx, category, id); err != nil {
+			return err
+		}
+
+		var children int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return newError(KindConflict, helpers.ErrCodeCategoryInUse, "Category still has subcategories, move or delete them first")
+		}
+		var books int64
+		if err := tx.Model(&models.Book{}).
+			Where("id IN (SELECT book_id FROM book_categories WHERE category_id = ?)", category.ID).
+			Count(&books).Error; err != nil {
+			return err
+		}
+		if books > 0 {
+			return newError(KindConflict, helpers.ErrCodeCategoryInUse, "Category still has books, reassign them first")
+		}
+
+		// Hubungan dengan buku yang sudah dihapus ikut dilepas
+		if err := tx.Exec("DELETE FROM book_categories WHERE category_id = ?", category.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
+
+// findCategory mengambil kategori berdasarkan ID
+func findCategory(tx *gorm.DB, category *models.Category, id uuid.UUID) error {
+	if err := tx.First(category, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newError(KindNotFound, helpers.ErrCodeCategoryNotFound, "Category not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureCategoryNameFree menolak nama yang sudah dipakai kategori lain (tanpa membedakan huruf besar/kecil)
+func ensureCategoryNameFree(tx *gorm.DB, name string, exceptID uuid.UUID) error {
+	var duplicates int64
+	if err := tx.Model(&models.Category{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).
+		Count(&duplicates).Error; err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return newError(KindConflict, helpers.ErrCodeCategoryExists, "A category with this name already exists")
+	}
+	return nil
+}
+
+// ensureCategoryParent memastikan induk kategori ada dan bukan kategori itu sendiri atau
+// salah satu subkategorinya, supaya pohon kategori tidak membentuk siklus
+func ensureCategoryParent(tx *gorm.DB, categoryID uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+	if err := tx.First(&models.Category{}, "id = ?", *parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newError(KindNotFound, helpers.ErrCodeCategoryNotFound, "Parent category not found")
+		}
+		return err
+	}
+	if categoryID == uuid.Nil {
+		return nil
+	}
+
+	var cycles int64
+	if err := tx.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM categories WHERE id = ?
+			UNION
+			SELECT categories.id, categories.parent_id FROM categories JOIN ancestors ON categories.id = ancestors.parent_id
+		) SELECT COUNT(*) FROM ancestors WHERE id = ?`, *parentID, categoryID).Scan(&cycles).Error; err != nil {
+		return err
+	}
+	if cycles > 0 {
+		return newError(KindInvalid, helpers.ErrCodeCategoryCycle, "A category cannot be moved under itself or its subcategories")
+	}
+	return nil
+}
+
+// categoryAncestryCTE memasangkan setiap kategori dengan dirinya dan semua leluhurnya
+// (kolom category_id dan ancestor_id), dipakai untuk menghitung buku subkategori pada induknya
+const categoryAncestryCTE = `WITH RECURSIVE ancestry AS (
+		SELECT id AS category_id, id AS ancestor_id FROM categories
+		UNION
+		SELECT ancestry.category_id, categories.parent_id FROM ancestry
+		JOIN categories ON categories.id = ancestry.ancestor_id
+		WHERE categories.parent_id IS NOT NULL
+	)`
+
+// buildCategoryTree menyusun daftar kategori menjadi pohon. Urutan anak mengikuti urutan
+// categories; kategori yang induknya tidak ada di daftar dianggap kategori utama.
+func buildCategoryTree(categories []models.Category) []models.Category {
+	children := make(map[uuid.UUID][]models.Category)
+	known := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID != nil && known[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	if roots == nil {
+		return []models.Category{}
+	}
+	return attach(roots)
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/config"       // Sesuaikan dengan nama proyekmu
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CirculationService memegang aturan sirkulasi: peminjaman, pengembalian, perpanjangan,
+// antrean hold dan denda. Setiap operasi berjalan dalam satu transaksi karena eksemplar,
+// batas tier, denda dan antrean hold saling bergantung.
+type CirculationService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewCirculationService membuat CirculationService
+func NewCirculationService(db *gorm.DB, cfg *config.Config) *CirculationService {
+	return &CirculationService{db: db, cfg: cfg}
+}
+
+// Checkout meminjamkan buku dan mengembalikan record (dengan Book, User dan Copy) beserta sisa
+// eksemplar. Eksemplar dipilih dan dikunci di dalam transaksi, sehingga dua peminjaman bersamaan
+// tidak bisa mengambil eksemplar yang sama. Jika peminjam memiliki hold ready,
+// eksemplar yang disisihkan untuknya yang dipinjamkan. Hold ready yang sudah melewati batas
+// pengambilan dianggap expired meskipun job expire-holds belum berjalan.
+func (s *CirculationService) Checkout(ctx context.Context, bookID uuid.UUID, userID uuid.UUID, borrowDate time.Time) (*models.Lending_records, int, error) {
+	var record *models.Lending_records
+	var remaining int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		book := new(models.Book)
+		if err := tx.First(book, "id = ?", bookID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindNotFound, helpers.ErrCodeBookNotFound, "Book not found")
+			}
+			return err
+		}
+		if err := lockUser(tx, userID); err != nil {
+			return err
+		}
+		if err := expireLapsedHolds(tx, book.ID, s.cfg, time.Now()); err != nil {
+			return err
+		}
+
+		bookCopy, err := reserveCopyFor(tx, book.ID, userID)
+		if err != nil {
+			return err
+		}
+
+		record, err = s.checkoutCopy(tx, bookCopy, book, userID, borrowDate)
+		if err != nil {
+			return err
+		}
+
+		remaining, err = repositories.CountAvailableCopies(tx, book.ID)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.preloadRecord(ctx, record); err != nil {
+		return nil, 0, err
+	}
+	return record, remaining, nil
+}
+
+// CheckoutByBarcode meminjamkan eksemplar hasil pindaian barcode kepada anggota
+func (s *CirculationService) CheckoutByBarcode(ctx context.Context, barcode string, userID uuid.UUID) (*models.Lending_records, error) {
+	var record *models.Lending_records
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUser(tx, userID); err != nil {
+			return err
+		}
+
+		// Hold yang kedaluwarsa dilepas sebelum eksemplar dikunci, urutan yang sama dengan job expire-holds
+		scanned, err := findCopyByBarcode(tx, barcode)
+		if err != nil {
+			return err
+		}
+		if err := expireLapsedHolds(tx, scanned.BookID, s.cfg, time.Now()); err != nil {
+			return err
+		}
+
+		bookCopy, err := lockCopyByBarcode(tx, barcode)
+		if err != nil {
+			return err
+		}
+		book := new(models.Book)
+		if err := tx.First(book, "id = ?", bookCopy.BookID).Error; err != nil {
+			return err
+		}
+
+		record, err = s.checkoutCopy(tx, bookCopy, book, userID, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.preloadRecord(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Return mencatat pengembalian dan mengembalikan record beserta denda keterlambatannya.
+// Hanya staff yang dapat mencatat pengembalian, karena buku harus diserahkan di meja sirkulasi.
+func (s *CirculationService) Return(ctx context.Context, recordID uuid.UUID, actor Actor) (*models.Lending_records, int64, error) {
+	if !actor.IsStaff() {
+		return nil, 0, newError(KindForbidden, "", "Only staff can record a return")
+	}
+
+	record := new(models.Lending_records)
+	var fine int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOwnRecord(tx.Preload("Book"), record, recordID, actor); err != nil {
+			return err
+		}
+		if !record.IsOpen() {
+			return newError(KindConflict, helpers.ErrCodeRecordNotOpen, "This loan has already been closed")
+		}
+
+		charged, err := s.closeLoan(tx, record, time.Now())
+		fine = charged
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.preloadRecord(ctx, record); err != nil {
+		return nil, 0, err
+	}
+	return record, fine, nil
+}
+
+// ReturnByBarcode memproses pengembalian eksemplar hasil pindaian barcode
+func (s *CirculationService) ReturnByBarcode(ctx context.Context, barcode string) (*models.Lending_records, int64, error) {
+	record := new(models.Lending_records)
+	var fine int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bookCopy, err := lockCopyByBarcode(tx, barcode)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Book").
+			Where("copy_id = ? AND return_date IS NULL AND status <> ?", bookCopy.ID, models.RecordStatusLost).
+			First(record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindConflict, helpers.ErrCodeCopyNotOnLoan, "This copy is not currently on loan")
+			}
+			return err
+		}
+
+		fine, err = s.closeLoan(tx, record, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.preloadRecord(ctx, record); err != nil {
+		return nil, 0, err
+	}
+	return record, fine, nil
+}
+
+// Renew memperpanjang jatuh tempo peminjaman sebesar lama peminjaman tier peminjam dan
+// mengembalikan sisa perpanjangan yang masih boleh dilakukan. Perpanjangan ditolak jika batas
+// perpanjangan tier tercapai, keterlambatan melewati masa toleransi, atau ada anggota lain
+// yang mengantre buku tersebut.
+func (s *CirculationService) Renew(ctx context.Context, recordID uuid.UUID, actor Actor) (*models.Lending_records, int, error) {
+	record := new(models.Lending_records)
+	var policy *loanPolicy
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOwnRecord(tx, record, recordID, actor); err != nil {
+			return err
+		}
+		if !record.IsOpen() {
+			return newError(KindConflict, helpers.ErrCodeRecordNotOpen, "This loan has already been closed")
+		}
+		borrowerID := record.User_id
+		var err error
+		policy, err = loanPolicyFor(tx, borrowerID, s.cfg)
+		if err != nil {
+			return err
+		}
+		if record.RenewalCount >= policy.MaxRenewals {
+			return newError(KindConflict, helpers.ErrCodeRenewalLimitReached,
+				fmt.Sprintf("Your %s membership allows at most %d renewals per loan", policy.TierCode, policy.MaxRenewals))
+		}
+
+		now := time.Now()
+		graceEnd := record.DueDate.AddDate(0, 0, s.cfg.RenewalGraceDays)
+		if now.After(graceEnd) {
+			return newError(KindConflict, helpers.ErrCodeRenewalOverdue,
+				"This loan is overdue beyond the renewal grace period, please return the book")
+		}
+
+		book := new(models.Book)
+		if err := tx.First(book, "id = ?", record.Book_id).Error; err != nil {
+			return err
+		}
+
+		held, err := hasWaitingHoldsFromOthers(tx, book.ID, borrowerID)
+		if err != nil {
+			return err
+		}
+		if held {
+			return newError(KindConflict, helpers.ErrCodeRenewalHoldPending,
+				"Another member has a hold on this book, the loan cannot be renewed")
+		}
+
+		previousDueDate := record.DueDate
+		record.DueDate = previousDueDate.AddDate(0, 0, policy.loanPeriodFor(book.Category))
+		record.RenewalCount++
+
+		if err := tx.Model(record).Updates(map[string]interface{}{
+			"due_date":      record.DueDate,
+			"renewal_count": record.RenewalCount,
+			"status":        models.RecordStatusBorrowed,
+		}).Error; err != nil {
+			return err
+		}
+		record.Status = models.RecordStatusBorrowed
+		record.SyncOverdueStatus(now)
+
+		return tx.Create(&models.Renewal{
+			RecordID:        record.ID,
+			RenewedBy:       actor.UserID,
+			PreviousDueDate: previousDueDate,
+			NewDueDate:      record.DueDate,
+		}).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.db.WithContext(ctx).Preload("Book").Preload("User").First(record, "id = ?", record.ID).Error; err != nil {
+		return nil, 0, fmt.Errorf("Failed to preload related data: %w", err)
+	}
+	return record, policy.MaxRenewals - record.RenewalCount, nil
+}
+
+// RenewalHistory mengambil riwayat perpanjangan sebuah peminjaman. Selain staff, actor hanya
+// dapat melihat riwayat peminjamannya sendiri.
+func (s *CirculationService) RenewalHistory(ctx context.Context, recordID uuid.UUID, actor Actor) ([]models.Renewal, error) {
+	db := s.db.WithContext(ctx)
+	if err := findOwnRecord(db, new(models.Lending_records), recordID, actor); err != nil {
+		return nil, err
+	}
+
+	var renewals []models.Renewal
+	err := db.Where("record_id = ?", recordID).Order("created_at ASC").Find(&renewals).Error
+	return renewals, err
+}
+
+// MarkLost menandai buku yang dipinjam sebagai hilang, lalu menagihkan biaya penggantian dan
+// denda keterlambatan yang sudah berjalan. Mengembalikan record beserta total yang ditagihkan.
+func (s *CirculationService) MarkLost(ctx context.Context, recordID uuid.UUID, staffID uuid.UUID) (*models.Lending_records, int64, error) {
+	record := new(models.Lending_records)
+	var charged int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Book").First(record, "id = ?", recordID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindNotFound, helpers.ErrCodeRecordNotFound, "Records not found")
+			}
+			return err
+		}
+		if !record.IsOpen() {
+			return newError(KindConflict, helpers.ErrCodeRecordNotOpen, "This loan has already been closed")
+		}
+
+		overdueFine, err := s.chargeOverdueFine(tx, record, &record.Book, time.Now())
+		if err != nil {
+			return err
+		}
+		charged = overdueFine
+
+		if record.Book.ReplacementCost > 0 {
+			if err := tx.Create(&models.FineTransaction{
+				UserID:    record.User_id,
+				RecordID:  &record.ID,
+				Type:      models.FineTypeLostItem,
+				Amount:    record.Book.ReplacementCost,
+				Note:      fmt.Sprintf("Replacement cost for %q", record.Book.Title),
+				CreatedBy: &staffID,
+			}).Error; err != nil {
+				return err
+			}
+			charged += record.Book.ReplacementCost
+		}
+
+		record.Status = models.RecordStatusLost
+		if err := tx.Model(record).Update("status", models.RecordStatusLost).Error; err != nil {
+			return err
+		}
+
+		// Eksemplar yang hilang tidak lagi termasuk koleksi
+		if record.CopyID != nil {
+			if err := setCopyStatus(tx, *record.CopyID, models.CopyStatusLost); err != nil {
+				return err
+			}
+		}
+		return repositories.SyncBookQuantity(tx, record.Book.ID)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return record, charged, nil
+}
+
+// checkoutCopy membuat peminjaman untuk eksemplar dan anggota yang sudah dikunci oleh pemanggil
+// setelah memeriksa saldo denda dan batas pinjaman tier anggota.
+// Eksemplar on_hold hanya bisa dipinjam oleh anggota pemilik hold ready-nya.
+func (s *CirculationService) checkoutCopy(tx *gorm.DB, bookCopy *models.BookCopy, book *models.Book, userID uuid.UUID, borrowDate time.Time) (*models.Lending_records, error) {
+	if err := s.ensureFinesBelowLimit(tx, userID); err != nil {
+		return nil, err
+	}
+	policy, err := loanPolicyFor(tx, userID, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.ensureLoanLimit(tx, userID); err != nil {
+		return nil, err
+	}
+
+	readyHold := new(models.Hold)
+	err = tx.Where("book_id = ? AND user_id = ? AND status = ?", book.ID, userID, models.HoldStatusReady).First(readyHold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		readyHold = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch bookCopy.Status {
+	case models.CopyStatusAvailable:
+	case models.CopyStatusOnHold:
+		if readyHold == nil || readyHold.CopyID == nil || *readyHold.CopyID != bookCopy.ID {
+			return nil, newError(KindConflict, helpers.ErrCodeCopyOnHold, "This copy is reserved for another member")
+		}
+	default:
+		return nil, newError(KindConflict, helpers.ErrCodeCopyUnavailable, "This copy cannot be borrowed, its status is "+bookCopy.Status)
+	}
+
+	record := &models.Lending_records{
+		Book_id:     book.ID,
+		User_id:     userID,
+		CopyID:      &bookCopy.ID,
+		Borrow_date: borrowDate,
+		// Jatuh tempo mengikuti lama peminjaman tier peminjam dan kategori buku
+		DueDate: borrowDate.AddDate(0, 0, policy.loanPeriodFor(book.Category)),
+		Status:  models.RecordStatusBorrowed,
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return nil, err
+	}
+	if err := setCopyStatus(tx, bookCopy.ID, models.CopyStatusOnLoan); err != nil {
+		return nil, err
+	}
+
+	if readyHold != nil {
+		if err := tx.Model(readyHold).Update("status", models.HoldStatusFulfilled).Error; err != nil {
+			return nil, err
+		}
+		// Anggota meminjam eksemplar lain dari yang disisihkan, lepaskan eksemplar tersebut ke antrean
+		if readyHold.CopyID != nil && *readyHold.CopyID != bookCopy.ID {
+			if err := setCopyStatus(tx, *readyHold.CopyID, models.CopyStatusAvailable); err != nil {
+				return nil, err
+			}
+			if err := PromoteHolds(tx, book.ID, s.cfg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return record, nil
+}
+
+// closeLoan menutup peminjaman yang sudah dikunci (dengan Book ter-preload): mencatat denda,
+// mengembalikan eksemplar ke rak, lalu menyisihkannya untuk antrean hold berikutnya
+func (s *CirculationService) closeLoan(tx *gorm.DB, record *models.Lending_records, returnedAt time.Time) (int64, error) {
+	fine, err := s.chargeOverdueFine(tx, record, &record.Book, returnedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	record.ReturnDate = &returnedAt
+	record.Status = models.RecordStatusReturned
+	if err := tx.Model(record).Updates(map[string]interface{}{
+		"return_date": returnedAt,
+		"status":      models.RecordStatusReturned,
+	}).Error; err != nil {
+		return 0, err
+	}
+
+	if record.CopyID != nil {
+		if err := setCopyStatus(tx, *record.CopyID, models.CopyStatusAvailable); err != nil {
+			return 0, err
+		}
+	}
+
+	return fine, PromoteHolds(tx, record.Book.ID, s.cfg)
+}
+
+// reserveCopyFor mengunci eksemplar yang akan dipinjamkan kepada pengguna: eksemplar dari
+// hold ready miliknya jika ada, selain itu eksemplar available pertama
+func reserveCopyFor(tx *gorm.DB, bookID uuid.UUID, userID uuid.UUID) (*models.BookCopy, error) {
+	readyHold := new(models.Hold)
+	err := tx.Where("book_id = ? AND user_id = ? AND status = ? AND copy_id IS NOT NULL", bookID, userID, models.HoldStatusReady).
+		First(readyHold).Error
+	if err == nil {
+		bookCopy := new(models.BookCopy)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(bookCopy, "id = ?", *readyHold.CopyID).Error; err != nil {
+			return nil, err
+		}
+		return bookCopy, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	bookCopy, err := lockAvailableCopy(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if bookCopy == nil {
+		return nil, newError(KindConflict, helpers.ErrCodeBookUnavailable, "No copies of this book are currently available")
+	}
+	return bookCopy, nil
+}
+
+// lockUser mengunci baris anggota selama transaksi peminjaman. Anggota selalu dikunci sebelum
+// eksemplar, sehingga peminjaman bersamaan oleh anggota yang sama dihitung satu per satu
+// terhadap batas pinjaman dan saldo denda.
+func lockUser(tx *gorm.DB, userID uuid.UUID) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.User{}, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newError(KindNotFound, helpers.ErrCodeUserNotFound, "User not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// lockOwnRecord mengunci record peminjaman milik actor, dengan aturan yang sama seperti findOwnRecord
+func lockOwnRecord(tx *gorm.DB, record *models.Lending_records, recordID uuid.UUID, actor Actor) error {
+	return findOwnRecord(tx.Clauses(clause.Locking{Strength: "UPDATE"}), record, recordID, actor)
+}
+
+// findOwnRecord mengambil record peminjaman. Peminjaman milik anggota lain dilaporkan tidak
+// ditemukan kecuali actor adalah staff, supaya keberadaannya tidak bocor.
+func findOwnRecord(db *gorm.DB, record *models.Lending_records, recordID uuid.UUID, actor Actor) error {
+	if err := db.First(record, "id = ?", recordID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newError(KindNotFound, helpers.ErrCodeRecordNotFound, "Records not found")
+		}
+		return err
+	}
+	if !actor.IsStaff() && record.User_id != actor.UserID {
+		return newError(KindNotFound, helpers.ErrCodeRecordNotFound, "Records not found")
+	}
+	return nil
+}
+
+// preloadRecord memuat ulang record beserta relasi Book, User dan Copy
+func (s *CirculationService) preloadRecord(ctx context.Context, record *models.Lending_records) error {
+	if err := s.db.WithContext(ctx).Preload("Book").Preload("User").Preload("Copy").First(record, "id = ?", record.ID).Error; err != nil {
+		return fmt.Errorf("Failed to preload related data: %w", err)
+	}
+	return nil
+}
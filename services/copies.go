@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CopyInput berisi data eksemplar dari staff. Saat memperbarui eksemplar, field kosong tidak diubah.
+type CopyInput struct {
+	Barcode       string
+	ShelfLocation string
+	Condition     string
+	Status        string
+	AcquiredAt    *time.Time
+}
+
+// Copies mengambil semua eksemplar sebuah buku, diurutkan berdasarkan barcode
+func (s *CirculationService) Copies(ctx context.Context, bookID uuid.UUID) ([]models.BookCopy, error) {
+	var copies []models.BookCopy
+	err := s.db.WithContext(ctx).Where("book_id = ?", bookID).Order("barcode ASC").Find(&copies).Error
+	return copies, err
+}
+
+// CopyByBarcode mengambil eksemplar beserta bukunya berdasarkan hasil pindaian barcode
+func (s *CirculationService) CopyByBarcode(ctx context.Context, barcode string) (*models.BookCopy, error) {
+	return findCopyByBarcode(s.db.WithContext(ctx).Preload("Book"), barcode)
+}
+
+// AddCopy mendaftarkan eksemplar baru untuk sebuah buku. Eksemplar baru bisa langsung
+// disisihkan untuk hold terdepan, sehingga statusnya bisa on_hold.
+func (s *CirculationService) AddCopy(ctx context.Context, bookID uuid.UUID, input CopyInput) (*models.BookCopy, error) {
+	input.Barcode = strings.TrimSpace(input.Barcode)
+	if input.Barcode == "" {
+		return nil, newError(KindInvalid, "", "Barcode is required")
+	}
+	if input.Condition != "" && !models.IsValidCopyCondition(input.Condition) {
+		return nil, newError(KindInvalid, "", "Invalid condition")
+	}
+
+	bookCopy := &models.BookCopy{
+		BookID:        bookID,
+		Barcode:       input.Barcode,
+		ShelfLocation: input.ShelfLocation,
+		Condition:     input.Condition,
+		Status:        models.CopyStatusAvailable,
+		AcquiredAt:    input.AcquiredAt,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Book{}, "id = ?", bookID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindNotFound, helpers.ErrCodeBookNotFound, "Book not found")
+			}
+			return err
+		}
+
+		var duplicates int64
+		if err := tx.Model(&models.BookCopy{}).Where("barcode = ?", bookCopy.Barcode).Count(&duplicates).Error; err != nil {
+			return err
+		}
+		if duplicates > 0 {
+			return newError(KindConflict, helpers.ErrCodeBarcodeExists, "A copy with this barcode already exists")
+		}
+
+		if err := tx.Create(bookCopy).Error; err != nil {
+			return err
+		}
+		if err := repositories.SyncBookQuantity(tx, bookID); err != nil {
+			return err
+		}
+		// Eksemplar baru bisa langsung melayani antrean hold
+		if err := PromoteHolds(tx, bookID, s.cfg); err != nil {
+			return err
+		}
+		return tx.First(bookCopy, "id = ?", bookCopy.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookCopy, nil
+}
+
+// UpdateCopy memperbarui lokasi rak, kondisi atau status eksemplar. Status hanya bisa diatur
+// manual antara available, damaged dan withdrawn; eksemplar yang sedang dipinjam, disisihkan
+// atau hilang diatur oleh alur sirkulasi.
+func (s *CirculationService) UpdateCopy(ctx context.Context, copyID uuid.UUID, input CopyInput) (*models.BookCopy, error) {
+	if input.Condition != "" && !models.IsValidCopyCondition(input.Condition) {
+		return nil, newError(KindInvalid, "", "Invalid condition")
+	}
+	if input.Status != "" && !models.IsManualCopyStatus(input.Status) {
+		return nil, newError(KindInvalid, "", "Status can only be set to available, damaged or withdrawn")
+	}
+
+	bookCopy := new(models.BookCopy)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(bookCopy, "id = ?", copyID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindNotFound, helpers.ErrCodeCopyNotFound, "Copy not found")
+			}
+			return err
+		}
+
+		if input.ShelfLocation != "" {
+			bookCopy.ShelfLocation = input.ShelfLocation
+		}
+		if input.Condition != "" {
+			bookCopy.Condition = input.Condition
+		}
+		if input.AcquiredAt != nil {
+			bookCopy.AcquiredAt = input.AcquiredAt
+		}
+		statusChanged := input.Status != "" && input.Status != bookCopy.Status
+		if statusChanged {
+			if !models.IsManualCopyStatus(bookCopy.Status) {
+				return newError(KindConflict, helpers.ErrCodeCopyUnavailable,
+					"The status of a copy that is "+bookCopy.Status+" is managed by circulation")
+			}
+			bookCopy.Status = input.Status
+		}
+
+		if err := tx.Save(bookCopy).Error; err != nil {
+			return err
+		}
+		if !statusChanged {
+			return nil
+		}
+		if err := repositories.SyncBookQuantity(tx, bookCopy.BookID); err != nil {
+			return err
+		}
+		// Status bisa berubah menjadi on_hold setelah PromoteHolds
+		if err := PromoteHolds(tx, bookCopy.BookID, s.cfg); err != nil {
+			return err
+		}
+		return tx.First(bookCopy, "id = ?", bookCopy.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookCopy, nil
+}
+
+// lockAvailableCopy mengunci satu eksemplar available milik buku. Baris yang sedang dikunci
+// transaksi lain dilewati, sehingga peminjaman bersamaan mendapat eksemplar yang berbeda.
+// Mengembalikan nil jika tidak ada eksemplar yang tersedia.
+func lockAvailableCopy(tx *gorm.DB, bookID uuid.UUID) (*models.BookCopy, error) {
+	bookCopy := new(models.BookCopy)
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("book_id = ? AND status = ?", bookID, models.CopyStatusAvailable).
+		Order("created_at ASC").
+		First(bookCopy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bookCopy, nil
+}
+
+// lockCopyByBarcode mengunci eksemplar berdasarkan barcode
+func lockCopyByBarcode(tx *gorm.DB, barcode string) (*models.BookCopy, error) {
+	return findCopyByBarcode(tx.Clauses(clause.Locking{Strength: "UPDATE"}), barcode)
+}
+
+// findCopyByBarcode mengambil eksemplar berdasarkan barcode tanpa menguncinya
+func findCopyByBarcode(db *gorm.DB, barcode string) (*models.BookCopy, error) {
+	bookCopy := new(models.BookCopy)
+	if err := db.Where("barcode = ?", barcode).First(bookCopy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newError(KindNotFound, helpers.ErrCodeCopyNotFound, "No copy found with this barcode")
+		}
+		return nil, err
+	}
+	return bookCopy, nil
+}
+
+// setCopyStatus mengubah status sebuah eksemplar
+func setCopyStatus(tx *gorm.DB, copyID uuid.UUID, status string) error {
+	return tx.Model(&models.BookCopy{}).Where("id = ?", copyID).Update("status", status).Error
+}
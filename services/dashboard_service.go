@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"library/models" // Sesuaikan dengan nama proyekmu
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DashboardService menghitung statistik untuk dashboard staff
+type DashboardService struct {
+	db *gorm.DB
+}
+
+// NewDashboardService membuat DashboardService
+func NewDashboardService(db *gorm.DB) *DashboardService {
+	return &DashboardService{db: db}
+}
+
+// DashboardSummary adalah ringkasan dashboard
+type DashboardSummary struct {
+	TotalBooks          int64   `json:"total_books"`
+	TotalMembers        int64   `json:"total_members"` // Menggunakan TotalMembers, asumsikan semua user adalah anggota
+	BorrowingsThisMonth int64   `json:"borrowings_this_month"`
+	AvgDailyBorrowings  float64 `json:"avg_daily_borrowings"`
+}
+
+// Summary menghitung total buku, total anggota, serta peminjaman dan rata-rata peminjaman
+// harian pada bulan now
+func (s *DashboardService) Summary(ctx context.Context, now time.Time) (*DashboardSummary, error) {
+	db := s.db.WithContext(ctx)
+
+	var totalBooks int64
+	if err := db.Model(&models.Book{}).Count(&totalBooks).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil total buku: %w", err)
+	}
+
+	var totalMembers int64
+	// Menghitung total semua user sebagai anggota
+	if err := db.Model(&models.User{}).Count(&totalMembers).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil total anggota: %w", err)
+	}
+
+	// Hitung peminjaman bulan ini
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Nanosecond) // Hari terakhir bulan ini
+
+	var borrowingsThisMonth int64
+	if err := db.Model(&models.Lending_records{}).
+		Where("borrow_date BETWEEN ? AND ?", startOfMonth, endOfMonth).
+		Count(&borrowingsThisMonth).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil peminjaman bulan ini: %w", err)
+	}
+
+	// Hitung rata-rata peminjaman harian untuk bulan ini
+	var distinctBorrowingDays int64
+	sqlQuery := `SELECT COUNT(DISTINCT DATE(borrow_date)) FROM lending_records WHERE borrow_date BETWEEN ? AND ?`
+	if err := db.Raw(sqlQuery, startOfMonth, endOfMonth).Scan(&distinctBorrowingDays).Error; err != nil {
+		return nil, fmt.Errorf("Gagal menghitung hari peminjaman unik: %w", err)
+	}
+
+	var avgDailyBorrowings float64
+	if distinctBorrowingDays > 0 {
+		avgDailyBorrowings = float64(borrowingsThisMonth) / float64(distinctBorrowingDays)
+	}
+
+	return &DashboardSummary{
+		TotalBooks:          totalBooks,
+		TotalMembers:        totalMembers,
+		BorrowingsThisMonth: borrowingsThisMonth,
+		AvgDailyBorrowings:  avgDailyBorrowings,
+	}, nil
+}
+
+// MonthlyTrend adalah jumlah peminjaman dan pengembalian pada satu bulan
+type MonthlyTrend struct {
+	MonthName  string `json:"month"`
+	MonthNum   int    `json:"month_num"`
+	Borrowings int64  `json:"borrowings"`
+	Returns    int64  `json:"returns"`
+}
+
+// monthCount adalah hasil hitung per bulan
+type monthCount struct {
+	Month int   `gorm:"column:month"`
+	Count int64 `gorm:"column:count"`
+}
+
+// MonthlyTrend mengambil jumlah peminjaman dan pengembalian untuk setiap bulan pada year
+func (s *DashboardService) MonthlyTrend(ctx context.Context, year int) ([]MonthlyTrend, error) {
+	db := s.db.WithContext(ctx)
+
+	var results []MonthlyTrend
+	// Inisialisasi data untuk 12 bulan
+	for i := 1; i <= 12; i++ {
+		results = append(results, MonthlyTrend{
+			MonthName: time.Month(i).String()[:3], // Jan, Feb, Mar, dst.
+			MonthNum:  i,
+		})
+	}
+
+	// Kueri untuk peminjaman
+	var borrowedData []monthCount
+	if err := db.Model(&models.Lending_records{}).
+		Select("EXTRACT(MONTH FROM borrow_date) as month, COUNT(*) as count").
+		Where("EXTRACT(YEAR FROM borrow_date) = ?", year).
+		Group("month").
+		Order("month asc").
+		Scan(&borrowedData).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil data peminjaman bulanan: %w", err)
+	}
+	for _, data := range borrowedData {
+		if data.Month >= 1 && data.Month <= 12 {
+			results[data.Month-1].Borrowings = data.Count
+		}
+	}
+
+	// Kueri untuk pengembalian (hanya jika return_date tidak NULL)
+	var returnedData []monthCount
+	if err := db.Model(&models.Lending_records{}).
+		Select("EXTRACT(MONTH FROM return_date) as month, COUNT(*) as count").
+		Where("EXTRACT(YEAR FROM return_date) = ? AND return_date IS NOT NULL", year).
+		Group("month").
+		Order("month asc").
+		Scan(&returnedData).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil data pengembalian bulanan: %w", err)
+	}
+	for _, data := range returnedData {
+		if data.Month >= 1 && data.Month <= 12 {
+			results[data.Month-1].Returns = data.Count
+		}
+	}
+
+	return results, nil
+}
+
+// Activity adalah satu aktivitas peminjaman (borrow) atau pengembalian (return)
+type Activity struct {
+	Type      string    `json:"type"`
+	UserName  string    `json:"user_name"`
+	BookTitle string    `json:"book_title"`
+	Date      time.Time `json:"date"`
+}
+
+// LatestActivity mengambil paling banyak limit aktivitas peminjaman dan pengembalian terbaru
+func (s *DashboardService) LatestActivity(ctx context.Context, limit int) ([]Activity, error) {
+	var records []models.Lending_records
+	if err := s.db.WithContext(ctx).
+		Preload("User").
+		Preload("Book").
+		Order("borrow_date DESC").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil aktivitas terbaru: %w", err)
+	}
+
+	var activities []Activity
+	for _, record := range records {
+		activities = append(activities, Activity{
+			Type:      "borrow",
+			UserName:  record.User.Name,
+			BookTitle: record.Book.Title,
+			Date:      record.Borrow_date,
+		})
+		if record.ReturnDate != nil && !record.ReturnDate.IsZero() {
+			activities = append(activities, Activity{
+				Type:      "return",
+				UserName:  record.User.Name,
+				BookTitle: record.Book.Title,
+				Date:      *record.ReturnDate,
+			})
+		}
+	}
+
+	// Aktivitas peminjaman dan pengembalian diurutkan dari yang terbaru
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Date.After(activities[j].Date)
+	})
+	// Pastikan hasil akhir tidak melebihi limit setelah aktivitas pengembalian ditambahkan
+	if len(activities) > limit {
+		activities = activities[:limit]
+	}
+	return activities, nil
+}
+
+// TopBorrowedBook adalah buku beserta jumlah peminjamannya
+type TopBorrowedBook struct {
+	BookTitle   string `json:"title"`
+	BorrowCount int64  `json:"borrow_count"`
+}
+
+// TopBorrowedBooks mengambil paling banyak limit buku yang paling banyak dipinjam pada bulan
+// month tahun year
+func (s *DashboardService) TopBorrowedBooks(ctx context.Context, year int, month int, limit int) ([]TopBorrowedBook, error) {
+	// Hitung start dan end date untuk periode yang diminta
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Now().Location())
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	var topBooks []TopBorrowedBook
+	// Join Records dengan Books, GROUP BY BookID dan hitung jumlah peminjaman
+	if err := s.db.WithContext(ctx).Model(&models.Lending_records{}).
+		Select("books.title, COUNT(lending_records.id) as borrow_count").
+		Joins("JOIN books ON lending_records.book_id = books.id").
+		Where("lending_records.borrow_date BETWEEN ? AND ?", startDate, endDate).
+		Group("books.id, books.title").
+		Order("borrow_count DESC").
+		Limit(limit).
+		Scan(&topBooks).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil buku paling banyak dipinjam: %w", err)
+	}
+	return topBooks, nil
+}
+
+// CategoryDistribution adalah jumlah buku pada satu kategori. BookCount menghitung
+// buku di kategori itu beserta seluruh subkategorinya (setiap buku sekali), DirectCount
+// hanya buku yang dihubungkan langsung ke kategori tersebut.
+type CategoryDistribution struct {
+	CategoryID  *uuid.UUID             `json:"category_id"`
+	Category    string                 `json:"category"`
+	BookCount   int64                  `json:"book_count"`
+	DirectCount int64                  `json:"direct_count"`
+	Children    []CategoryDistribution `json:"children,omitempty"`
+}
+
+// categoryBookCount adalah hasil hitung buku per kategori
+type categoryBookCount struct {
+	CategoryID  uuid.UUID
+	BookCount   int64
+	DirectCount int64
+}
+
+// CategoryDistribution mengambil distribusi buku per kategori sebagai pohon kategori.
+// Jumlah buku subkategori ikut dihitung pada induknya, dan kategori tanpa buku tetap ditampilkan.
+// Buku tanpa kategori dikelompokkan di akhir dengan category_id null.
+func (s *DashboardService) CategoryDistribution(ctx context.Context) ([]CategoryDistribution, error) {
+	db := s.db.WithContext(ctx)
+
+	var categories []models.Category
+	if err := db.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil kategori buku: %w", err)
+	}
+
+	// Setiap kategori dipasangkan dengan dirinya dan semua leluhurnya, lalu buku dihitung
+	// sekali per leluhur supaya buku yang ada di dua subkategori tidak terhitung dua kali
+	var counts []categoryBookCount
+	if err := db.Raw(categoryAncestryCTE + `
+		SELECT ancestry.ancestor_id AS category_id,
+			COUNT(DISTINCT book_categories.book_id) AS book_count,
+			COUNT(DISTINCT book_categories.book_id) FILTER (WHERE ancestry.category_id = ancestry.ancestor_id) AS direct_count
+		FROM ancestry
+		JOIN book_categories ON book_categories.category_id = ancestry.category_id
+		JOIN books ON books.id = book_categories.book_id AND books.deleted_at IS NULL
+		GROUP BY ancestry.ancestor_id`).Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil distribusi kategori buku: %w", err)
+	}
+
+	var uncategorized int64
+	if err := db.Model(&models.Book{}).
+		Where("NOT EXISTS (SELECT 1 FROM book_categories WHERE book_categories.book_id = books.id)").
+		Count(&uncategorized).Error; err != nil {
+		return nil, fmt.Errorf("Gagal mengambil distribusi kategori buku: %w", err)
+	}
+
+	byCategory := make(map[uuid.UUID]categoryBookCount, len(counts))
+	for _, count := range counts {
+		byCategory[count.CategoryID] = count
+	}
+	var toDistribution func(nodes []models.Category) []CategoryDistribution
+	toDistribution = func(nodes []models.Category) []CategoryDistribution {
+		distribution := make([]CategoryDistribution, 0, len(nodes))
+		for _, node := range nodes {
+			id := node.ID
+			count := byCategory[id]
+			distribution = append(distribution, CategoryDistribution{
+				CategoryID:  &id,
+				Category:    node.Name,
+				BookCount:   count.BookCount,
+				DirectCount: count.DirectCount,
+				Children:    toDistribution(node.Children),
+			})
+		}
+		// Urutkan dari jumlah buku terbanyak, lalu nama kategori
+		sort.SliceStable(distribution, func(i, j int) bool {
+			return distribution[i].BookCount > distribution[j].BookCount
+		})
+		return distribution
+	}
+
+	distribution := toDistribution(buildCategoryTree(categories))
+	if uncategorized > 0 {
+		distribution = append(distribution, CategoryDistribution{BookCount: uncategorized, DirectCount: uncategorized})
+	}
+	return distribution, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserExportRow adalah satu baris export pengguna, tanpa password
+type UserExportRow struct {
+	ID        uuid.UUID
+	Name      string
+	Email     string
+	Role      string
+	Tier      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RecordExportRow adalah satu baris export peminjaman beserta judul buku, barcode eksemplar
+// dan peminjamnya, supaya hasil export bisa dibaca tanpa mencocokkan ID
+type RecordExportRow struct {
+	models.Lending_records
+	BookTitle   string
+	BookIsbn    string
+	CopyBarcode string
+	UserName    string
+	UserEmail   string
+}
+
+// ExportService membaca data export baris demi baris lewat cursor database, sehingga export
+// besar tidak perlu dimuat seluruhnya ke memori seperti List pada repository
+type ExportService struct {
+	db *gorm.DB
+}
+
+// NewExportService membuat ExportService
+func NewExportService(db *gorm.DB) *ExportService {
+	return &ExportService{db: db}
+}
+
+// ExportRows adalah cursor hasil query export. Pemanggil wajib memanggil Close.
+type ExportRows struct {
+	rows *sql.Rows
+	db   *gorm.DB
+}
+
+// Next memajukan cursor ke baris berikutnya
+func (r *ExportRows) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan membaca baris saat ini ke dest (pointer ke struct). Hook AfterFind tidak dijalankan.
+func (r *ExportRows) Scan(dest interface{}) error {
+	return r.db.ScanRows(r.rows, dest)
+}
+
+// Err mengembalikan kesalahan yang terjadi selama iterasi
+func (r *ExportRows) Err() error {
+	return r.rows.Err()
+}
+
+// Close menutup cursor
+func (r *ExportRows) Close() error {
+	return r.rows.Close()
+}
+
+// Books membuka cursor buku sesuai filter dan urutan listQuery; baris dibaca sebagai models.Book
+func (s *ExportService) Books(ctx context.Context, listQuery *helpers.ListQuery) (*ExportRows, error) {
+	db := s.db.WithContext(ctx)
+	return s.open(listQuery.Order(listQuery.Filter(db.Model(&models.Book{}))))
+}
+
+// Users membuka cursor pengguna beserta kode tier-nya; baris dibaca sebagai UserExportRow
+func (s *ExportService) Users(ctx context.Context, listQuery *helpers.ListQuery) (*ExportRows, error) {
+	db := s.db.WithContext(ctx)
+	query := listQuery.Order(listQuery.Filter(db.Model(&models.User{}))).
+		Select(`users.id, users.name, users.email, users.role, users.created_at, users.updated_at,
+			COALESCE((SELECT code FROM membership_tiers WHERE membership_tiers.id = users.tier_id), '') AS tier`)
+	return s.open(query)
+}
+
+// Records membuka cursor peminjaman yang dibatasi filter beserta judul buku, barcode dan
+// peminjamnya; baris dibaca sebagai RecordExportRow
+func (s *ExportService) Records(ctx context.Context, filter repositories.RecordFilter, listQuery *helpers.ListQuery) (*ExportRows, error) {
+	query := listQuery.Filter(s.db.WithContext(ctx).Model(&models.Lending_records{}))
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = repositories.FilterRecordsByStatus(query, filter.Status, filter.Now)
+	}
+	query = listQuery.Order(query).Select(`lending_records.*,
+		COALESCE((SELECT title FROM books WHERE books.id = lending_records.book_id), '') AS book_title,
+		COALESCE((SELECT isbn FROM books WHERE books.id = lending_records.book_id), '') AS book_isbn,
+		COALESCE((SELECT barcode FROM book_copies WHERE book_copies.id = lending_records.copy_id), '') AS copy_barcode,
+		COALESCE((SELECT name FROM users WHERE users.id = lending_records.user_id), '') AS user_name,
+		COALESCE((SELECT email FROM users WHERE users.id = lending_records.user_id), '') AS user_email`)
+	return s.open(query)
+}
+
+// open menjalankan query dan membungkus cursor-nya
+func (s *ExportService) open(query *gorm.DB) (*ExportRows, error) {
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	return &ExportRows{rows: rows, db: s.db}, nil
+}
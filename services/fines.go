@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FineSummary berisi saldo denda anggota beserta riwayat transaksinya
+type FineSummary struct {
+	UserID       uuid.UUID                `json:"user_id"`
+	Balance      int64                    `json:"balance"`
+	Transactions []models.FineTransaction `json:"transactions"`
+}
+
+// FineEntry adalah transaksi denda yang dicatat staff. Pada pembayaran dan pembebasan Amount
+// adalah nominal yang dilunasi; pada penyesuaian Amount positif menambah tagihan dan negatif
+// mengurangi tagihan.
+type FineEntry struct {
+	UserID    uuid.UUID
+	Amount    int64
+	Note      string
+	RecordID  *uuid.UUID
+	CreatedBy *uuid.UUID
+}
+
+// FineSummary mengambil saldo dan riwayat denda anggota, transaksi terbaru lebih dulu
+func (s *CirculationService) FineSummary(ctx context.Context, userID uuid.UUID) (*FineSummary, error) {
+	db := s.db.WithContext(ctx)
+	if err := db.First(&models.User{}, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newError(KindNotFound, helpers.ErrCodeUserNotFound, "User not found")
+		}
+		return nil, err
+	}
+
+	balance, err := userFineBalance(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	var transactions []models.FineTransaction
+	if err := db.Where("user_id = ?", userID).Order("created_at DESC").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	return &FineSummary{UserID: userID, Balance: balance, Transactions: transactions}, nil
+}
+
+// SettleFine mencatat pembayaran (models.FineTypePayment) atau pembebasan (models.FineTypeWaiver)
+// denda. Nominal tidak boleh melebihi saldo supaya anggota tidak memiliki saldo kredit.
+func (s *CirculationService) SettleFine(ctx context.Context, fineType string, entry FineEntry) error {
+	if fineType != models.FineTypePayment && fineType != models.FineTypeWaiver {
+		return fmt.Errorf("fine type %q does not settle a balance", fineType)
+	}
+	if entry.Amount <= 0 {
+		return newError(KindInvalid, "", "Amount must be greater than zero")
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci baris pengguna agar dua pembayaran bersamaan tidak melewati saldo
+		if err := lockUser(tx, entry.UserID); err != nil {
+			return err
+		}
+		balance, err := userFineBalance(tx, entry.UserID)
+		if err != nil {
+			return err
+		}
+		if entry.Amount > balance {
+			return newError(KindConflict, helpers.ErrCodeFineExceedsBalance,
+				fmt.Sprintf("Amount exceeds the outstanding balance of %d", balance))
+		}
+		return tx.Create(entry.transaction(fineType, -entry.Amount)).Error
+	})
+}
+
+// AdjustFine mencatat koreksi manual saldo denda. Catatan wajib diisi sebagai alasan koreksi.
+// Seperti SettleFine, koreksi negatif tidak boleh membuat saldo di bawah nol.
+func (s *CirculationService) AdjustFine(ctx context.Context, entry FineEntry) error {
+	if entry.Amount == 0 {
+		return newError(KindInvalid, "", "Amount must not be zero")
+	}
+	if entry.Note == "" {
+		return newError(KindInvalid, "", "Note is required for adjustments")
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci baris pengguna agar koreksi dan pembayaran bersamaan tidak melewati saldo
+		if err := lockUser(tx, entry.UserID); err != nil {
+			return err
+		}
+		if entry.Amount < 0 {
+			balance, err := userFineBalance(tx, entry.UserID)
+			if err != nil {
+				return err
+			}
+			if -entry.Amount > balance {
+				return newError(KindConflict, helpers.ErrCodeFineExceedsBalance,
+					fmt.Sprintf("Adjustment exceeds the outstanding balance of %d", balance))
+			}
+		}
+		return tx.Create(entry.transaction(models.FineTypeAdjustment, entry.Amount)).Error
+	})
+}
+
+// transaction membuat FineTransaction dari entry dengan jenis dan nominal bertanda
+func (e FineEntry) transaction(fineType string, amount int64) *models.FineTransaction {
+	return &models.FineTransaction{
+		UserID:    e.UserID,
+		RecordID:  e.RecordID,
+		Type:      fineType,
+		Amount:    amount,
+		Note:      e.Note,
+		CreatedBy: e.CreatedBy,
+	}
+}
+
+// chargeOverdueFine menghitung dan mencatat denda keterlambatan sebuah peminjaman
+// dengan tarif tier peminjam (atau tarif khusus kategori buku)
+func (s *CirculationService) chargeOverdueFine(tx *gorm.DB, record *models.Lending_records, book *models.Book, returnedAt time.Time) (int64, error) {
+	if !returnedAt.After(record.DueDate) {
+		return 0, nil
+	}
+
+	policy, err := loanPolicyFor(tx, record.User_id, s.cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	fine := helpers.CalculateOverdueFine(record.DueDate, returnedAt, policy.fineRateFor(book.Category), s.cfg.FineGraceDays, s.cfg.FineMaxAmount)
+	if fine == 0 {
+		return 0, nil
+	}
+	err = tx.Create(&models.FineTransaction{
+		UserID:   record.User_id,
+		RecordID: &record.ID,
+		Type:     models.FineTypeOverdue,
+		Amount:   fine,
+		Note:     fmt.Sprintf("Overdue fine for %q", book.Title),
+	}).Error
+	return fine, err
+}
+
+// ensureFinesBelowLimit menolak peminjaman jika saldo denda melebihi batas yang diizinkan
+func (s *CirculationService) ensureFinesBelowLimit(tx *gorm.DB, userID uuid.UUID) error {
+	balance, err := userFineBalance(tx, userID)
+	if err != nil {
+		return err
+	}
+	if balance > s.cfg.MaxOutstandingFine {
+		return newError(KindConflict, helpers.ErrCodeFinesOutstanding,
+			fmt.Sprintf("Outstanding fines of %d exceed the borrowing limit of %d, please settle them first", balance, s.cfg.MaxOutstandingFine))
+	}
+	return nil
+}
+
+// userFineBalance menghitung saldo denda anggota
+func userFineBalance(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var balance int64
+	err := db.Model(&models.FineTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error
+	return balance, err
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"library/config"       // Sesuaikan dengan nama proyekmu
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueuedHold adalah hold beserta posisinya di antrean (0 jika sudah tidak menunggu)
+type QueuedHold struct {
+	models.Hold
+	QueuePosition int64 `json:"queue_position"`
+}
+
+// PlaceHold menempatkan anggota ke antrean reservasi sebuah buku.
+// Hold hanya bisa dibuat jika semua eksemplar sedang dipinjam atau disisihkan.
+func (s *CirculationService) PlaceHold(ctx context.Context, bookID uuid.UUID, userID uuid.UUID) (*QueuedHold, error) {
+	hold := &models.Hold{BookID: bookID, UserID: userID, Status: models.HoldStatusWaiting}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		book := new(models.Book)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(book, "id = ?", bookID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindNotFound, helpers.ErrCodeBookNotFound, "Book not found")
+			}
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.Hold{}).
+			Where("book_id = ? AND user_id = ? AND status IN ?", bookID, userID, []string{models.HoldStatusWaiting, models.HoldStatusReady}).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return newError(KindConflict, helpers.ErrCodeHoldExists, "You already have an active hold on this book")
+		}
+
+		// Peminjaman yang hilang tidak dihitung: anggota boleh mengantre eksemplar pengganti
+		var borrowed int64
+		if err := tx.Model(&models.Lending_records{}).
+			Where("book_id = ? AND user_id = ? AND return_date IS NULL AND status <> ?", bookID, userID, models.RecordStatusLost).
+			Count(&borrowed).Error; err != nil {
+			return err
+		}
+		if borrowed > 0 {
+			return newError(KindConflict, helpers.ErrCodeHoldExists, "You are currently borrowing this book")
+		}
+
+		available, err := repositories.CountAvailableCopies(tx, book.ID)
+		if err != nil {
+			return err
+		}
+		if available > 0 {
+			return newError(KindConflict, helpers.ErrCodeHoldNotNeeded, "This book has copies available, borrow it directly")
+		}
+
+		return tx.Create(hold).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := holdQueuePosition(s.db.WithContext(ctx), hold)
+	if err != nil {
+		return nil, err
+	}
+	return &QueuedHold{Hold: *hold, QueuePosition: position}, nil
+}
+
+// ActiveHolds mengambil hold aktif milik anggota beserta posisinya di antrean
+func (s *CirculationService) ActiveHolds(ctx context.Context, userID uuid.UUID) ([]QueuedHold, error) {
+	db := s.db.WithContext(ctx)
+
+	var holds []models.Hold
+	if err := db.Preload("Book").
+		Where("user_id = ? AND status IN ?", userID, []string{models.HoldStatusWaiting, models.HoldStatusReady}).
+		Order("created_at ASC").
+		Find(&holds).Error; err != nil {
+		return nil, err
+	}
+
+	queued := make([]QueuedHold, 0, len(holds))
+	for _, hold := range holds {
+		position, err := holdQueuePosition(db, &hold)
+		if err != nil {
+			return nil, err
+		}
+		queued = append(queued, QueuedHold{Hold: hold, QueuePosition: position})
+	}
+	return queued, nil
+}
+
+// BookHolds mengambil antrean hold aktif sebuah buku, diurutkan dari yang paling awal
+func (s *CirculationService) BookHolds(ctx context.Context, bookID uuid.UUID) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := s.db.WithContext(ctx).
+		Where("book_id = ? AND status IN ?", bookID, []string{models.HoldStatusWaiting, models.HoldStatusReady}).
+		Order("created_at ASC").
+		Find(&holds).Error
+	return holds, err
+}
+
+// CancelHold membatalkan hold. Selain staff, actor hanya dapat membatalkan hold miliknya sendiri.
+// Jika hold yang dibatalkan sudah ready, eksemplarnya diteruskan ke antrean berikutnya.
+func (s *CirculationService) CancelHold(ctx context.Context, holdID uuid.UUID, actor Actor) (*models.Hold, error) {
+	hold := new(models.Hold)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(hold, "id = ?", holdID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newError(KindNotFound, helpers.ErrCodeHoldNotFound, "Hold not found")
+			}
+			return err
+		}
+		if !actor.IsStaff() && hold.UserID != actor.UserID {
+			return newError(KindNotFound, helpers.ErrCodeHoldNotFound, "Hold not found")
+		}
+		if !hold.IsActive() {
+			return newError(KindConflict, helpers.ErrCodeHoldNotActive, "This hold is no longer active")
+		}
+
+		wasReady := hold.Status == models.HoldStatusReady
+		hold.Status = models.HoldStatusCancelled
+		if err := tx.Model(hold).Update("status", models.HoldStatusCancelled).Error; err != nil {
+			return err
+		}
+		if wasReady {
+			if err := releaseHoldCopy(tx, hold); err != nil {
+				return err
+			}
+			return PromoteHolds(tx, hold.BookID, s.cfg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// ExpireReadyHolds menandai hold ready yang melewati batas pengambilan sebagai expired,
+// lalu meneruskan eksemplar yang disisihkan ke antrean berikutnya. Dijalankan berkala oleh background job.
+func (s *CirculationService) ExpireReadyHolds(ctx context.Context) error {
+	now := time.Now()
+	var bookIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.Hold{}).
+		Where("status = ? AND expires_at < ?", models.HoldStatusReady, now).
+		Distinct().Pluck("book_id", &bookIDs).Error; err != nil {
+		return err
+	}
+
+	for _, bookID := range bookIDs {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return expireLapsedHolds(tx, bookID, s.cfg, now)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(bookIDs) > 0 {
+		log.Printf("Expired ready holds of %d books", len(bookIDs))
+	}
+	return nil
+}
+
+// expireLapsedHolds menandai hold ready sebuah buku yang melewati batas pengambilan sebagai expired,
+// melepas eksemplar yang disisihkan dan meneruskannya ke antrean berikutnya. Hold dikunci lebih
+// dulu, sehingga hold yang sudah dipenuhi atau dibatalkan transaksi lain tidak ikut diubah.
+func expireLapsedHolds(tx *gorm.DB, bookID uuid.UUID, cfg *config.Config, now time.Time) error {
+	var lapsed []models.Hold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ? AND expires_at < ?", bookID, models.HoldStatusReady, now).
+		Find(&lapsed).Error; err != nil {
+		return err
+	}
+	if len(lapsed) == 0 {
+		return nil
+	}
+
+	for i := range lapsed {
+		if err := tx.Model(&lapsed[i]).Update("status", models.HoldStatusExpired).Error; err != nil {
+			return err
+		}
+		if err := releaseHoldCopy(tx, &lapsed[i]); err != nil {
+			return err
+		}
+	}
+	return PromoteHolds(tx, bookID, cfg)
+}
+
+// PromoteHolds menyisihkan eksemplar available untuk hold waiting terdepan (FIFO),
+// mengubahnya menjadi ready sampai eksemplar habis atau antrean kosong.
+// Harus dipanggil di dalam transaksi setiap kali eksemplar kembali tersedia.
+func PromoteHolds(tx *gorm.DB, bookID uuid.UUID, cfg *config.Config) error {
+	now := time.Now()
+	expiresAt := now.AddDate(0, 0, cfg.HoldPickupDays)
+
+	for {
+		hold := new(models.Hold)
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("book_id = ? AND status = ?", bookID, models.HoldStatusWaiting).
+			Order("created_at ASC").
+			First(hold).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		bookCopy, err := lockAvailableCopy(tx, bookID)
+		if err != nil || bookCopy == nil {
+			return err
+		}
+
+		if err := setCopyStatus(tx, bookCopy.ID, models.CopyStatusOnHold); err != nil {
+			return err
+		}
+		if err := tx.Model(hold).Updates(map[string]interface{}{
+			"status":     models.HoldStatusReady,
+			"copy_id":    bookCopy.ID,
+			"ready_at":   now,
+			"expires_at": expiresAt,
+		}).Error; err != nil {
+			return err
+		}
+	}
+}
+
+// releaseHoldCopy mengembalikan eksemplar yang disisihkan untuk hold ke status available
+func releaseHoldCopy(tx *gorm.DB, hold *models.Hold) error {
+	if hold.CopyID == nil {
+		return nil
+	}
+	return tx.Model(&models.BookCopy{}).
+		Where("id = ? AND status = ?", *hold.CopyID, models.CopyStatusOnHold).
+		Update("status", models.CopyStatusAvailable).Error
+}
+
+// holdQueuePosition menghitung posisi hold waiting di antrean (dimulai dari 1)
+func holdQueuePosition(db *gorm.DB, hold *models.Hold) (int64, error) {
+	if hold.Status != models.HoldStatusWaiting {
+		return 0, nil
+	}
+
+	var ahead int64
+	err := db.Model(&models.Hold{}).
+		Where("book_id = ? AND status = ? AND created_at < ?", hold.BookID, models.HoldStatusWaiting, hold.CreatedAt).
+		Count(&ahead).Error
+	return ahead + 1, err
+}
+
+// hasWaitingHoldsFromOthers memeriksa apakah ada anggota lain yang sedang mengantre buku ini
+func hasWaitingHoldsFromOthers(db *gorm.DB, bookID uuid.UUID, userID uuid.UUID) (bool, error) {
+	var waiting int64
+	err := db.Model(&models.Hold{}).
+		Where("book_id = ? AND user_id <> ? AND status IN ?", bookID, userID, []string{models.HoldStatusWaiting, models.HoldStatusReady}).
+		Count(&waiting).Error
+	return waiting > 0, err
+}
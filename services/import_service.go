@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"library/config"       // Sesuaikan dengan nama proyekmu
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImportService mengimpor katalog buku dari file. Eksemplar baru dari impor bisa
+// meneruskan antrean hold, sehingga service ini juga membutuhkan konfigurasi sirkulasi.
+type ImportService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewImportService membuat ImportService
+func NewImportService(db *gorm.DB, cfg *config.Config) *ImportService {
+	return &ImportService{db: db, cfg: cfg}
+}
+
+// DefaultImportChunkSize adalah jumlah baris yang disimpan dalam satu transaksi impor
+const DefaultImportChunkSize = 500
+
+// Kolom file impor buku. Nama kolom di header tidak membedakan huruf besar/kecil.
+var (
+	requiredImportColumns = []string{"title", "author", "isbn"}
+	optionalImportColumns = []string{"quantity", "category", "replacement_cost"}
+)
+
+// BookImportRow adalah satu baris data buku dari file impor
+type BookImportRow struct {
+	Row             int // Nomor baris di file (header adalah baris 1), atau urutan record untuk MARC
+	Title           string
+	Author          string
+	Isbn            string
+	Quantity        int
+	Category        string
+	ReplacementCost int64
+	// hasQuantity dan hasReplacementCost membedakan sel kosong dari nilai 0
+	hasQuantity        bool
+	hasReplacementCost bool
+}
+
+// BookImportOptions mengatur jalannya impor
+type BookImportOptions struct {
+	DryRun    bool // Hanya validasi, tidak ada data yang disimpan
+	ChunkSize int  // Jumlah baris per transaksi
+	StartRow  int  // Lanjutkan impor mulai dari nomor baris ini (untuk impor yang terhenti)
+}
+
+// BookImportRowResult adalah hasil impor satu baris yang bermasalah atau perlu perhatian
+type BookImportRowResult struct {
+	Row      int      `json:"row"`
+	Isbn     string   `json:"isbn"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// BookImportReport adalah ringkasan hasil impor beserta laporan per baris
+type BookImportReport struct {
+	DryRun        bool                  `json:"dry_run"`
+	TotalRows     int                   `json:"total_rows"`
+	Skipped       int                   `json:"skipped"` // Baris sebelum start_row
+	Valid         int                   `json:"valid"`
+	Invalid       int                   `json:"invalid"`
+	Created       int                   `json:"created"`
+	Updated       int                   `json:"updated"`
+	Rows          []BookImportRowResult `json:"rows"`
+	Completed     bool                  `json:"completed"`
+	ResumeFromRow int                   `json:"resume_from_row,omitempty"` // Diisi jika impor terhenti di tengah
+	Error         string                `json:"error,omitempty"`
+}
+
+// ParseBookImportFile membaca baris buku dari file CSV, XLSX, MARC21 (.mrc) atau MARCXML (.xml)
+// berdasarkan ekstensinya. File yang tidak bisa dibaca dilaporkan sebagai KindInvalid.
+func ParseBookImportFile(r io.Reader, filename string) ([]BookImportRow, error) {
+	rows, err := parseBookImportRows(r, filename)
+	if err != nil {
+		return nil, newError(KindInvalid, helpers.ErrCodeImportInvalidFile, err.Error())
+	}
+	return rows, nil
+}
+
+// parseBookImportRows membaca baris buku sesuai format file
+func parseBookImportRows(r io.Reader, filename string) ([]BookImportRow, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".mrc", ".marc":
+		marcRecords, err := helpers.ReadMARC21(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MARC21 file: %w", err)
+		}
+		return bookImportRowsFromMARC(marcRecords), nil
+	case ".xml":
+		marcRecords, err := helpers.ReadMARCXML(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MARCXML file: %w", err)
+		}
+		return bookImportRowsFromMARC(marcRecords), nil
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("invalid CSV file: %w", err)
+		}
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX file: %w", err)
+		}
+		defer workbook.Close()
+		// Hanya sheet pertama yang dibaca
+		if records, err = workbook.GetRows(workbook.GetSheetName(0)); err != nil {
+			return nil, fmt.Errorf("invalid XLSX file: %w", err)
+		}
+	default:
+		return nil, errors.New("unsupported file type, use .csv, .xlsx, .mrc or .xml")
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing required column %q, expected columns: %s", name,
+				strings.Join(append(requiredImportColumns, optionalImportColumns...), ", "))
+		}
+	}
+
+	rows := make([]BookImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		cell := func(name string) string {
+			index, ok := columns[name]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue // Lewati baris kosong
+		}
+
+		row := BookImportRow{
+			Row:      i + 2,
+			Title:    cell("title"),
+			Author:   cell("author"),
+			Isbn:     cell("isbn"),
+			Category: cell("category"),
+		}
+		if value := cell("quantity"); value != "" {
+			row.hasQuantity = true
+			quantity, err := strconv.Atoi(value)
+			if err != nil {
+				quantity = -1 // Ditolak saat validasi
+			}
+			row.Quantity = quantity
+		}
+		if value := cell("replacement_cost"); value != "" {
+			row.hasReplacementCost = true
+			cost, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				cost = -1 // Ditolak saat validasi
+			}
+			row.ReplacementCost = cost
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ImportBooks memvalidasi semua baris lalu menyimpan buku (upsert berdasarkan ISBN) per chunk.
+// Jika ada baris tidak valid tidak ada yang disimpan. Setiap chunk berjalan dalam transaksinya
+// sendiri; jika sebuah chunk gagal, laporan berisi resume_from_row untuk melanjutkan impor.
+func (s *ImportService) ImportBooks(ctx context.Context, rows []BookImportRow, opts BookImportOptions) *BookImportReport {
+	db := s.db.WithContext(ctx)
+	if opts.ChunkSize < 1 {
+		opts.ChunkSize = DefaultImportChunkSize
+	}
+	report := &BookImportReport{DryRun: opts.DryRun, TotalRows: len(rows), Rows: []BookImportRowResult{}}
+
+	pending := make([]BookImportRow, 0, len(rows))
+	seen := make(map[string]int)
+	for _, row := range rows {
+		if row.Row < opts.StartRow {
+			report.Skipped++
+			continue
+		}
+		result := validateImportRow(&row)
+		if previous, ok := seen[row.Isbn]; ok && len(result.Errors) == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("duplicate ISBN, already used on row %d", previous))
+		}
+		if len(result.Errors) > 0 {
+			report.Invalid++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+		seen[row.Isbn] = row.Row
+		report.Valid++
+		pending = append(pending, row)
+	}
+
+	if opts.DryRun {
+		// Dry run tetap memeriksa ISBN yang sudah ada supaya laporan create/update akurat
+		for _, row := range pending {
+			book := new(models.Book)
+			err := db.Unscoped().Where("isbn = ?", row.Isbn).First(book).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				report.Created++
+			case err != nil:
+				report.Error = err.Error()
+				return report
+			case book.DeletedAt.Valid:
+				report.Rows = append(report.Rows, BookImportRowResult{Row: row.Row, Isbn: row.Isbn,
+					Errors: []string{"ISBN belongs to a deleted book"}})
+				report.Invalid++
+				report.Valid--
+			default:
+				report.Updated++
+			}
+		}
+		return report
+	}
+	if report.Invalid > 0 {
+		return report
+	}
+
+	for start := 0; start < len(pending); start += opts.ChunkSize {
+		end := start + opts.ChunkSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		chunk := pending[start:end]
+
+		var created, updated int
+		var results []BookImportRowResult
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range chunk {
+				wasCreated, result, err := upsertImportedBook(tx, row, s.cfg)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", row.Row, err)
+				}
+				if wasCreated {
+					created++
+				} else {
+					updated++
+				}
+				if len(result.Warnings) > 0 {
+					results = append(results, result)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			report.Error = err.Error()
+			report.ResumeFromRow = chunk[0].Row
+			return report
+		}
+		report.Created += created
+		report.Updated += updated
+		report.Rows = append(report.Rows, results...)
+	}
+
+	report.Completed = true
+	return report
+}
+
+// validateImportRow memeriksa satu baris dan menormalisasi ISBN-nya
+func validateImportRow(row *BookImportRow) BookImportRowResult {
+	result := BookImportRowResult{Row: row.Row, Isbn: row.Isbn}
+	if row.Title == "" {
+		result.Errors = append(result.Errors, "title is required")
+	}
+	if row.Author == "" {
+		result.Errors = append(result.Errors, "author is required")
+	}
+	if isbn, err := helpers.NormalizeISBN(row.Isbn); err != nil {
+		result.Errors = append(result.Errors, "isbn must be a valid ISBN-10 or ISBN-13")
+	} else {
+		row.Isbn = isbn
+		result.Isbn = isbn
+	}
+	if row.Quantity < 0 {
+		result.Errors = append(result.Errors, "quantity must be a whole number of at least 0")
+	}
+	if row.ReplacementCost < 0 {
+		result.Errors = append(result.Errors, "replacement_cost must be a whole number of at least 0")
+	}
+	return result
+}
+
+// upsertImportedBook membuat buku baru atau memperbarui buku dengan ISBN yang sama.
+// Penulis dan kategori dicocokkan dengan nama yang sudah ada tanpa membedakan huruf besar/kecil.
+// Quantity hanya bisa menambah eksemplar; eksemplar yang berkurang harus ditarik lewat /copies.
+func upsertImportedBook(tx *gorm.DB, row BookImportRow, cfg *config.Config) (bool, BookImportRowResult, error) {
+	result := BookImportRowResult{Row: row.Row, Isbn: row.Isbn}
+
+	authors, _, err := repositories.BookAuthorsFor(tx, nil, row.Author)
+	if err != nil {
+		return false, result, err
+	}
+	categories, categoriesChanged, err := repositories.BookCategoriesFor(tx, nil, row.Category)
+	if err != nil {
+		return false, result, err
+	}
+
+	book := new(models.Book)
+	err = tx.Unscoped().Where("isbn = ?", row.Isbn).First(book).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		book = &models.Book{
+			Title:           row.Title,
+			Isbn:            row.Isbn,
+			ReplacementCost: row.ReplacementCost,
+		}
+		repositories.ApplyBookAuthors(book, authors)
+		repositories.ApplyBookCategories(book, categories)
+		if err := tx.Create(book).Error; err != nil {
+			return false, result, err
+		}
+		return true, result, repositories.CreateGeneratedCopies(tx, book, row.Quantity)
+	}
+	if err != nil {
+		return false, result, err
+	}
+	if book.DeletedAt.Valid {
+		return false, result, errors.New("ISBN belongs to a deleted book")
+	}
+
+	repositories.ApplyBookAuthors(book, authors)
+	updates := map[string]interface{}{"title": row.Title, "author": book.Author}
+	if categoriesChanged {
+		repositories.ApplyBookCategories(book, categories)
+		updates["category"] = book.Category
+	}
+	if row.hasReplacementCost {
+		updates["replacement_cost"] = row.ReplacementCost
+	}
+	if err := tx.Model(book).Omit(clause.Associations).Updates(updates).Error; err != nil {
+		return false, result, err
+	}
+	if err := repositories.ReplaceBookAuthors(tx, book, authors); err != nil {
+		return false, result, err
+	}
+	if categoriesChanged {
+		if err := repositories.ReplaceBookCategories(tx, book, categories); err != nil {
+			return false, result, err
+		}
+	}
+
+	if row.hasQuantity {
+		switch {
+		case row.Quantity > book.Quantity:
+			if err := repositories.CreateGeneratedCopies(tx, book, row.Quantity-book.Quantity); err != nil {
+				return false, result, err
+			}
+			if err := PromoteHolds(tx, book.ID, cfg); err != nil {
+				return false, result, err
+			}
+		case row.Quantity < book.Quantity:
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"quantity %d is lower than the %d copies on record, copies were not removed", row.Quantity, book.Quantity))
+		}
+	}
+	return false, result, nil
+}
+
+// bookImportRowsFromMARC memetakan record MARC21 ke baris impor buku:
+// 020$a menjadi ISBN, 245$a dan $b menjadi judul, 100/110/111$a (atau 700$a) menjadi penulis
+// dan 650$a (atau 655$a) menjadi kategori. Nomor baris adalah urutan record di file.
+func bookImportRowsFromMARC(records []helpers.MARCRecord) []BookImportRow {
+	rows := make([]BookImportRow, 0, len(records))
+	for i := range records {
+		record := &records[i]
+		title := trimISBD(record.Subfield("245", "a"))
+		if subtitle := trimISBD(record.Subfield("245", "b")); subtitle != "" {
+			title += ": " + subtitle
+		}
+		rows = append(rows, BookImportRow{
+			Row:      i + 1,
+			Title:    title,
+			Author:   trimISBD(firstMARCValue(record, "a", "100", "110", "111", "700")),
+			Isbn:     marcISBN(record),
+			Category: trimISBD(firstMARCValue(record, "a", "650", "655")),
+		})
+	}
+	return rows
+}
+
+// firstMARCValue mengembalikan subfield code pertama yang terisi dari daftar tag sesuai prioritas
+func firstMARCValue(record *helpers.MARCRecord, code string, tags ...string) string {
+	for _, tag := range tags {
+		if value := strings.TrimSpace(record.Subfield(tag, code)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// marcISBN mengambil ISBN dari 020$a. Subfield ini sering berisi keterangan seperti
+// "9786020331232 (pbk.)", jadi hanya kata pertama yang dipakai, dan ISBN valid pertama
+// yang dipilih jika ada beberapa 020.
+func marcISBN(record *helpers.MARCRecord) string {
+	var first string
+	for _, value := range record.SubfieldValues("020", "a") {
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := helpers.NormalizeISBN(fields[0]); err == nil {
+			return fields[0]
+		}
+		if first == "" {
+			first = fields[0]
+		}
+	}
+	return first
+}
+
+// trimISBD membuang tanda baca ISBD di akhir nilai MARC, misalnya "Laskar pelangi /"
+// atau "Hirata, Andrea,". Titik setelah inisial ("Tolkien, J. R. R.") dipertahankan.
+func trimISBD(value string) string {
+	value = strings.TrimRight(strings.TrimSpace(value), " /:;,=")
+	if strings.HasSuffix(value, ".") {
+		words := strings.Fields(value)
+		if last := words[len(words)-1]; len([]rune(last)) > 2 {
+			value = strings.TrimSuffix(value, ".")
+		}
+	}
+	return strings.TrimSpace(value)
+}
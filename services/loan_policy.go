@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"library/config"  // Sesuaikan dengan nama proyekmu
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loanPolicy adalah aturan peminjaman yang berlaku untuk seorang anggota, diambil dari tier-nya.
+// Lama peminjaman dan denda khusus kategori (CATEGORY_LOAN_PERIODS, CATEGORY_FINE_RATES)
+// tetap diutamakan, misalnya untuk buku referensi yang hanya boleh dipinjam sebentar.
+type loanPolicy struct {
+	TierCode       string
+	MaxLoans       int
+	LoanPeriodDays int
+	MaxRenewals    int
+	FineDailyRate  int64
+	cfg            *config.Config
+}
+
+// loanPolicyFor mengambil aturan peminjaman anggota. Anggota tanpa tier memakai tier default,
+// dan jika tier default pun tidak ada, dipakai nilai dari konfigurasi tanpa batas jumlah pinjaman.
+func loanPolicyFor(db *gorm.DB, userID uuid.UUID, cfg *config.Config) (*loanPolicy, error) {
+	user := new(models.User)
+	if err := db.Preload("Tier").First(user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	tier := user.Tier
+	if tier == nil {
+		tier = new(models.MembershipTier)
+		err := db.Where("code = ?", cfg.DefaultTierCode).First(tier).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &loanPolicy{
+				LoanPeriodDays: cfg.LoanPeriodDays,
+				MaxRenewals:    cfg.MaxRenewals,
+				FineDailyRate:  cfg.FineDailyRate,
+				cfg:            cfg,
+			}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &loanPolicy{
+		TierCode:       tier.Code,
+		MaxLoans:       tier.MaxLoans,
+		LoanPeriodDays: tier.LoanPeriodDays,
+		MaxRenewals:    tier.MaxRenewals,
+		FineDailyRate:  tier.FineDailyRate,
+		cfg:            cfg,
+	}, nil
+}
+
+// loanPeriodFor mengembalikan lama peminjaman (hari) untuk buku dengan kategori tertentu
+func (p *loanPolicy) loanPeriodFor(category string) int {
+	if days, ok := p.cfg.CategoryLoanPeriod(category); ok {
+		return days
+	}
+	return p.LoanPeriodDays
+}
+
+// fineRateFor mengembalikan denda keterlambatan per hari untuk buku dengan kategori tertentu
+func (p *loanPolicy) fineRateFor(category string) int64 {
+	if rate, ok := p.cfg.CategoryFineRate(category); ok {
+		return rate
+	}
+	return p.FineDailyRate
+}
+
+// ensureLoanLimit menolak peminjaman jika anggota sudah mencapai batas pinjaman tier-nya.
+// Pemanggil harus sudah mengunci baris anggota (lockUser) supaya dua peminjaman bersamaan
+// tidak sama-sama lolos dari hitungan yang sama.
+func (p *loanPolicy) ensureLoanLimit(db *gorm.DB, userID uuid.UUID) error {
+	if p.MaxLoans <= 0 {
+		return nil
+	}
+
+	var openLoans int64
+	if err := db.Model(&models.Lending_records{}).
+		Where("user_id = ? AND return_date IS NULL AND status <> ?", userID, models.RecordStatusLost).
+		Count(&openLoans).Error; err != nil {
+		return err
+	}
+	if openLoans >= int64(p.MaxLoans) {
+		return newError(KindConflict, helpers.ErrCodeLoanLimitReached,
+			fmt.Sprintf("Your %s membership allows at most %d books on loan at a time", p.TierCode, p.MaxLoans))
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"library/helpers" // Sesuaikan dengan nama proyekmu
+	"library/models"  // Sesuaikan dengan nama proyekmu
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MembershipService memegang aturan tier keanggotaan yang menentukan batas peminjaman anggota
+type MembershipService struct {
+	db *gorm.DB
+}
+
+// NewMembershipService membuat MembershipService
+func NewMembershipService(db *gorm.DB) *MembershipService {
+	return &MembershipService{db: db}
+}
+
+// TierInput berisi data tier keanggotaan. Field numerik berupa pointer supaya nilai 0
+// bisa dibedakan dari field yang tidak dikirim.
+type TierInput struct {
+	Code           string
+	Name           string
+	MaxLoans       *int
+	LoanPeriodDays *int
+	MaxRenewals    *int
+	FineDailyRate  *int64
+}
+
+// Tiers mengambil semua tier keanggotaan, diurutkan berdasarkan kode
+func (s *MembershipService) Tiers(ctx context.Context) ([]models.MembershipTier, error) {
+	var tiers []models.MembershipTier
+	err := s.db.WithContext(ctx).Order("code ASC").Find(&tiers).Error
+	return tiers, err
+}
+
+// CreateTier membuat tier keanggotaan baru dengan kode yang belum dipakai
+func (s *MembershipService) CreateTier(ctx context.Context, input TierInput) (*models.MembershipTier, error) {
+	code := tierCode(input.Code)
+	if code == "" || input.LoanPeriodDays == nil {
+		return nil, newError(KindInvalid, "", "Code and loan_period_days are required")
+	}
+
+	tier := &models.MembershipTier{Code: code, Name: input.Name}
+	if err := input.apply(tier); err != nil {
+		return nil, err
+	}
+
+	db := s.db.WithContext(ctx)
+	var duplicates int64
+	if err := db.Model(&models.MembershipTier{}).Where("code = ?", tier.Code).Count(&duplicates).Error; err != nil {
+		return nil, err
+	}
+	if duplicates > 0 {
+		return nil, newError(KindConflict, "", "A membership tier with this code already exists")
+	}
+
+	if err := db.Create(tier).Error; err != nil {
+		return nil, err
+	}
+	return tier, nil
+}
+
+// UpdateTier memperbarui aturan peminjaman sebuah tier. Perubahan berlaku untuk peminjaman
+// dan perpanjangan berikutnya, bukan yang sudah berjalan.
+func (s *MembershipService) UpdateTier(ctx context.Context, id uuid.UUID, input TierInput) (*models.MembershipTier, error) {
+	db := s.db.WithContext(ctx)
+	tier := new(models.MembershipTier)
+	if err := db.First(tier, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newError(KindNotFound, helpers.ErrCodeTierNotFound, "Membership tier not found")
+		}
+		return nil, err
+	}
+
+	// Kode tier dipakai sebagai DEFAULT_MEMBERSHIP_TIER sehingga tidak boleh diubah
+	if input.Code != "" && tierCode(input.Code) != tier.Code {
+		return nil, newError(KindInvalid, "", "Tier code cannot be changed")
+	}
+	if input.Name != "" {
+		tier.Name = input.Name
+	}
+	if err := input.apply(tier); err != nil {
+		return nil, err
+	}
+
+	if err := db.Save(tier).Error; err != nil {
+		return nil, err
+	}
+	return tier, nil
+}
+
+// AssignTier mengubah tier keanggotaan seorang pengguna dan mengembalikan pengguna beserta tier barunya
+func (s *MembershipService) AssignTier(ctx context.Context, userID uuid.UUID, code string) (*models.User, error) {
+	code = tierCode(code)
+	if code == "" {
+		return nil, newError(KindInvalid, "", "tier_code is required")
+	}
+
+	db := s.db.WithContext(ctx)
+	user := new(models.User)
+	if err := db.First(user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newError(KindNotFound, helpers.ErrCodeUserNotFound, "User not found")
+		}
+		return nil, err
+	}
+
+	tier := new(models.MembershipTier)
+	if err := db.Where("code = ?", code).First(tier).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newError(KindNotFound, helpers.ErrCodeTierNotFound, "Membership tier not found")
+		}
+		return nil, err
+	}
+
+	if err := db.Model(user).Update("tier_id", tier.ID).Error; err != nil {
+		return nil, err
+	}
+	user.TierID = &tier.ID
+	user.Tier = tier
+	user.Password = ""
+	return user, nil
+}
+
+// tierCode merapikan kode tier menjadi huruf kecil tanpa spasi di awal dan akhir
+func tierCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// apply menyalin aturan peminjaman yang dikirim ke tier setelah memvalidasinya
+func (input TierInput) apply(tier *models.MembershipTier) error {
+	if input.MaxLoans != nil {
+		if *input.MaxLoans < 0 {
+			return newError(KindInvalid, "", "max_loans must not be negative")
+		}
+		tier.MaxLoans = *input.MaxLoans
+	}
+	if input.LoanPeriodDays != nil {
+		if *input.LoanPeriodDays < 1 {
+			return newError(KindInvalid, "", "loan_period_days must be at least 1")
+		}
+		tier.LoanPeriodDays = *input.LoanPeriodDays
+	}
+	if input.MaxRenewals != nil {
+		if *input.MaxRenewals < 0 {
+			return newError(KindInvalid, "", "max_renewals must not be negative")
+		}
+		tier.MaxRenewals = *input.MaxRenewals
+	}
+	if input.FineDailyRate != nil {
+		if *input.FineDailyRate < 0 {
+			return newError(KindInvalid, "", "fine_daily_rate must not be negative")
+		}
+		tier.FineDailyRate = *input.FineDailyRate
+	}
+	return nil
+}
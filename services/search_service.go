@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"library/helpers"      // Sesuaikan dengan nama proyekmu
+	"library/models"       // Sesuaikan dengan nama proyekmu
+	"library/repositories" // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// searchSimilarityThreshold adalah skor kemiripan trigram minimal supaya judul atau penulis
+// yang salah ketik tetap cocok (0 sampai 1, makin besar makin ketat)
+const searchSimilarityThreshold = 0.3
+
+// searchFacetLimit adalah jumlah maksimal nilai yang ditampilkan per facet
+const searchFacetLimit = 20
+
+// BookSearchResult adalah buku hasil pencarian beserta skor relevansinya
+type BookSearchResult struct {
+	models.Book
+	Rank float64 `json:"rank"`
+}
+
+// FacetCount adalah jumlah buku hasil pencarian untuk satu nilai facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchService membaca katalog untuk pencarian dan feed OPDS. Pencarian memakai full-text
+// dan trigram PostgreSQL yang tidak punya padanan di BookRepository.
+type SearchService struct {
+	db *gorm.DB
+}
+
+// NewSearchService membuat SearchService
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// BookSearch adalah parameter pencarian katalog. Q kosong tidak membatasi hasil, Category dan
+// Author (tanpa membedakan huruf besar/kecil) kosong berarti tidak difilter. Category adalah nama
+// kategori dan ikut mencakup buku di subkategorinya.
+type BookSearch struct {
+	Q        string
+	Category string
+	Author   string
+	Limit    int
+	Offset   int
+}
+
+// SearchResults adalah satu halaman hasil pencarian beserta jumlah seluruh hasil dan facet-nya
+type SearchResults struct {
+	Books          []BookSearchResult
+	Total          int64
+	CategoryFacets []FacetCount
+	AuthorFacets   []FacetCount
+}
+
+// Search mencari buku berdasarkan judul, penulis, kategori atau ISBN. Hasil diurutkan
+// berdasarkan relevansi full-text ditambah kemiripan trigram, dan dikembalikan bersama jumlah
+// hasil per kategori dan per penulis. Facet sebuah field tidak ikut difilter oleh field itu
+// sendiri supaya pilihan lain tetap terlihat.
+func (s *SearchService) Search(ctx context.Context, search BookSearch) (*SearchResults, error) {
+	matched := matchBooks(s.db.WithContext(ctx).Model(&models.Book{}), search.Q)
+	withCategory := func(db *gorm.DB) *gorm.DB {
+		if search.Category == "" {
+			return db
+		}
+		return inCategory(db, search.Category)
+	}
+	withAuthor := func(db *gorm.DB) *gorm.DB {
+		if search.Author == "" {
+			return db
+		}
+		return db.Where("LOWER(author) = LOWER(?)", search.Author)
+	}
+	filtered := matched.Session(&gorm.Session{}).Scopes(withCategory, withAuthor)
+
+	results := &SearchResults{Books: []BookSearchResult{}}
+	if err := filtered.Session(&gorm.Session{}).Count(&results.Total).Error; err != nil {
+		return nil, err
+	}
+	query := rankBooks(filtered.Session(&gorm.Session{}), search.Q)
+	if err := query.Limit(search.Limit).Offset(search.Offset).Find(&results.Books).Error; err != nil {
+		return nil, err
+	}
+
+	var err error
+	results.CategoryFacets, err = categoryFacets(s.db.WithContext(ctx), matched.Session(&gorm.Session{}).Scopes(withAuthor))
+	if err != nil {
+		return nil, err
+	}
+	results.AuthorFacets, err = facetCounts(matched.Session(&gorm.Session{}).Scopes(withCategory), "author")
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// BookFeed menentukan buku pada satu halaman feed OPDS. Q diisi untuk hasil pencarian yang
+// diurutkan berdasarkan relevansi; tanpa Q buku diurutkan berdasarkan judul, atau dari yang
+// terbaru jika Newest. Category adalah nama kategori termasuk subkategorinya; kosong berarti
+// semua kategori.
+type BookFeed struct {
+	Q        string
+	Category string
+	Newest   bool
+	Limit    int
+	Offset   int
+}
+
+// FeedPage adalah satu halaman feed beserta jumlah seluruh buku dan eksemplar available per buku
+type FeedPage struct {
+	Books     []models.Book
+	Total     int64
+	Available map[uuid.UUID]int
+}
+
+// Feed mengambil satu halaman buku untuk feed OPDS
+func (s *SearchService) Feed(ctx context.Context, feed BookFeed) (*FeedPage, error) {
+	db := s.db.WithContext(ctx)
+	query := matchBooks(db.Model(&models.Book{}), feed.Q)
+	if feed.Category != "" {
+		query = inCategory(query, feed.Category)
+	}
+
+	page := &FeedPage{}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	ordered := query.Session(&gorm.Session{})
+	switch {
+	case feed.Q != "":
+		ordered = rankBooks(ordered, feed.Q)
+	case feed.Newest:
+		ordered = ordered.Order("created_at DESC, id ASC")
+	default:
+		ordered = ordered.Order("title ASC, id ASC")
+	}
+	if err := ordered.Limit(feed.Limit).Offset(feed.Offset).Find(&page.Books).Error; err != nil {
+		return nil, err
+	}
+
+	if len(page.Books) == 0 {
+		page.Available = map[uuid.UUID]int{}
+		return page, nil
+	}
+	ids := make([]uuid.UUID, 0, len(page.Books))
+	for _, book := range page.Books {
+		ids = append(ids, book.ID)
+	}
+	available, err := repositories.AvailableCopiesByBook(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	page.Available = available
+	return page, nil
+}
+
+// CategoryCounts mengambil jumlah buku per kategori, urut nama kategori. Buku subkategori ikut
+// dihitung pada induknya, dan kategori tanpa buku tidak dikembalikan.
+func (s *SearchService) CategoryCounts(ctx context.Context) ([]FacetCount, error) {
+	var categories []FacetCount
+	err := s.db.WithContext(ctx).Raw(categoryAncestryCTE + `
+		SELECT categories.name AS value, COUNT(DISTINCT book_categories.book_id) AS count
+		FROM ancestry
+		JOIN book_categories ON book_categories.category_id = ancestry.category_id
+		JOIN books ON books.id = book_categories.book_id AND books.deleted_at IS NULL
+		JOIN categories ON categories.id = ancestry.ancestor_id
+		GROUP BY categories.name
+		ORDER BY categories.name ASC`).Scan(&categories).Error
+	return categories, err
+}
+
+// matchBooks membatasi query buku ke yang cocok dengan kata kunci q: full-text pada judul,
+// penulis dan kategori, kemiripan trigram pada judul dan penulis, atau ISBN yang sama.
+// Kata kunci kosong tidak membatasi apa pun.
+func matchBooks(db *gorm.DB, q string) *gorm.DB {
+	if q == "" {
+		return db
+	}
+	conditions := `search_vector @@ websearch_to_tsquery('simple', @q)
+		OR word_similarity(@q, title) >= @threshold
+		OR word_similarity(@q, author) >= @threshold`
+	// ISBN disimpan sebagai ISBN-13, sehingga kata kunci ISBN-10 maupun ISBN-13 tetap cocok
+	isbn, err := helpers.NormalizeISBN(q)
+	if err == nil {
+		conditions += ` OR isbn = @isbn`
+	}
+	return db.Where("("+conditions+")", map[string]interface{}{
+		"q":         q,
+		"threshold": searchSimilarityThreshold,
+		"isbn":      isbn,
+	})
+}
+
+// rankBooks memilih kolom buku beserta skor relevansi (rank) dan mengurutkan hasil dari
+// yang paling relevan. Tanpa kata kunci hasil diurutkan berdasarkan judul.
+func rankBooks(db *gorm.DB, q string) *gorm.DB {
+	if q == "" {
+		return db.Select("books.*, 0 AS rank").Order("title ASC")
+	}
+	rank := clause.Expr{
+		SQL: `ts_rank(search_vector, websearch_to_tsquery('simple', ?)) +
+			GREATEST(word_similarity(?, title), word_similarity(?, author))`,
+		Vars: []interface{}{q, q, q},
+	}
+	return db.Select("books.*, (?) AS rank", rank).Order("rank DESC, title ASC")
+}
+
+// inCategory membatasi query buku ke yang masuk kategori bernama name (tanpa membedakan huruf
+// besar/kecil) atau salah satu subkategorinya
+func inCategory(db *gorm.DB, name string) *gorm.DB {
+	return db.Where(`books.id IN (
+		SELECT book_categories.book_id FROM book_categories
+		WHERE book_categories.category_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE LOWER(name) = LOWER(?)
+				UNION
+				SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id
+			)
+			SELECT id FROM subtree
+		))`, name)
+}
+
+// categoryFacets menghitung jumlah buku hasil query matched per kategori, termasuk buku di
+// subkategorinya, nilai terbanyak lebih dulu
+func categoryFacets(db *gorm.DB, matched *gorm.DB) ([]FacetCount, error) {
+	facets := []FacetCount{}
+	err := db.Raw(categoryAncestryCTE+`
+		SELECT categories.name AS value, COUNT(DISTINCT book_categories.book_id) AS count
+		FROM ancestry
+		JOIN book_categories ON book_categories.category_id = ancestry.category_id
+		JOIN categories ON categories.id = ancestry.ancestor_id
+		WHERE book_categories.book_id IN (?)
+		GROUP BY categories.name
+		ORDER BY count DESC, value ASC
+		LIMIT ?`, matched.Select("books.id"), searchFacetLimit).Scan(&facets).Error
+	return facets, err
+}
+
+// facetCounts menghitung jumlah buku per nilai sebuah kolom, nilai terbanyak lebih dulu
+func facetCounts(query *gorm.DB, column string) ([]FacetCount, error) {
+	facets := []FacetCount{}
+	err := query.
+		Select(column + " AS value, COUNT(*) AS count").
+		Where(column + " <> ''").
+		Group(column).
+		Order("count DESC, value ASC").
+		Limit(searchFacetLimit).
+		Scan(&facets).Error
+	return facets, err
+}
@@ -0,0 +1,74 @@
+// Package services berisi aturan domain perpustakaan (katalog, akun dan sirkulasi) yang
+// dipakai bersama oleh API HTTP, perintah CLI dan background job.
+// Pelanggaran aturan dikembalikan sebagai *Error; error lain adalah kegagalan sistem.
+package services
+
+import (
+	"errors"
+	"library/models" // Sesuaikan dengan nama proyekmu
+
+	"github.com/google/uuid"
+)
+
+// Kind adalah jenis pelanggaran aturan domain
+type Kind int
+
+const (
+	KindInvalid Kind = iota + 1
+	KindNotFound
+	KindConflict
+	KindForbidden
+)
+
+// Error adalah pelanggaran aturan domain yang boleh ditampilkan ke pengguna.
+// Code adalah kode error API (helpers.ErrCode*, boleh kosong) dan Data entitas terkait,
+// misalnya buku lain yang sudah memakai ISBN yang sama.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Data    interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is mencocokkan Error dengan sentinel sejenis, misalnya errors.Is(err, services.ErrNotFound)
+func (e *Error) Is(target error) bool {
+	sentinel, ok := target.(*Error)
+	return ok && sentinel.Code == "" && sentinel.Kind == e.Kind
+}
+
+// Sentinel untuk memeriksa jenis Error dengan errors.Is
+var (
+	ErrInvalid   = &Error{Kind: KindInvalid, Message: "invalid request"}
+	ErrNotFound  = &Error{Kind: KindNotFound, Message: "not found"}
+	ErrConflict  = &Error{Kind: KindConflict, Message: "conflict"}
+	ErrForbidden = &Error{Kind: KindForbidden, Message: "forbidden"}
+)
+
+// newError membuat Error baru
+func newError(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// AsError mengembalikan Error di dalam err, atau nil jika err adalah kegagalan sistem
+func AsError(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+	return nil
+}
+
+// Actor adalah pengguna yang menjalankan operasi, dipakai untuk aturan kepemilikan dan hak akses
+type Actor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+// IsStaff memeriksa apakah actor adalah petugas (admin atau librarian)
+func (a Actor) IsStaff() bool {
+	return models.IsStaffRole(a.Role)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/config"
+	"library/helpers"
+	"library/models"
+	"library/repositories"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestErrorMatchesKindSentinel(t *testing.T) {
+	err := fmt.Errorf("checkout: %w", newError(KindConflict, helpers.ErrCodeBookUnavailable, "No copies"))
+
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("errors.Is(err, ErrConflict) = false, want true")
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is(err, ErrNotFound) = true, want false")
+	}
+	if domainErr := AsError(err); domainErr == nil || domainErr.Code != helpers.ErrCodeBookUnavailable {
+		t.Errorf("AsError(err) = %v, want the wrapped Error", domainErr)
+	}
+	if AsError(errors.New("connection refused")) != nil {
+		t.Errorf("AsError returned an Error for a system failure")
+	}
+}
+
+func TestCatalogServiceCreate(t *testing.T) {
+	existingID := uuid.New()
+	catalog := NewCatalogService(repositories.NewMemoryBookRepository(
+		models.Book{ID: existingID, Title: "The Odyssey", Isbn: "9780140449136"},
+	))
+
+	tests := []struct {
+		name     string
+		book     models.Book
+		links    repositories.BookLinks
+		wantKind Kind
+		wantCode string
+	}{
+		{name: "valid isbn-10", book: models.Book{Title: "Sample", Isbn: "0-306-40615-2"}},
+		{name: "invalid isbn", book: models.Book{Title: "Sample", Isbn: "12345"}, wantKind: KindInvalid, wantCode: helpers.ErrCodeInvalidISBN},
+		{name: "negative quantity", book: models.Book{Title: "Sample", Isbn: "9780306406157", Quantity: -1}, wantKind: KindInvalid},
+		{name: "duplicate isbn", book: models.Book{Title: "Odyssey", Isbn: "0140449132"}, wantKind: KindConflict, wantCode: helpers.ErrCodeISBNExists},
+		{
+			name: "unknown author", book: models.Book{Title: "Sample", Isbn: "9781861972712"},
+			links:    repositories.BookLinks{AuthorIDs: []uuid.UUID{uuid.New()}},
+			wantKind: KindNotFound, wantCode: helpers.ErrCodeAuthorNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			book := tc.book
+			err := catalog.Create(context.Background(), &book, tc.links)
+			if tc.wantKind == 0 {
+				if err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+				return
+			}
+			domainErr := AsError(err)
+			if domainErr == nil || domainErr.Kind != tc.wantKind || domainErr.Code != tc.wantCode {
+				t.Fatalf("Create() error = %#v, want kind %d code %q", err, tc.wantKind, tc.wantCode)
+			}
+			if tc.wantCode == helpers.ErrCodeISBNExists {
+				if existing, ok := domainErr.Data.(*models.Book); !ok || existing.ID != existingID {
+					t.Errorf("Data = %v, want the book holding the isbn", domainErr.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestAccountServiceUpdateRole(t *testing.T) {
+	memberID := uuid.New()
+	accounts := NewAccountService(repositories.NewMemoryUserRepository(
+		models.User{ID: memberID, Name: "Member", Email: "member@example.com", Role: models.RoleMember},
+	), &config.Config{})
+	ctx := context.Background()
+
+	_, err := accounts.Update(ctx, memberID, UserUpdate{Role: models.RoleAdmin}, Actor{Role: models.RoleLibrarian})
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("librarian promoting to admin: error = %v, want ErrForbidden", err)
+	}
+	_, err = accounts.Update(ctx, memberID, UserUpdate{Role: "superuser"}, Actor{Role: models.RoleAdmin})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("unknown role: error = %v, want ErrInvalid", err)
+	}
+	_, err = accounts.Update(ctx, uuid.New(), UserUpdate{Name: "Nobody"}, Actor{Role: models.RoleAdmin})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown user: error = %v, want ErrNotFound", err)
+	}
+
+	user, err := accounts.Update(ctx, memberID, UserUpdate{Role: models.RoleLibrarian, Password: "secret123"}, Actor{Role: models.RoleAdmin})
+	if err != nil {
+		t.Fatalf("admin promoting to librarian: error = %v", err)
+	}
+	if user.Role != models.RoleLibrarian || user.Password == "secret123" {
+		t.Errorf("got role %q, password stored in plain text: %v", user.Role, user.Password == "secret123")
+	}
+}
+
+func TestAccountServiceUpdateCredentials(t *testing.T) {
+	adminID, librarianID, memberID := uuid.New(), uuid.New(), uuid.New()
+	accounts := NewAccountService(repositories.NewMemoryUserRepository(
+		models.User{ID: adminID, Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin},
+		models.User{ID: librarianID, Name: "Librarian", Email: "librarian@example.com", Role: models.RoleLibrarian},
+		models.User{ID: memberID, Name: "Member", Email: "member@example.com", Role: models.RoleMember},
+	), &config.Config{})
+	librarian := Actor{UserID: librarianID, Role: models.RoleLibrarian}
+
+	tests := []struct {
+		name    string
+		target  uuid.UUID
+		update  UserUpdate
+		actor   Actor
+		wantErr error
+	}{
+		{name: "librarian sets admin password", target: adminID, update: UserUpdate{Password: "takeover1"}, actor: librarian, wantErr: ErrForbidden},
+		{name: "librarian sets admin email", target: adminID, update: UserUpdate{Email: "mine@example.com"}, actor: librarian, wantErr: ErrForbidden},
+		{name: "librarian sets member password", target: memberID, update: UserUpdate{Password: "newpass1"}, actor: librarian, wantErr: ErrForbidden},
+		{name: "librarian corrects member email", target: memberID, update: UserUpdate{Email: "member2@example.com"}, actor: librarian},
+		{name: "librarian changes own password", target: librarianID, update: UserUpdate{Password: "newpass1"}, actor: librarian},
+		{name: "admin resets librarian password", target: librarianID, update: UserUpdate{Password: "reset123"}, actor: Actor{UserID: adminID, Role: models.RoleAdmin}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := accounts.Update(context.Background(), tc.target, tc.update, tc.actor)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	admin, err := accounts.Get(context.Background(), adminID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Email != "admin@example.com" || admin.Password != "" {
+		t.Errorf("admin account changed by a rejected update: email %q, password set %v", admin.Email, admin.Password != "")
+	}
+}
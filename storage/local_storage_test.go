@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "uploads")
+	store, err := NewLocalStorage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorageRoundTrip(t, store)
+
+	// Key yang ditolak tidak boleh meninggalkan file di luar root
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape.jpg")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the storage root: %v", err)
+	}
+}
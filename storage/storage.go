@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"library/config" // Sesuaikan dengan nama proyekmu
+	"log"
+	"path"
+	"strings"
+)
+
+// ErrNotFound dikembalikan jika objek dengan key tersebut tidak ada
+var ErrNotFound = errors.New("object not found")
+
+// Object adalah isi file yang dibaca dari storage. Body wajib ditutup pemanggil.
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// Storage adalah penyimpanan file yang bisa diganti backend-nya.
+// Key memakai pemisah "/" seperti path, misalnya "covers/<book id>/<versi>/small.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// InitStorage membuat storage sesuai STORAGE_DRIVER
+func InitStorage(cfg *config.Config) Storage {
+	var store Storage
+	var err error
+	switch cfg.StorageDriver {
+	case "local":
+		store, err = NewLocalStorage(cfg.StorageLocalDir)
+	case "s3":
+		store, err = NewS3Storage(context.Background(), S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		err = fmt.Errorf("unknown storage driver %q, use local or s3", cfg.StorageDriver)
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	log.Printf("Storage initialized (%s)", cfg.StorageDriver)
+	return store
+}
+
+// validKey menolak key kosong, absolut atau yang keluar dari root dengan ".."
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
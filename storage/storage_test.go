@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testStorageRoundTrip menguji Put, Get dan Delete pada sebuah Storage, termasuk penolakan key
+// yang keluar dari root
+func testStorageRoundTrip(t *testing.T, store Storage) {
+	t.Helper()
+	ctx := context.Background()
+	key := "covers/test/0123456789abcdef/small.jpg"
+	content := "not really a jpeg"
+
+	if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	object, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, err := io.ReadAll(object.Body)
+	object.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != content || object.Size != int64(len(content)) || object.ContentType != "image/jpeg" {
+		t.Errorf("Get() = %q (%d bytes, %s), want %q", body, object.Size, object.ContentType, content)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of a missing key error = %v, want nil", err)
+	}
+
+	for _, bad := range []string{"../escape.jpg", "covers/../../escape.jpg"} {
+		if err := store.Put(ctx, bad, strings.NewReader(content), int64(len(content)), "image/jpeg"); err == nil {
+			t.Errorf("Put(%q) succeeded, want an error", bad)
+		}
+		if _, err := store.Get(ctx, bad); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) error = %v, want an invalid key error", bad, err)
+		}
+		if err := store.Delete(ctx, bad); err == nil {
+			t.Errorf("Delete(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "covers/book/v1/small.jpg"},
+		{key: "file.jpg"},
+		{key: "", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "../file.jpg", wantErr: true},
+		{key: "covers/../../file.jpg", wantErr: true},
+		{key: "covers/./small.jpg", wantErr: true},
+		{key: "covers//small.jpg", wantErr: true},
+		{key: "covers/", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		if err := validKey(tc.key); (err != nil) != tc.wantErr {
+			t.Errorf("validKey(%q) error = %v, want error %v", tc.key, err, tc.wantErr)
+		}
+	}
+}